@@ -0,0 +1,120 @@
+// Command fsmgen generates a standalone, switch-based Go implementation of an
+// automaton definition file, suitable for go:generate:
+//
+//	//go:generate go run github.com/dsonic0912/PolicyReporter-FSM/cmd/fsmgen -in parity.yaml -symbols rune -prefix Parity -out parity_fsm.go -test
+//
+// The definition is read as JSON, YAML or SCXML according to its extension
+// (or -format). The package clause defaults to $GOPACKAGE, which go generate
+// sets. With -test, a test comparing the generated code with the definition on
+// random inputs is written next to the output file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dsonic0912/PolicyReporter-FSM/fsm"
+	"github.com/dsonic0912/PolicyReporter-FSM/internal/machinefile"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// options holds the parsed command line.
+type options struct {
+	input, output string
+	format        machinefile.Format
+	config        fsm.GoGeneratorConfig
+	withTest      bool
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fsmgen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	config := fsm.DefaultGoGeneratorConfig()
+	if pkg := os.Getenv("GOPACKAGE"); pkg != "" {
+		config.Package = pkg
+	}
+	input := flags.String("in", "", "definition file to generate from (required)")
+	formatName := flags.String("format", "", "definition format: json, yaml or scxml (default: from the file extension)")
+	symbolKind := flags.String("symbols", "string", "symbol type: string, rune or int")
+	output := flags.String("out", "", "output file (default: standard output)")
+	flags.StringVar(&config.Package, "package", config.Package, "package name of the generated code")
+	flags.StringVar(&config.Prefix, "prefix", "", "prefix of every generated identifier")
+	withTest := flags.Bool("test", false, "also write a _test.go file next to -out")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *input == "" || flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: fsmgen -in FILE [-out FILE.go [-test]] [flags]")
+		flags.PrintDefaults()
+		return 2
+	}
+	if *withTest && *output == "" {
+		fmt.Fprintln(stderr, "fsmgen: -test requires -out")
+		return 2
+	}
+
+	opts := options{input: *input, output: *output, config: config, withTest: *withTest}
+	if *formatName != "" {
+		format, err := machinefile.ParseFormat(*formatName)
+		if err != nil {
+			fmt.Fprintf(stderr, "fsmgen: %v\n", err)
+			return 2
+		}
+		opts.format = format
+	}
+	kind, err := machinefile.ParseSymbolKind(*symbolKind)
+	if err != nil {
+		fmt.Fprintf(stderr, "fsmgen: %v\n", err)
+		return 2
+	}
+
+	switch kind {
+	case machinefile.RuneSymbols:
+		err = generate[rune](opts, stdout)
+	case machinefile.IntSymbols:
+		err = generate[int](opts, stdout)
+	default:
+		err = generate[string](opts, stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "fsmgen: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func generate[S fsm.Symbol](opts options, stdout io.Writer) error {
+	fa, err := machinefile.Load[S](opts.input, opts.format)
+	if err != nil {
+		return fmt.Errorf("%s: %w", opts.input, err)
+	}
+
+	generator := fsm.NewGoGenerator[string, S](opts.config)
+	source, err := generator.Generate(fa)
+	if err != nil {
+		return err
+	}
+	if opts.output == "" {
+		_, err = stdout.Write(source)
+		return err
+	}
+	if err := os.WriteFile(opts.output, source, 0o644); err != nil {
+		return err
+	}
+
+	if opts.withTest {
+		test, err := generator.GenerateTest(fa)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(strings.TrimSuffix(opts.output, ".go")+"_test.go", test, 0o644)
+	}
+	return nil
+}
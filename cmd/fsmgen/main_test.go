@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const parityYAML = `version: 1
+initial: even
+states: [even, odd]
+alphabet: ["0", "1"]
+accepting: [even]
+transitions:
+  even: {"0": even, "1": odd}
+  odd: {"0": odd, "1": even}
+`
+
+// writeDefinition writes a definition file into a temporary directory
+func writeDefinition(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+	return path
+}
+
+// TestRun_Stdout tests generating code to standard output
+func TestRun_Stdout(t *testing.T) {
+	input := writeDefinition(t, "parity.yaml", parityYAML)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-in", input, "-symbols", "rune", "-package", "parity", "-prefix", "Parity"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("run returned %d: %s", code, stderr.String())
+	}
+	for _, fragment := range []string{"package parity\n", "func ParityStep(state ParityState, symbol rune)"} {
+		if !strings.Contains(stdout.String(), fragment) {
+			t.Errorf("Output is missing %q:\n%s", fragment, stdout.String())
+		}
+	}
+}
+
+// TestRun_OutputFiles tests writing the code and its test to files
+func TestRun_OutputFiles(t *testing.T) {
+	input := writeDefinition(t, "parity.yaml", parityYAML)
+	output := filepath.Join(t.TempDir(), "parity_fsm.go")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-in", input, "-symbols", "rune", "-out", output, "-test"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("run returned %d: %s", code, stderr.String())
+	}
+	for _, path := range []string{output, strings.TrimSuffix(output, ".go") + "_test.go"} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be written: %v", path, err)
+		}
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("Expected no output on stdout, got %q", stdout.String())
+	}
+}
+
+// TestRun_Errors tests exit codes for bad usage and bad definitions
+func TestRun_Errors(t *testing.T) {
+	input := writeDefinition(t, "parity.yaml", parityYAML)
+	broken := writeDefinition(t, "broken.json", `{"version": 1}`)
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"missing input", []string{}, 2},
+		{"test without out", []string{"-in", input, "-test"}, 2},
+		{"unknown symbols", []string{"-in", input, "-symbols", "float"}, 2},
+		{"unknown format", []string{"-in", input, "-format", "toml"}, 2},
+		{"scxml with rune symbols", []string{"-in", input, "-format", "scxml", "-symbols", "rune"}, 1},
+		{"invalid definition", []string{"-in", broken}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr); code != tt.code {
+				t.Errorf("run returned %d, want %d (stderr: %s)", code, tt.code, stderr.String())
+			}
+		})
+	}
+}
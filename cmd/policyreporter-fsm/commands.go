@@ -0,0 +1,248 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dsonic0912/PolicyReporter-FSM/fsm"
+	"github.com/dsonic0912/PolicyReporter-FSM/internal/machinefile"
+)
+
+// runCommand feeds symbols to a machine and prints the outcome and trace.
+func runCommand(c *cli, args []string) int {
+	c.newFlags("MACHINE [SYMBOL...]")
+	positional, ok := c.parse(args)
+	if !ok {
+		return exitUsage
+	}
+	m, code := c.load(positional[0])
+	if m == nil {
+		return code
+	}
+
+	result, err := m.run(positional[1:])
+	if err != nil {
+		code := exitCode(err)
+		if c.jsonOutput {
+			c.report(map[string]interface{}{
+				"accepted":   false,
+				"finalState": result.FinalState,
+				"trace":      result.Trace,
+				"error":      errorJSON(err),
+				"exitCode":   code,
+			})
+			return code
+		}
+		if len(result.Trace) > 0 {
+			fmt.Fprintf(c.stdout, "trace: %s\n", strings.Join(result.Trace, " -> "))
+		}
+		fmt.Fprintf(c.stderr, "policyreporter-fsm run: %v\n", err)
+		return code
+	}
+
+	code = exitOK
+	if !result.Accepted {
+		code = exitRejected
+	}
+	if c.jsonOutput {
+		c.report(result)
+		return code
+	}
+	if result.Accepted {
+		fmt.Fprintln(c.stdout, "accepted")
+	} else {
+		fmt.Fprintln(c.stdout, "rejected")
+	}
+	fmt.Fprintf(c.stdout, "trace: %s\n", strings.Join(result.Trace, " -> "))
+	return code
+}
+
+// validateCommand checks a machine and lists every problem found.
+func validateCommand(c *cli, args []string) int {
+	flags := c.newFlags("MACHINE")
+	strict := flags.Bool("strict", false, "use the strict validator configuration")
+	positional, ok := c.parse(args)
+	if !ok {
+		return exitUsage
+	}
+	if len(positional) > 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	config := fsm.DefaultValidatorConfig()
+	if *strict {
+		config = fsm.StrictValidatorConfig()
+	}
+	format, kind, err := c.machineOptions()
+	if err != nil {
+		return c.usageError(err)
+	}
+	m, err := loadMachine(positional[0], format, kind)
+	switch {
+	case err == nil:
+		err = m.validate(config)
+	case !fsm.IsValidationError(err):
+		return c.fail(fmt.Errorf("%s: %w", positional[0], err))
+	}
+
+	problems := validationProblems(err)
+	if c.jsonOutput {
+		encoded := make([]interface{}, len(problems))
+		for i, problem := range problems {
+			encoded[i] = errorJSON(problem)
+		}
+		c.report(map[string]interface{}{"valid": err == nil, "errors": encoded})
+	} else if err == nil {
+		fmt.Fprintln(c.stdout, "valid")
+	} else {
+		fmt.Fprintln(c.stdout, "invalid:")
+		for _, problem := range problems {
+			fmt.Fprintf(c.stdout, "  - %v\n", problem)
+		}
+	}
+	if err != nil {
+		return exitErrorTypeBase + int(fsm.ErrorTypeValidation)
+	}
+	return exitOK
+}
+
+// validationProblems lists the individual errors collected in err, looking
+// through the cause of a wrapping AutomatonError.
+func validationProblems(err error) []error {
+	problems := []error{}
+	for err != nil {
+		var collector *fsm.ErrorCollector
+		if errors.As(err, &collector) {
+			return append(problems, collector.Errors()...)
+		}
+		var automatonErr *fsm.AutomatonError
+		if !errors.As(err, &automatonErr) || automatonErr.Cause == nil {
+			return append(problems, err)
+		}
+		err = automatonErr.Cause
+	}
+	return problems
+}
+
+// minimizeCommand writes the minimal machine equivalent to the input.
+func minimizeCommand(c *cli, args []string) int {
+	flags := c.newFlags("MACHINE")
+	to := flags.String("to", "", "output format (default: the input format)")
+	output := flags.String("o", "", "output file (default: standard output)")
+	positional, ok := c.parse(args)
+	if !ok {
+		return exitUsage
+	}
+	format, err := c.outputFormat(*to, positional[0])
+	if err != nil {
+		return c.usageError(err)
+	}
+	m, code := c.load(positional[0])
+	if m == nil {
+		return code
+	}
+
+	minimized, mapping, err := m.minimize()
+	if err != nil {
+		return c.fail(err)
+	}
+	data, err := minimized.encode(format)
+	if err != nil {
+		return c.fail(err)
+	}
+	if !c.jsonOutput {
+		return c.write(*output, data)
+	}
+	summary := map[string]interface{}{
+		"states":          m.stateCount(),
+		"minimizedStates": minimized.stateCount(),
+		"stateMapping":    mapping,
+	}
+	if *output == "" {
+		summary["definition"] = string(data)
+	} else if code := c.write(*output, data); code != exitOK {
+		return code
+	}
+	return c.report(summary)
+}
+
+// convertCommand writes a machine in another serialization format.
+func convertCommand(c *cli, args []string) int {
+	flags := c.newFlags("MACHINE")
+	to := flags.String("to", "", "output format: json, yaml or scxml (required)")
+	output := flags.String("o", "", "output file (default: standard output)")
+	positional, ok := c.parse(args)
+	if !ok {
+		return exitUsage
+	}
+	if *to == "" {
+		flags.Usage()
+		return exitUsage
+	}
+	format, err := machinefile.ParseFormat(*to)
+	if err != nil {
+		return c.usageError(err)
+	}
+	m, code := c.load(positional[0])
+	if m == nil {
+		return code
+	}
+
+	data, err := m.encode(format)
+	if err != nil {
+		return c.fail(err)
+	}
+	if !c.jsonOutput {
+		return c.write(*output, data)
+	}
+	if *output == "" {
+		return c.report(map[string]string{"format": string(format), "definition": string(data)})
+	}
+	if code := c.write(*output, data); code != exitOK {
+		return code
+	}
+	return c.report(map[string]string{"format": string(format), "output": *output})
+}
+
+// renderCommand draws a machine, optionally highlighting the path of an input.
+func renderCommand(c *cli, args []string) int {
+	flags := c.newFlags("MACHINE [SYMBOL...]")
+	as := flags.String("as", "dot", "diagram kind: dot, mermaid or plantuml")
+	output := flags.String("o", "", "output file (default: standard output)")
+	positional, ok := c.parse(args)
+	if !ok {
+		return exitUsage
+	}
+	if len(positional) > 1 && *as != "dot" {
+		return c.usageError(fmt.Errorf("highlighting an input is only supported for dot diagrams"))
+	}
+	m, code := c.load(positional[0])
+	if m == nil {
+		return code
+	}
+
+	diagram, err := m.render(*as, positional[1:])
+	if err != nil {
+		return c.fail(err)
+	}
+	if !c.jsonOutput {
+		return c.write(*output, []byte(diagram))
+	}
+	if *output == "" {
+		return c.report(map[string]string{"kind": *as, "diagram": diagram})
+	}
+	if code := c.write(*output, []byte(diagram)); code != exitOK {
+		return code
+	}
+	return c.report(map[string]string{"kind": *as, "output": *output})
+}
+
+// outputFormat returns the -to format, defaulting to the format of the input.
+func (c *cli) outputFormat(to, input string) (machinefile.Format, error) {
+	if to != "" {
+		return machinefile.ParseFormat(to)
+	}
+	return c.inputFormat(input)
+}
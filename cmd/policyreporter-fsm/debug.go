@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dsonic0912/PolicyReporter-FSM/fsm"
+)
+
+const debugHelp = `commands:
+  feed SYMBOL...         queue input after the pending symbols
+  step [N]         (s)   consume the next N pending symbols, default 1
+  continue         (c)   step until a breakpoint or the end of the input
+  back [N]               undo the last N steps, default 1
+  restart                undo every step, keeping the breakpoints
+  break STATE      (b)   stop after entering STATE
+  break STATE SYMBOL     stop after the transition from STATE on SYMBOL
+  delete ID              remove a breakpoint
+  breakpoints            list the breakpoints
+  inspect          (i)   show the current state and the input
+  trace            (t)   print the states visited so far
+  help                   show this help
+  quit             (q)   leave the debugger
+`
+
+// debugCommand starts an interactive stepping session on standard input.
+func debugCommand(c *cli, args []string) int {
+	c.newFlags("MACHINE [SYMBOL...]")
+	positional, ok := c.parse(args)
+	if !ok {
+		return exitUsage
+	}
+	m, code := c.load(positional[0])
+	if m == nil {
+		return code
+	}
+	if err := m.debug(positional[1:], c.stdin, c.stdout); err != nil {
+		return c.fail(err)
+	}
+	return exitOK
+}
+
+func (m *typedMachine[S]) debug(args []string, in io.Reader, out io.Writer) error {
+	input, err := m.parse(args)
+	if err != nil {
+		return err
+	}
+	session := &debugSession[S]{
+		debugger: fsm.NewDebugger[string, S](m.fa, input...),
+		parse:    m.parse,
+		out:      out,
+	}
+	return session.repl(in)
+}
+
+// debugSession reads debugger commands and prints their outcome.
+type debugSession[S fsm.Symbol] struct {
+	debugger *fsm.Debugger[string, S]
+	parse    func([]string) ([]S, error)
+	out      io.Writer
+}
+
+// repl runs commands until quit or the end of the input. Errors of single
+// commands are printed and do not end the session.
+func (s *debugSession[S]) repl(in io.Reader) error {
+	fmt.Fprintf(s.out, "%s\n", s.position())
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(s.out, "(fsm) ")
+		if !scanner.Scan() {
+			fmt.Fprintln(s.out)
+			return scanner.Err()
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" || fields[0] == "q" {
+			return nil
+		}
+		if err := s.execute(fields[0], fields[1:]); err != nil {
+			fmt.Fprintf(s.out, "error: %v\n", err)
+		}
+	}
+}
+
+func (s *debugSession[S]) execute(name string, args []string) error {
+	switch name {
+	case "feed":
+		input, err := s.parse(args)
+		if err != nil {
+			return err
+		}
+		s.debugger.Feed(input...)
+		fmt.Fprintf(s.out, "pending: %s\n", symbolsText(s.debugger.Pending()))
+	case "step", "s":
+		n, err := countArgument(args)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			step, err := s.debugger.Step()
+			if err != nil {
+				return err
+			}
+			s.printStep(step)
+			if len(step.Hit) > 0 {
+				break
+			}
+		}
+	case "continue", "c":
+		steps, err := s.debugger.Continue()
+		for _, step := range steps {
+			s.printStep(step)
+		}
+		if err != nil {
+			return err
+		}
+		if len(s.debugger.Pending()) == 0 {
+			fmt.Fprintf(s.out, "end of input: %s\n", s.position())
+		}
+	case "back":
+		n, err := countArgument(args)
+		if err != nil {
+			return err
+		}
+		if err := s.debugger.Back(n); err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "%s\n", s.position())
+	case "restart":
+		if err := s.debugger.Restart(); err != nil {
+			return err
+		}
+		fmt.Fprintf(s.out, "%s\n", s.position())
+	case "break", "b":
+		return s.addBreakpoint(args)
+	case "delete":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: delete ID")
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+		if err != nil || !s.debugger.RemoveBreakpoint(id) {
+			return fmt.Errorf("no breakpoint %s", args[0])
+		}
+	case "breakpoints":
+		for _, breakpoint := range s.debugger.Breakpoints() {
+			fmt.Fprintf(s.out, "breakpoint %s\n", breakpoint)
+		}
+	case "inspect", "i":
+		fmt.Fprintf(s.out, "%s\n", s.position())
+		fmt.Fprintf(s.out, "consumed: %s\n", symbolsText(s.debugger.Consumed()))
+		fmt.Fprintf(s.out, "pending: %s\n", symbolsText(s.debugger.Pending()))
+	case "trace", "t":
+		return s.debugger.DumpTrace(s.out)
+	case "help", "h", "?":
+		fmt.Fprint(s.out, debugHelp)
+	default:
+		return fmt.Errorf("unknown command %q, try help", name)
+	}
+	return nil
+}
+
+// addBreakpoint handles "break STATE" and "break STATE SYMBOL".
+func (s *debugSession[S]) addBreakpoint(args []string) error {
+	var id int
+	switch len(args) {
+	case 1:
+		id = s.debugger.AddStateBreakpoint(args[0])
+	case 2:
+		symbols, err := s.parse(args[1:])
+		if err != nil {
+			return err
+		}
+		if len(symbols) != 1 {
+			return fmt.Errorf("%q is not a single symbol", args[1])
+		}
+		id = s.debugger.AddTransitionBreakpoint(args[0], symbols[0])
+	default:
+		return fmt.Errorf("usage: break STATE [SYMBOL]")
+	}
+	for _, breakpoint := range s.debugger.Breakpoints() {
+		if breakpoint.ID == id {
+			fmt.Fprintf(s.out, "breakpoint %s\n", breakpoint)
+		}
+	}
+	return nil
+}
+
+// printStep prints a transition and the breakpoints it hit.
+func (s *debugSession[S]) printStep(step fsm.DebugStep[string, S]) {
+	fmt.Fprintf(s.out, "%d: %s --%s--> %s\n", step.Position+1, step.From, symbolText(step.Symbol), step.To)
+	for _, breakpoint := range step.Hit {
+		fmt.Fprintf(s.out, "hit breakpoint %s\n", breakpoint)
+	}
+}
+
+// position describes the current state, whether it accepts and how many
+// symbols were consumed.
+func (s *debugSession[S]) position() string {
+	status := "rejecting"
+	if s.debugger.Accepting() {
+		status = "accepting"
+	}
+	return fmt.Sprintf("state %s (%s) at position %d", s.debugger.State(), status, s.debugger.Position())
+}
+
+// countArgument reads the optional repeat count of step and back.
+func countArgument(args []string) (int, error) {
+	switch len(args) {
+	case 0:
+		return 1, nil
+	case 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid count %q", args[0])
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("expected at most one count")
+	}
+}
+
+// symbolText writes a rune symbol as its character and others with %v.
+func symbolText[S fsm.Symbol](symbol S) string {
+	if r, ok := any(symbol).(rune); ok {
+		return string(r)
+	}
+	return fmt.Sprintf("%v", symbol)
+}
+
+// symbolsText joins symbols with spaces, writing "(none)" for no symbols.
+func symbolsText[S fsm.Symbol](symbols []S) string {
+	if len(symbols) == 0 {
+		return "(none)"
+	}
+	texts := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		texts[i] = symbolText(symbol)
+	}
+	return strings.Join(texts, " ")
+}
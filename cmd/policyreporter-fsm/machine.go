@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/dsonic0912/PolicyReporter-FSM/fsm"
+	"github.com/dsonic0912/PolicyReporter-FSM/internal/machinefile"
+)
+
+// machine is a loaded automaton whose symbol type was chosen on the command
+// line. It hides the type parameter from the subcommands.
+type machine interface {
+	// stateCount returns the number of states
+	stateCount() int
+	// run processes the symbols given as arguments
+	run(args []string) (runResult, error)
+	// validate checks the automaton with the given configuration
+	validate(config fsm.ValidatorConfig) error
+	// minimize returns the minimal automaton and the old-to-new state mapping
+	minimize() (machine, map[string]string, error)
+	// encode serializes the automaton
+	encode(format machinefile.Format) ([]byte, error)
+	// render draws the automaton, highlighting the path taken by the arguments
+	render(kind string, highlight []string) (string, error)
+	// debug runs an interactive stepping session with the arguments queued
+	debug(args []string, in io.Reader, out io.Writer) error
+}
+
+// runResult is the outcome of the run subcommand.
+type runResult struct {
+	Accepted   bool     `json:"accepted"`
+	FinalState string   `json:"finalState"`
+	Trace      []string `json:"trace"`
+}
+
+// loadMachine reads a definition file with the given symbol kind.
+func loadMachine(path string, format machinefile.Format, kind machinefile.SymbolKind) (machine, error) {
+	switch kind {
+	case machinefile.RuneSymbols:
+		return load(path, format, parseRunes)
+	case machinefile.IntSymbols:
+		return load(path, format, parseInts)
+	default:
+		return load(path, format, func(args []string) ([]string, error) { return args, nil })
+	}
+}
+
+func load[S fsm.Symbol](path string, format machinefile.Format, parse func([]string) ([]S, error)) (machine, error) {
+	fa, err := machinefile.Load[S](path, format)
+	if err != nil {
+		return nil, err
+	}
+	return &typedMachine[S]{fa: fa, parse: parse}, nil
+}
+
+// parseRunes treats every argument as a sequence of rune symbols.
+func parseRunes(args []string) ([]rune, error) {
+	input := []rune{}
+	for _, arg := range args {
+		input = append(input, []rune(arg)...)
+	}
+	return input, nil
+}
+
+// parseInts reads every argument as a decimal integer symbol.
+func parseInts(args []string) ([]int, error) {
+	input := make([]int, len(args))
+	for i, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fsm.NewInvalidInputError(arg, i, fmt.Sprintf("symbol %q is not an integer", arg))
+		}
+		input[i] = n
+	}
+	return input, nil
+}
+
+// typedMachine implements machine for one symbol type.
+type typedMachine[S fsm.Symbol] struct {
+	fa    *fsm.FiniteAutomaton[string, S]
+	parse func([]string) ([]S, error)
+}
+
+func (m *typedMachine[S]) stateCount() int {
+	return len(m.fa.GetStates())
+}
+
+func (m *typedMachine[S]) run(args []string) (runResult, error) {
+	input, err := m.parse(args)
+	if err != nil {
+		return runResult{Trace: []string{}}, err
+	}
+	trace, accepted, err := m.fa.ProcessInputWithTrace(input)
+	result := runResult{Accepted: accepted, Trace: append([]string{}, trace...)}
+	if len(trace) > 0 {
+		result.FinalState = trace[len(trace)-1]
+	}
+	return result, err
+}
+
+func (m *typedMachine[S]) validate(config fsm.ValidatorConfig) error {
+	return fsm.NewInputValidator[string, S](config).Validate(m.fa)
+}
+
+func (m *typedMachine[S]) minimize() (machine, map[string]string, error) {
+	minimized, mapping, err := fsm.Minimize(m.fa)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &typedMachine[S]{fa: minimized, parse: m.parse}, mapping, nil
+}
+
+func (m *typedMachine[S]) encode(format machinefile.Format) ([]byte, error) {
+	serializer, err := machinefile.Serializer[S](format)
+	if err != nil {
+		return nil, err
+	}
+	return serializer.Serialize(m.fa)
+}
+
+func (m *typedMachine[S]) render(kind string, highlight []string) (string, error) {
+	switch kind {
+	case "dot":
+		options := fsm.DefaultDOTOptions[string, S]()
+		if len(highlight) > 0 {
+			input, err := m.parse(highlight)
+			if err != nil {
+				return "", err
+			}
+			// A rejected or failing input still highlights the path up to where it stopped.
+			trace, _, _ := m.fa.ProcessInputWithTrace(input)
+			options = options.HighlightTrace(trace, "red")
+		}
+		return fsm.ToDOTWithOptions[string, S](m.fa, options)
+	case "mermaid":
+		return fsm.ToMermaid[string, S](m.fa)
+	case "plantuml":
+		return fsm.ToPlantUML[string, S](m.fa)
+	default:
+		return "", fmt.Errorf("unknown diagram kind %q (expected dot, mermaid or plantuml)", kind)
+	}
+}
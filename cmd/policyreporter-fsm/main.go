@@ -0,0 +1,231 @@
+// Command policyreporter-fsm runs, checks, transforms and draws automaton
+// definition files written in JSON, YAML or SCXML.
+//
+// Usage:
+//
+//	policyreporter-fsm <command> [flags] MACHINE [SYMBOL...]
+//
+// Commands:
+//
+//	run       feed the symbols to the machine and print whether they are accepted
+//	validate  check the machine with the default or -strict validator
+//	minimize  write the minimal equivalent machine
+//	convert   write the machine in another format
+//	render    draw the machine as a dot, mermaid or plantuml diagram
+//	debug     step through an input interactively, with breakpoints
+//
+// Every command but debug accepts -json for machine-readable output; when the
+// output is written to a file with -o, the JSON summary names the file. The exit
+// status is 0 on success, 1 when run rejects its input, 2 for usage errors, 3
+// for other failures such as unreadable files, and 10 plus the fsm.ErrorType
+// of the error otherwise (10 validation, 11 transition, 12 invalid input, 13
+// invalid configuration, 14 internal).
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dsonic0912/PolicyReporter-FSM/fsm"
+	"github.com/dsonic0912/PolicyReporter-FSM/internal/machinefile"
+)
+
+// Exit statuses; see the package documentation.
+const (
+	exitOK       = 0
+	exitRejected = 1
+	exitUsage    = 2
+	exitFailure  = 3
+	// exitErrorTypeBase is added to the fsm.ErrorType of an AutomatonError
+	exitErrorTypeBase = 10
+)
+
+const usage = `usage: policyreporter-fsm <command> [flags] MACHINE [SYMBOL...]
+
+commands:
+  run       feed the symbols to the machine and print whether they are accepted
+  validate  check the machine with the default or -strict validator
+  minimize  write the minimal equivalent machine
+  convert   write the machine in another format
+  render    draw the machine as a dot, mermaid or plantuml diagram
+  debug     step through an input interactively, with breakpoints
+
+Run "policyreporter-fsm <command> -h" for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// command is a subcommand: it parses its own flags and returns an exit status.
+type command func(c *cli, args []string) int
+
+var commands = map[string]command{
+	"run":      runCommand,
+	"validate": validateCommand,
+	"minimize": minimizeCommand,
+	"convert":  convertCommand,
+	"render":   renderCommand,
+	"debug":    debugCommand,
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+	cmd, exists := commands[name]
+	if !exists {
+		fmt.Fprintf(stderr, "policyreporter-fsm: unknown command %q\n\n%s", name, usage)
+		return exitUsage
+	}
+	return cmd(&cli{name: name, stdin: stdin, stdout: stdout, stderr: stderr}, args[1:])
+}
+
+// cli holds the state shared by a subcommand invocation: its streams and the
+// flags every subcommand accepts.
+type cli struct {
+	name           string
+	stdin          io.Reader
+	stdout, stderr io.Writer
+
+	flags       *flag.FlagSet
+	formatName  string
+	symbolsName string
+	jsonOutput  bool
+}
+
+// newFlags creates the flag set of the subcommand with the common flags.
+func (c *cli) newFlags(arguments string) *flag.FlagSet {
+	c.flags = flag.NewFlagSet(c.name, flag.ContinueOnError)
+	c.flags.SetOutput(c.stderr)
+	c.flags.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: policyreporter-fsm %s [flags] %s\n", c.name, arguments)
+		c.flags.PrintDefaults()
+	}
+	c.flags.StringVar(&c.formatName, "format", "", "format of MACHINE: json, yaml or scxml (default: from the file extension)")
+	c.flags.StringVar(&c.symbolsName, "symbols", "string", "symbol type: string, rune (each argument is split into characters) or int")
+	c.flags.BoolVar(&c.jsonOutput, "json", false, "write machine-readable JSON output")
+	return c.flags
+}
+
+// parse parses the arguments and returns the positional ones, which must
+// start with the machine file.
+func (c *cli) parse(args []string) ([]string, bool) {
+	if err := c.flags.Parse(args); err != nil {
+		return nil, false
+	}
+	if c.flags.NArg() == 0 {
+		c.flags.Usage()
+		return nil, false
+	}
+	return c.flags.Args(), true
+}
+
+// machineOptions returns the format and symbol kind selected by the flags;
+// an empty format is inferred from the file extension.
+func (c *cli) machineOptions() (machinefile.Format, machinefile.SymbolKind, error) {
+	var format machinefile.Format
+	if c.formatName != "" {
+		parsed, err := machinefile.ParseFormat(c.formatName)
+		if err != nil {
+			return "", "", err
+		}
+		format = parsed
+	}
+	kind, err := machinefile.ParseSymbolKind(c.symbolsName)
+	if err != nil {
+		return "", "", err
+	}
+	return format, kind, nil
+}
+
+// load reads the machine file, reporting any error. It returns nil and the
+// exit status on failure.
+func (c *cli) load(path string) (machine, int) {
+	format, kind, err := c.machineOptions()
+	if err != nil {
+		return nil, c.usageError(err)
+	}
+	m, err := loadMachine(path, format, kind)
+	if err != nil {
+		return nil, c.fail(fmt.Errorf("%s: %w", path, err))
+	}
+	return m, exitOK
+}
+
+// inputFormat returns the format of the machine file.
+func (c *cli) inputFormat(path string) (machinefile.Format, error) {
+	if c.formatName != "" {
+		return machinefile.ParseFormat(c.formatName)
+	}
+	return machinefile.FormatOf(path)
+}
+
+// write writes a command's output to the -o file, or to stdout when empty.
+func (c *cli) write(output string, data []byte) int {
+	if output == "" {
+		if _, err := c.stdout.Write(data); err != nil {
+			return c.fail(err)
+		}
+		return exitOK
+	}
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return c.fail(err)
+	}
+	return exitOK
+}
+
+// report writes a successful result as JSON.
+func (c *cli) report(result interface{}) int {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return c.fail(err)
+	}
+	return exitOK
+}
+
+// usageError reports an invalid flag value.
+func (c *cli) usageError(err error) int {
+	fmt.Fprintf(c.stderr, "policyreporter-fsm %s: %v\n", c.name, err)
+	return exitUsage
+}
+
+// fail reports an error, as {"error": ...} with -json, and returns its exit status.
+func (c *cli) fail(err error) int {
+	code := exitCode(err)
+	if c.jsonOutput {
+		c.report(map[string]interface{}{"error": errorJSON(err), "exitCode": code})
+		return code
+	}
+	fmt.Fprintf(c.stderr, "policyreporter-fsm %s: %v\n", c.name, err)
+	return code
+}
+
+// exitCode maps an error to the exit status documented in the package comment.
+func exitCode(err error) int {
+	var automatonErr *fsm.AutomatonError
+	if errors.As(err, &automatonErr) {
+		return exitErrorTypeBase + int(automatonErr.Type)
+	}
+	return exitFailure
+}
+
+// errorJSON returns the value encoding an error in JSON output.
+func errorJSON(err error) interface{} {
+	var automatonErr *fsm.AutomatonError
+	if errors.As(err, &automatonErr) {
+		return automatonErr
+	}
+	return map[string]string{"message": err.Error()}
+}
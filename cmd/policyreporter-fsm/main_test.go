@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const turnstileYAML = `version: 1
+initial: locked
+states: [locked, unlocked, spare]
+alphabet: [coin, push]
+accepting: [unlocked]
+transitions:
+  locked: {coin: unlocked, push: locked}
+  unlocked: {coin: unlocked}
+  spare: {coin: unlocked, push: locked}
+`
+
+const parityJSON = `{
+  "version": 1,
+  "states": ["even", "odd"],
+  "alphabet": ["0", "1"],
+  "initialState": "even",
+  "acceptingStates": ["even"],
+  "transitions": [
+    {"from": "even", "symbol": "0", "to": "even"},
+    {"from": "even", "symbol": "1", "to": "odd"},
+    {"from": "odd", "symbol": "0", "to": "odd"},
+    {"from": "odd", "symbol": "1", "to": "even"}
+  ]
+}
+`
+
+// writeMachine writes a definition file into a temporary directory
+func writeMachine(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+	return path
+}
+
+// execute runs the command line and returns its exit status and output
+func execute(args ...string) (int, string, string) {
+	return executeWithInput("", args...)
+}
+
+// executeWithInput runs the command line with the given standard input
+func executeWithInput(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestRunCommand tests accepted, rejected and failing inputs
+func TestRunCommand(t *testing.T) {
+	turnstile := writeMachine(t, "turnstile.yaml", turnstileYAML)
+	parity := writeMachine(t, "parity.json", parityJSON)
+
+	tests := []struct {
+		name     string
+		args     []string
+		code     int
+		contains string
+	}{
+		{"accepted", []string{"run", turnstile, "coin"}, exitOK, "accepted\ntrace: locked -> unlocked\n"},
+		{"rejected input", []string{"run", turnstile, "push"}, exitRejected, "rejected\ntrace: locked -> locked\n"},
+		{"runes", []string{"run", "-symbols", "rune", parity, "0110"}, exitOK, "trace: even -> even -> odd -> even -> even\n"},
+		{"unknown symbol", []string{"run", turnstile, "kick"}, 12, ""},
+		{"undefined transition", []string{"run", turnstile, "coin", "push"}, 11, "trace: locked -> unlocked\n"},
+		{"missing file", []string{"run", filepath.Join(t.TempDir(), "missing.json")}, exitFailure, ""},
+		{"wrong symbol kind", []string{"run", "-symbols", "rune", turnstile}, 10, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, stdout, stderr := execute(tt.args...)
+			if code != tt.code {
+				t.Errorf("exit status %d, want %d (stderr: %s)", code, tt.code, stderr)
+			}
+			if !strings.Contains(stdout, tt.contains) {
+				t.Errorf("stdout %q does not contain %q", stdout, tt.contains)
+			}
+		})
+	}
+}
+
+// TestRunCommand_JSON tests the machine-readable output of run
+func TestRunCommand_JSON(t *testing.T) {
+	turnstile := writeMachine(t, "turnstile.yaml", turnstileYAML)
+
+	code, stdout, _ := execute("run", "-json", turnstile, "coin", "push")
+	if code != 11 {
+		t.Errorf("exit status %d, want 11", code)
+	}
+	var result struct {
+		Accepted   bool     `json:"accepted"`
+		FinalState string   `json:"finalState"`
+		Trace      []string `json:"trace"`
+		ExitCode   int      `json:"exitCode"`
+		Error      struct {
+			Type    string                 `json:"type"`
+			Context map[string]interface{} `json:"context"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", stdout, err)
+	}
+	if result.Accepted || result.FinalState != "unlocked" || len(result.Trace) != 2 || result.ExitCode != 11 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if result.Error.Type != "TransitionError" {
+		t.Errorf("error type %q, want TransitionError", result.Error.Type)
+	}
+}
+
+// TestValidateCommand tests default and strict validation
+func TestValidateCommand(t *testing.T) {
+	turnstile := writeMachine(t, "turnstile.yaml", turnstileYAML)
+	parity := writeMachine(t, "parity.json", parityJSON)
+	broken := writeMachine(t, "broken.json", `{"version": 1, "states": [], "alphabet": [], "initialState": "a",
+		"acceptingStates": [], "transitions": []}`)
+
+	if code, stdout, _ := execute("validate", turnstile); code != exitOK || stdout != "valid\n" {
+		t.Errorf("validate = %d, %q; want valid", code, stdout)
+	}
+	if code, _, _ := execute("validate", "-strict", parity); code != exitOK {
+		t.Errorf("strict validate of a complete automaton = %d, want 0", code)
+	}
+
+	code, stdout, _ := execute("validate", "-strict", turnstile)
+	if code != 10 || !strings.HasPrefix(stdout, "invalid:\n  - ") {
+		t.Errorf("strict validate = %d, %q; want a list of problems", code, stdout)
+	}
+
+	code, stdout, _ = execute("validate", "-json", broken)
+	var result struct {
+		Valid  bool              `json:"valid"`
+		Errors []json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", stdout, err)
+	}
+	if code != 10 || result.Valid || len(result.Errors) < 2 {
+		t.Errorf("validate -json = %d, %s; want every problem listed", code, stdout)
+	}
+}
+
+// TestMinimizeCommand tests that unreachable and equivalent states are removed
+func TestMinimizeCommand(t *testing.T) {
+	turnstile := writeMachine(t, "turnstile.yaml", turnstileYAML)
+
+	code, stdout, stderr := execute("minimize", "-to", "json", turnstile)
+	if code != exitOK {
+		t.Fatalf("minimize = %d: %s", code, stderr)
+	}
+	if strings.Contains(stdout, "spare") || !strings.Contains(stdout, `"initialState": "locked"`) {
+		t.Errorf("unexpected minimized machine:\n%s", stdout)
+	}
+
+	code, stdout, _ = execute("minimize", "-json", turnstile)
+	var summary struct {
+		States          int               `json:"states"`
+		MinimizedStates int               `json:"minimizedStates"`
+		StateMapping    map[string]string `json:"stateMapping"`
+		Definition      string            `json:"definition"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &summary); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", stdout, err)
+	}
+	if code != exitOK || summary.States != 3 || summary.MinimizedStates != 2 || !strings.HasPrefix(summary.Definition, "version: 1") {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+}
+
+// TestConvertCommand tests conversion between formats through a file
+func TestConvertCommand(t *testing.T) {
+	turnstile := writeMachine(t, "turnstile.yaml", turnstileYAML)
+	output := filepath.Join(t.TempDir(), "turnstile.scxml")
+
+	if code, _, stderr := execute("convert", "-to", "scxml", "-o", output, turnstile); code != exitOK {
+		t.Fatalf("convert = %d: %s", code, stderr)
+	}
+	if code, stdout, _ := execute("run", output, "coin"); code != exitOK || !strings.HasPrefix(stdout, "accepted") {
+		t.Errorf("converted machine run = %d, %q", code, stdout)
+	}
+
+	// With -json and -o the definition goes to the file and a summary to stdout.
+	var summary map[string]string
+	code, stdout, _ := execute("convert", "-json", "-to", "json", "-o", output, turnstile)
+	if err := json.Unmarshal([]byte(stdout), &summary); code != exitOK || err != nil || summary["format"] != "json" || summary["output"] != output {
+		t.Errorf("convert -json -o = %d, %q", code, stdout)
+	}
+	diagram := filepath.Join(t.TempDir(), "turnstile.dot")
+	code, stdout, _ = execute("render", "-json", "-o", diagram, turnstile)
+	if err := json.Unmarshal([]byte(stdout), &summary); code != exitOK || err != nil || summary["kind"] != "dot" || summary["output"] != diagram {
+		t.Errorf("render -json -o = %d, %q", code, stdout)
+	}
+	if data, err := os.ReadFile(diagram); err != nil || !strings.HasPrefix(string(data), "digraph fsm {") {
+		t.Errorf("Expected the diagram in %s, got %q, %v", diagram, data, err)
+	}
+
+	if code, _, _ := execute("convert", turnstile); code != exitUsage {
+		t.Errorf("convert without -to = %d, want %d", code, exitUsage)
+	}
+	if code, _, _ := execute("convert", "-to", "scxml", "-symbols", "int", writeMachine(t, "p.json", parityJSON)); code != exitFailure {
+		t.Errorf("convert of int symbols to scxml = %d, want %d", code, exitFailure)
+	}
+}
+
+// TestRenderCommand tests diagram output
+func TestRenderCommand(t *testing.T) {
+	parity := writeMachine(t, "parity.json", parityJSON)
+
+	tests := []struct {
+		args     []string
+		code     int
+		contains string
+	}{
+		{[]string{"render", parity}, exitOK, "digraph fsm {"},
+		{[]string{"render", "-symbols", "rune", parity, "1"}, exitOK, "even -> odd [color=red"},
+		{[]string{"render", "-as", "mermaid", parity}, exitOK, "stateDiagram-v2"},
+		{[]string{"render", "-as", "plantuml", parity}, exitOK, "@startuml"},
+		{[]string{"render", "-as", "mermaid", parity, "1"}, exitUsage, ""},
+		{[]string{"render", "-as", "svg", parity}, exitFailure, ""},
+	}
+	for _, tt := range tests {
+		code, stdout, stderr := execute(tt.args...)
+		if code != tt.code || !strings.Contains(stdout, tt.contains) {
+			t.Errorf("%v = %d, %q (stderr %q); want %d containing %q", tt.args, code, stdout, stderr, tt.code, tt.contains)
+		}
+	}
+}
+
+// TestRun_Usage tests the top-level command dispatch
+func TestRun_Usage(t *testing.T) {
+	if code, _, _ := execute(); code != exitUsage {
+		t.Errorf("no arguments = %d, want %d", code, exitUsage)
+	}
+	if code, _, _ := execute("frobnicate"); code != exitUsage {
+		t.Errorf("unknown command = %d, want %d", code, exitUsage)
+	}
+	if code, stdout, _ := execute("help"); code != exitOK || !strings.Contains(stdout, "commands:") {
+		t.Errorf("help = %d, %q", code, stdout)
+	}
+	if code, _, _ := execute("run"); code != exitUsage {
+		t.Errorf("run without a machine = %d, want %d", code, exitUsage)
+	}
+	if code, _, _ := execute("run", "-symbols", "float", "x.json"); code != exitUsage {
+		t.Errorf("unknown symbol kind = %d, want %d", code, exitUsage)
+	}
+}
+
+// TestDebugCommand tests an interactive session driven through standard input
+func TestDebugCommand(t *testing.T) {
+	parity := writeMachine(t, "parity.json", parityJSON)
+	session := strings.Join([]string{
+		"break odd",
+		"b even 0",
+		"continue",
+		"c",
+		"back",
+		"inspect",
+		"delete 1",
+		"feed 1",
+		"c",
+		"s",
+		"trace",
+		"frobnicate",
+		"quit",
+		"step",
+	}, "\n")
+
+	code, stdout, stderr := executeWithInput(session, "debug", "-symbols", "rune", parity, "0100")
+	if code != exitOK {
+		t.Fatalf("debug = %d: %s", code, stderr)
+	}
+	for _, line := range []string{
+		"state even (accepting) at position 0\n",
+		"(fsm) breakpoint #1 state odd\n",
+		"(fsm) breakpoint #2 transition from even on 0\n",
+		"(fsm) 1: even --0--> even\nhit breakpoint #2 transition from even on 0\n",
+		"(fsm) 2: even --1--> odd\nhit breakpoint #1 state odd\n",
+		"(fsm) state even (accepting) at position 1\nconsumed: 0\npending: 1 0 0\n",
+		"(fsm) (fsm) pending: 1 0 0 1\n",
+		"(fsm) 2: even --1--> odd\n3: odd --0--> odd\n4: odd --0--> odd\n5: odd --1--> even\nend of input: state even (accepting) at position 5\n",
+		"(fsm) error: [InvalidInputError] no pending input\n",
+		"(fsm) 0: even*\n1: even* --0--> even*\n2: even* --1--> odd\n",
+		"(fsm) error: unknown command \"frobnicate\", try help\n",
+	} {
+		if !strings.Contains(stdout, line) {
+			t.Errorf("Output is missing %q:\n%s", line, stdout)
+		}
+	}
+	if strings.Count(stdout, "(fsm) ") != 13 {
+		t.Errorf("Expected the session to end at quit:\n%s", stdout)
+	}
+}
@@ -45,7 +45,10 @@
 package fsm
 
 import (
+	"cmp"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -90,6 +93,9 @@ type FiniteAutomaton[Q State, S Symbol] struct {
 	// Current state (for stateful processing)
 	currentState Q
 
+	// Number of changes made to the definition, see Version
+	version uint64
+
 	// Thread safety
 	mutex sync.RWMutex
 }
@@ -111,6 +117,7 @@ func New[Q State, S Symbol](initialState Q) *FiniteAutomaton[Q, S] {
 // Returns the automaton for method chaining.
 func (fa *FiniteAutomaton[Q, S]) AddState(state Q) *FiniteAutomaton[Q, S] {
 	fa.states[state] = true
+	fa.version++
 	return fa
 }
 
@@ -120,6 +127,7 @@ func (fa *FiniteAutomaton[Q, S]) AddStates(states ...Q) *FiniteAutomaton[Q, S] {
 	for _, state := range states {
 		fa.states[state] = true
 	}
+	fa.version++
 	return fa
 }
 
@@ -127,6 +135,7 @@ func (fa *FiniteAutomaton[Q, S]) AddStates(states ...Q) *FiniteAutomaton[Q, S] {
 // Returns the automaton for method chaining.
 func (fa *FiniteAutomaton[Q, S]) AddSymbol(symbol S) *FiniteAutomaton[Q, S] {
 	fa.alphabet[symbol] = true
+	fa.version++
 	return fa
 }
 
@@ -136,6 +145,7 @@ func (fa *FiniteAutomaton[Q, S]) AddSymbols(symbols ...S) *FiniteAutomaton[Q, S]
 	for _, symbol := range symbols {
 		fa.alphabet[symbol] = true
 	}
+	fa.version++
 	return fa
 }
 
@@ -143,6 +153,7 @@ func (fa *FiniteAutomaton[Q, S]) AddSymbols(symbols ...S) *FiniteAutomaton[Q, S]
 // Returns the automaton for method chaining.
 func (fa *FiniteAutomaton[Q, S]) AddAcceptingState(state Q) *FiniteAutomaton[Q, S] {
 	fa.acceptingStates[state] = true
+	fa.version++
 	return fa
 }
 
@@ -152,6 +163,7 @@ func (fa *FiniteAutomaton[Q, S]) AddAcceptingStates(states ...Q) *FiniteAutomato
 	for _, state := range states {
 		fa.acceptingStates[state] = true
 	}
+	fa.version++
 	return fa
 }
 
@@ -163,9 +175,19 @@ func (fa *FiniteAutomaton[Q, S]) AddTransition(fromState Q, symbol S, toState Q)
 		fa.transitions[fromState] = make(map[S]Q)
 	}
 	fa.transitions[fromState][symbol] = toState
+	fa.version++
 	return fa
 }
 
+// Version returns a counter incremented by every change to the definition.
+// Caches keyed on an automaton compare versions to detect changes.
+// This method is thread-safe.
+func (fa *FiniteAutomaton[Q, S]) Version() uint64 {
+	fa.mutex.RLock()
+	defer fa.mutex.RUnlock()
+	return fa.version
+}
+
 // GetInitialState returns the initial state q0.
 // This method is thread-safe.
 func (fa *FiniteAutomaton[Q, S]) GetInitialState() Q {
@@ -174,6 +196,14 @@ func (fa *FiniteAutomaton[Q, S]) GetInitialState() Q {
 	return fa.initialState
 }
 
+// GetStates returns the states Q in a deterministic order.
+// This method is thread-safe.
+func (fa *FiniteAutomaton[Q, S]) GetStates() []Q {
+	fa.mutex.RLock()
+	defer fa.mutex.RUnlock()
+	return sortValues(fa.getStatesList())
+}
+
 // GetCurrentState returns the current state during processing.
 // This method is thread-safe.
 func (fa *FiniteAutomaton[Q, S]) GetCurrentState() Q {
@@ -213,21 +243,42 @@ func (fa *FiniteAutomaton[Q, S]) Step(symbol S) (Q, error) {
 	fa.mutex.Lock()
 	defer fa.mutex.Unlock()
 
+	nextState, err := fa.transition(fa.currentState, symbol)
+	if err != nil {
+		return nextState, err
+	}
+
+	fa.currentState = nextState
+	return fa.currentState, nil
+}
+
+// Transition returns the state reached from the given state on symbol without
+// changing the current state (implements StateTransitioner interface). The
+// errors are those of Step.
+func (fa *FiniteAutomaton[Q, S]) Transition(state Q, symbol S) (Q, error) {
+	fa.mutex.RLock()
+	defer fa.mutex.RUnlock()
+	return fa.transition(state, symbol)
+}
+
+// transition looks up the transition from state on symbol.
+// Callers must hold the mutex.
+func (fa *FiniteAutomaton[Q, S]) transition(state Q, symbol S) (Q, error) {
 	// Validate symbol is in alphabet
 	if !fa.alphabet[symbol] {
 		var zero Q
-		return zero, fmt.Errorf("symbol not in alphabet: %v", symbol)
+		return zero, NewErrorWithContext(ErrorTypeInvalidInput,
+			fmt.Sprintf("symbol not in alphabet: %v", symbol), map[string]interface{}{"symbol": symbol})
 	}
 
 	// Get transition
-	nextState, exists := fa.transitions[fa.currentState][symbol]
+	nextState, exists := fa.transitions[state][symbol]
 	if !exists {
 		var zero Q
-		return zero, fmt.Errorf("no transition defined for state %v with symbol %v", fa.currentState, symbol)
+		return zero, NewTransitionError(state, symbol,
+			fmt.Sprintf("no transition defined for state %v with symbol %v", state, symbol))
 	}
-
-	fa.currentState = nextState
-	return fa.currentState, nil
+	return nextState, nil
 }
 
 // ProcessInput processes a sequence of input symbols.
@@ -350,3 +401,44 @@ func (fa *FiniteAutomaton[Q, S]) getAcceptingStatesList() []Q {
 	}
 	return states
 }
+
+// sortValues orders states or symbols deterministically so that algorithms and
+// output formats do not depend on map iteration order. Strings and numbers are
+// ordered by value; other types fall back to their %v representation.
+func sortValues[T comparable](values []T) []T {
+	slices.SortStableFunc(values, compareValues[T])
+	return values
+}
+
+func compareValues[T comparable](a, b T) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.IsValid() && vb.IsValid() && va.Kind() == vb.Kind() {
+		switch va.Kind() {
+		case reflect.String:
+			return strings.Compare(va.String(), vb.String())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return cmp.Compare(va.Int(), vb.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return cmp.Compare(va.Uint(), vb.Uint())
+		case reflect.Float32, reflect.Float64:
+			return cmp.Compare(va.Float(), vb.Float())
+		default:
+		}
+	}
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+}
+
+// finiteAutomatonOf returns the FiniteAutomaton behind an Automaton value, looking
+// through wrappers such as ObservableAutomaton. Algorithms that walk the
+// definition rather than run it use this to accept any Automaton.
+func finiteAutomatonOf[Q State, S Symbol](automaton Automaton[Q, S]) (*FiniteAutomaton[Q, S], error) {
+	switch a := automaton.(type) {
+	case *FiniteAutomaton[Q, S]:
+		return a, nil
+	case *ObservableAutomaton[Q, S]:
+		return finiteAutomatonOf(a.automaton)
+	default:
+		return nil, NewInvalidConfigurationError("automaton",
+			fmt.Sprintf("operation requires a *FiniteAutomaton, got %T", automaton))
+	}
+}
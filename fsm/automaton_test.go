@@ -34,6 +34,14 @@ func TestFiniteAutomaton_AddStates(t *testing.T) {
 	}
 }
 
+// TestFiniteAutomaton_GetStates tests listing the states
+func TestFiniteAutomaton_GetStates(t *testing.T) {
+	fa := New[string, rune]("q0").AddStates("q2", "q0", "q1")
+	if got := fa.GetStates(); strings.Join(got, ",") != "q0,q1,q2" {
+		t.Errorf("GetStates() = %v, want [q0 q1 q2]", got)
+	}
+}
+
 // TestFiniteAutomaton_AddSymbols tests adding alphabet symbols
 func TestFiniteAutomaton_AddSymbols(t *testing.T) {
 	fa := New[string, rune]("q0").
@@ -60,6 +60,29 @@ func (b *AutomatonBuilder[Q, S]) WithTransitions(transitions ...Transition[Q, S]
 	return b
 }
 
+// WithEpsilon adds an ε-transition from one state to another. Since ε-moves
+// make the automaton nondeterministic, this converts the builder into an
+// NFABuilder that carries over everything configured so far.
+func (b *AutomatonBuilder[Q, S]) WithEpsilon(from Q, to Q) *NFABuilder[Q, S] {
+	nfaBuilder := NewNFABuilderWithValidation[Q, S](b.automaton.initialState, b.validator.config)
+	nfa := nfaBuilder.automaton
+	for state := range b.automaton.states {
+		nfa.AddState(state)
+	}
+	for symbol := range b.automaton.alphabet {
+		nfa.AddSymbol(symbol)
+	}
+	for state := range b.automaton.acceptingStates {
+		nfa.AddAcceptingState(state)
+	}
+	for fromState, transitions := range b.automaton.transitions {
+		for symbol, toState := range transitions {
+			nfa.AddTransition(fromState, symbol, toState)
+		}
+	}
+	return nfaBuilder.WithEpsilon(from, to)
+}
+
 // Build finalizes the automaton and validates its configuration.
 // Returns an error if the automaton is not properly configured.
 func (b *AutomatonBuilder[Q, S]) Build() (Automaton[Q, S], error) {
@@ -86,6 +109,94 @@ func (b *AutomatonBuilder[Q, S]) MustBuild() Automaton[Q, S] {
 	return fa
 }
 
+// NFABuilder provides a fluent interface for constructing nondeterministic automata.
+// Transitions added for the same state and symbol accumulate instead of overwriting.
+type NFABuilder[Q State, S Symbol] struct {
+	automaton *NFA[Q, S]
+	validator *NFAValidator[Q, S]
+}
+
+// NewNFABuilder creates a new builder for constructing an NFA.
+// The initial state q0 must be specified.
+func NewNFABuilder[Q State, S Symbol](initialState Q) *NFABuilder[Q, S] {
+	return &NFABuilder[Q, S]{
+		automaton: NewNFA[Q, S](initialState),
+		validator: NewNFAValidator[Q, S](DefaultValidatorConfig()),
+	}
+}
+
+// NewNFABuilderWithValidation creates a new NFA builder with custom validation configuration.
+func NewNFABuilderWithValidation[Q State, S Symbol](initialState Q, config ValidatorConfig) *NFABuilder[Q, S] {
+	return &NFABuilder[Q, S]{
+		automaton: NewNFA[Q, S](initialState),
+		validator: NewNFAValidator[Q, S](config),
+	}
+}
+
+// WithStates adds states to the automaton's set Q.
+// The initial state is automatically added.
+func (b *NFABuilder[Q, S]) WithStates(states ...Q) *NFABuilder[Q, S] {
+	b.automaton.AddStates(states...)
+	// Ensure initial state is in Q
+	b.automaton.AddState(b.automaton.initialState)
+	return b
+}
+
+// WithAlphabet sets the input alphabet Σ.
+func (b *NFABuilder[Q, S]) WithAlphabet(symbols ...S) *NFABuilder[Q, S] {
+	b.automaton.AddSymbols(symbols...)
+	return b
+}
+
+// WithAcceptingStates sets the accepting states F.
+func (b *NFABuilder[Q, S]) WithAcceptingStates(states ...Q) *NFABuilder[Q, S] {
+	b.automaton.AddAcceptingStates(states...)
+	return b
+}
+
+// WithTransition adds "to" to the set δ(from, symbol).
+func (b *NFABuilder[Q, S]) WithTransition(from Q, symbol S, to Q) *NFABuilder[Q, S] {
+	b.automaton.AddTransition(from, symbol, to)
+	return b
+}
+
+// WithTransitions adds multiple transitions at once.
+func (b *NFABuilder[Q, S]) WithTransitions(transitions ...Transition[Q, S]) *NFABuilder[Q, S] {
+	for _, t := range transitions {
+		b.automaton.AddTransition(t.From, t.Symbol, t.To)
+	}
+	return b
+}
+
+// WithEpsilon adds an ε-transition from one state to another.
+func (b *NFABuilder[Q, S]) WithEpsilon(from Q, to Q) *NFABuilder[Q, S] {
+	b.automaton.AddEpsilonTransition(from, to)
+	return b
+}
+
+// Build finalizes the NFA and validates its configuration.
+// Returns an error if the automaton is not properly configured.
+func (b *NFABuilder[Q, S]) Build() (*NFA[Q, S], error) {
+	if err := b.validator.Validate(b.automaton); err != nil {
+		return nil, err
+	}
+
+	if err := b.automaton.Validate(); err != nil {
+		return nil, err
+	}
+
+	return b.automaton, nil
+}
+
+// MustBuild finalizes the NFA and panics if validation fails.
+func (b *NFABuilder[Q, S]) MustBuild() *NFA[Q, S] {
+	nfa, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return nfa
+}
+
 // Transition represents a single state transition δ(From, Symbol) = To.
 type Transition[Q State, S Symbol] struct {
 	From   Q
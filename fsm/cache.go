@@ -0,0 +1,320 @@
+package fsm
+
+import (
+	"container/list"
+	"context"
+	"hash/maphash"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// CacheConfig bounds the memory used by caching processors.
+type CacheConfig struct {
+	// MaxEntries is the number of results kept; beyond it the least recently
+	// used result is evicted. Zero or less means no limit.
+	MaxEntries int
+	// MaxInputLength is the length of the longest input cached; longer inputs
+	// are processed without caching. Zero or less means no limit.
+	MaxInputLength int
+}
+
+// DefaultCacheConfig returns the default cache configuration.
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{
+		MaxEntries:     1024,
+		MaxInputLength: 4096,
+	}
+}
+
+// CacheStats reports the activity of a caching processor.
+type CacheStats struct {
+	// Hits counts lookups answered from the cache
+	Hits uint64
+	// Misses counts lookups that had to be processed
+	Misses uint64
+	// Evictions counts results dropped to stay within MaxEntries
+	Evictions uint64
+	// Invalidations counts results dropped because their automaton changed or
+	// was invalidated
+	Invalidations uint64
+	// Entries is the number of results currently cached
+	Entries int
+}
+
+// HitRate returns the fraction of lookups answered from the cache.
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// cacheIdentity returns the key identifying the definition of an automaton
+// and its current version. Sessions of one Machine share a definition and are
+// identified by the machine. Automata that are not pointers have no stable
+// identity and cannot be cached, and neither can observable automata, whose
+// observers expect to be notified of every run.
+func cacheIdentity[Q State, S Symbol](automaton Automaton[Q, S]) (identity any, version uint64, ok bool) {
+	switch automaton := automaton.(type) {
+	case *Session[Q, S]:
+		return automaton.Machine(), 0, true
+	case *ObservableAutomaton[Q, S]:
+		return nil, 0, false
+	}
+	if automaton == nil || reflect.ValueOf(automaton).Kind() != reflect.Pointer {
+		return nil, 0, false
+	}
+	if versioned, isVersioned := automaton.(Versioned); isVersioned {
+		version = versioned.Version()
+	}
+	return automaton, version, true
+}
+
+// cacheOwner tracks the cached results of one automaton. Holding the identity
+// as a map key keeps the automaton alive, so its address is never reused by
+// another automaton while results are cached.
+type cacheOwner struct {
+	version uint64
+	entries int
+}
+
+// cacheEntry is a cached result and the exact input it was computed for.
+type cacheEntry[Q State, S Symbol] struct {
+	hash     uint64
+	identity any
+	input    []S
+	result   ProcessResult[Q]
+}
+
+// OptimizedProcessor memoizes processing results. Results are keyed by the
+// exact input and the automaton they were computed against, and kept in a
+// least-recently-used cache bounded by a CacheConfig.
+//
+// Results of a Versioned automaton, such as a FiniteAutomaton, are dropped as
+// soon as its definition changes. Other automata are assumed not to change
+// once processed; call Invalidate after changing one. A cache hit does not
+// step the automaton, so its current state is unchanged. Errors are never
+// cached, and an ObservableAutomaton is always processed so that its observers
+// are notified.
+type OptimizedProcessor[Q State, S Symbol] struct {
+	config CacheConfig
+	seed   maphash.Seed
+
+	// entries maps input hashes to elements of lru holding *cacheEntry values
+	entries map[uint64][]*list.Element
+	// lru lists the entries from most to least recently used
+	lru    *list.List
+	owners map[any]*cacheOwner
+	stats  CacheStats
+	mutex  sync.Mutex
+}
+
+// NewOptimizedProcessor creates a new optimized processor with the default
+// cache configuration.
+func NewOptimizedProcessor[Q State, S Symbol]() *OptimizedProcessor[Q, S] {
+	return NewOptimizedProcessorWithConfig[Q, S](DefaultCacheConfig())
+}
+
+// NewOptimizedProcessorWithConfig creates a new optimized processor with the
+// given cache configuration.
+func NewOptimizedProcessorWithConfig[Q State, S Symbol](config CacheConfig) *OptimizedProcessor[Q, S] {
+	return &OptimizedProcessor[Q, S]{
+		config:  config,
+		seed:    maphash.MakeSeed(),
+		entries: make(map[uint64][]*list.Element),
+		lru:     list.New(),
+		owners:  make(map[any]*cacheOwner),
+	}
+}
+
+// Process processes input, answering repeated inputs from the cache.
+func (p *OptimizedProcessor[Q, S]) Process(automaton Automaton[Q, S], input []S) (ProcessResult[Q], error) {
+	return p.ProcessContext(context.Background(), automaton, input)
+}
+
+// ProcessContext processes input with cancellation, answering repeated inputs
+// from the cache (implements ContextProcessor interface).
+func (p *OptimizedProcessor[Q, S]) ProcessContext(
+	ctx context.Context,
+	automaton Automaton[Q, S],
+	input []S,
+) (ProcessResult[Q], error) {
+	processor := NewStandardProcessor[Q, S]()
+	identity, version, ok := cacheIdentity(automaton)
+	if !ok || input == nil || (p.config.MaxInputLength > 0 && len(input) > p.config.MaxInputLength) {
+		p.mutex.Lock()
+		p.stats.Misses++
+		p.mutex.Unlock()
+		return processor.ProcessContext(ctx, automaton, input)
+	}
+
+	hash := p.hash(identity, input)
+	p.mutex.Lock()
+	p.checkVersion(identity, version)
+	if element := p.lookup(hash, identity, input); element != nil {
+		p.lru.MoveToFront(element)
+		p.stats.Hits++
+		result := cloneResult(element.Value.(*cacheEntry[Q, S]).result)
+		p.mutex.Unlock()
+		return result, nil
+	}
+	p.stats.Misses++
+	p.mutex.Unlock()
+
+	result, err := processor.ProcessContext(ctx, automaton, input)
+	if err != nil {
+		return result, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	// Another goroutine may have cached the input, or changed the automaton,
+	// while it was processed.
+	if p.lookup(hash, identity, input) == nil && p.checkVersion(identity, version) {
+		p.store(&cacheEntry[Q, S]{
+			hash:     hash,
+			identity: identity,
+			input:    slices.Clone(input),
+			result:   cloneResult(result),
+		}, version)
+	}
+	return result, nil
+}
+
+// hash returns the hash of an input processed by the identified automaton.
+func (p *OptimizedProcessor[Q, S]) hash(identity any, input []S) uint64 {
+	var h maphash.Hash
+	h.SetSeed(p.seed)
+	maphash.WriteComparable(&h, identity)
+	for _, symbol := range input {
+		maphash.WriteComparable(&h, symbol)
+	}
+	return h.Sum64()
+}
+
+// lookup returns the element caching the input, or nil. The caller must hold
+// the mutex.
+func (p *OptimizedProcessor[Q, S]) lookup(hash uint64, identity any, input []S) *list.Element {
+	for _, element := range p.entries[hash] {
+		entry := element.Value.(*cacheEntry[Q, S])
+		if entry.identity == identity && slices.Equal(entry.input, input) {
+			return element
+		}
+	}
+	return nil
+}
+
+// checkVersion drops the results of the identified automaton when they were
+// computed against an older version, and reports whether version is the most
+// recent version seen. The caller must hold the mutex.
+func (p *OptimizedProcessor[Q, S]) checkVersion(identity any, version uint64) bool {
+	owner, exists := p.owners[identity]
+	if !exists {
+		return true
+	}
+	if version < owner.version {
+		return false
+	}
+	if version > owner.version {
+		p.removeOwner(identity)
+	}
+	return true
+}
+
+// store adds an entry computed against the given version of its automaton,
+// evicting the least recently used entries beyond MaxEntries. The caller must
+// hold the mutex.
+func (p *OptimizedProcessor[Q, S]) store(entry *cacheEntry[Q, S], version uint64) {
+	owner, exists := p.owners[entry.identity]
+	if !exists {
+		owner = &cacheOwner{}
+		p.owners[entry.identity] = owner
+	}
+	owner.version = version
+	owner.entries++
+
+	p.entries[entry.hash] = append(p.entries[entry.hash], p.lru.PushFront(entry))
+	for p.config.MaxEntries > 0 && p.lru.Len() > p.config.MaxEntries {
+		p.remove(p.lru.Back())
+		p.stats.Evictions++
+	}
+}
+
+// remove drops an entry. The caller must hold the mutex.
+func (p *OptimizedProcessor[Q, S]) remove(element *list.Element) {
+	entry := p.lru.Remove(element).(*cacheEntry[Q, S])
+
+	bucket := slices.DeleteFunc(p.entries[entry.hash], func(e *list.Element) bool { return e == element })
+	if len(bucket) == 0 {
+		delete(p.entries, entry.hash)
+	} else {
+		p.entries[entry.hash] = bucket
+	}
+
+	owner := p.owners[entry.identity]
+	owner.entries--
+	if owner.entries == 0 {
+		delete(p.owners, entry.identity)
+	}
+}
+
+// removeOwner drops every entry of the identified automaton. The caller must
+// hold the mutex.
+func (p *OptimizedProcessor[Q, S]) removeOwner(identity any) {
+	for element := p.lru.Front(); element != nil; {
+		next := element.Next()
+		if element.Value.(*cacheEntry[Q, S]).identity == identity {
+			p.remove(element)
+			p.stats.Invalidations++
+		}
+		element = next
+	}
+}
+
+// Invalidate drops the cached results of an automaton. It is needed only
+// for automata that change without being Versioned.
+func (p *OptimizedProcessor[Q, S]) Invalidate(automaton Automaton[Q, S]) {
+	identity, _, ok := cacheIdentity(automaton)
+	if !ok {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, exists := p.owners[identity]; exists {
+		p.removeOwner(identity)
+	}
+}
+
+// ClearCache clears the processor's cache. Statistics other than the number
+// of entries are kept.
+func (p *OptimizedProcessor[Q, S]) ClearCache() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.entries = make(map[uint64][]*list.Element)
+	p.lru.Init()
+	p.owners = make(map[any]*cacheOwner)
+}
+
+// GetCacheSize returns the number of cached results.
+func (p *OptimizedProcessor[Q, S]) GetCacheSize() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.lru.Len()
+}
+
+// Stats returns the cache statistics.
+func (p *OptimizedProcessor[Q, S]) Stats() CacheStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	stats := p.stats
+	stats.Entries = p.lru.Len()
+	return stats
+}
+
+// cloneResult copies the trace of a result, so that callers cannot modify
+// cached results.
+func cloneResult[Q State](result ProcessResult[Q]) ProcessResult[Q] {
+	result.Trace = slices.Clone(result.Trace)
+	return result
+}
@@ -0,0 +1,181 @@
+package fsm
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestOptimizedProcessor_ExactKeys tests that inputs of the same length are cached separately
+func TestOptimizedProcessor_ExactKeys(t *testing.T) {
+	fa := newEndsWithZeroAutomaton()
+	processor := NewOptimizedProcessor[string, rune]()
+
+	for round := 0; round < 2; round++ {
+		for _, input := range []string{"10", "01", "00", "11"} {
+			want, _, _ := fa.ProcessInputWithTrace([]rune(input))
+			result, err := processor.Process(fa, []rune(input))
+			if err != nil {
+				t.Fatalf("Process returned error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Trace, want) || result.Accepted != (input[1] == '0') {
+				t.Errorf("Round %d, input %q: got %+v, want trace %v", round, input, result, want)
+			}
+		}
+	}
+
+	stats := processor.Stats()
+	if stats.Hits != 4 || stats.Misses != 4 || stats.Entries != 4 || stats.HitRate() != 0.5 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+// TestOptimizedProcessor_Eviction tests the least-recently-used bound
+func TestOptimizedProcessor_Eviction(t *testing.T) {
+	fa := newParityAutomaton()
+	processor := NewOptimizedProcessorWithConfig[string, rune](CacheConfig{MaxEntries: 2, MaxInputLength: 3})
+
+	for _, input := range []string{"0", "1", "0", "11"} {
+		if _, err := processor.Process(fa, []rune(input)); err != nil {
+			t.Fatalf("Process returned error: %v", err)
+		}
+	}
+	// "1" was the least recently used when "11" was added.
+	processor.Process(fa, []rune("0"))
+	processor.Process(fa, []rune("1"))
+	stats := processor.Stats()
+	if stats.Evictions != 2 || stats.Hits != 2 || stats.Misses != 4 || processor.GetCacheSize() != 2 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// Inputs over MaxInputLength are not cached.
+	processor.Process(fa, []rune("0000"))
+	processor.Process(fa, []rune("0000"))
+	if stats := processor.Stats(); stats.Misses != 6 || stats.Entries != 2 {
+		t.Errorf("Expected long inputs to bypass the cache, got %+v", stats)
+	}
+
+	processor.ClearCache()
+	if stats := processor.Stats(); stats.Entries != 0 || stats.Hits != 2 {
+		t.Errorf("Expected ClearCache to drop entries only, got %+v", stats)
+	}
+}
+
+// TestOptimizedProcessor_Invalidation tests that changing an automaton drops its results
+func TestOptimizedProcessor_Invalidation(t *testing.T) {
+	fa := New[string, rune]("a").
+		AddStates("a", "b").
+		AddSymbols('x').
+		AddTransition("a", 'x', "b")
+	other := newParityAutomaton()
+	processor := NewOptimizedProcessor[string, rune]()
+
+	if result, _ := processor.Process(fa, []rune("x")); result.Accepted {
+		t.Fatalf("Expected x to be rejected before b is accepting")
+	}
+	processor.Process(other, []rune("1"))
+
+	version := fa.Version()
+	fa.AddAcceptingState("b")
+	if fa.Version() == version {
+		t.Fatalf("Expected AddAcceptingState to change the version")
+	}
+	if result, _ := processor.Process(fa, []rune("x")); !result.Accepted {
+		t.Errorf("Expected the cached result to be invalidated")
+	}
+	stats := processor.Stats()
+	if stats.Invalidations != 1 || stats.Entries != 2 {
+		t.Errorf("Expected only the changed automaton's result to be dropped, got %+v", stats)
+	}
+
+	// An observable wrapper is never cached, so its observers see every run.
+	debug := NewDebugObserver[string, rune]()
+	observable := NewObservableAutomaton[string, rune](fa)
+	observable.AddObserver(debug)
+	processor.Process(observable, []rune("x"))
+	processor.Process(observable, []rune("x"))
+	if len(debug.GetTransitions()) != 2 {
+		t.Errorf("Expected 2 observed transitions, got %d", len(debug.GetTransitions()))
+	}
+	if stats := processor.Stats(); stats.Entries != 2 || stats.Hits != 0 {
+		t.Errorf("Expected the wrapper's results not to be cached, got %+v", stats)
+	}
+
+	processor.Invalidate(other)
+	if _, err := processor.Process(other, []rune("1")); err != nil || processor.Stats().Hits != 0 {
+		t.Errorf("Expected Invalidate to drop the results, got %+v", processor.Stats())
+	}
+}
+
+// TestOptimizedProcessor_Copies tests that callers cannot corrupt cached traces
+func TestOptimizedProcessor_Copies(t *testing.T) {
+	fa := newParityAutomaton()
+	processor := NewOptimizedProcessor[string, rune]()
+	input := []rune("11")
+
+	first, _ := processor.Process(fa, input)
+	first.Trace[0] = "corrupted"
+	input[0] = '0'
+	second, _ := processor.Process(fa, []rune("11"))
+	if !reflect.DeepEqual(second.Trace, []string{"even", "odd", "even"}) {
+		t.Errorf("Cached trace was modified: %v", second.Trace)
+	}
+	second.Trace[1] = "corrupted"
+	if third, _ := processor.Process(fa, []rune("11")); third.Trace[1] != "odd" {
+		t.Errorf("Cached trace was modified: %v", third.Trace)
+	}
+}
+
+// TestOptimizedProcessor_Sessions tests that sessions of one machine share results
+func TestOptimizedProcessor_Sessions(t *testing.T) {
+	machine, err := Compile[string, rune](newParityAutomaton())
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+	processor := NewOptimizedProcessor[string, rune]()
+
+	processor.Process(machine.NewSession(), []rune("101"))
+	if result, err := processor.Process(machine.NewSession(), []rune("101")); err != nil || !result.Accepted {
+		t.Errorf("Process = %+v, %v; want accepted", result, err)
+	}
+	if stats := processor.Stats(); stats.Hits != 1 {
+		t.Errorf("Expected a hit across sessions, got %+v", stats)
+	}
+
+	// Errors are not cached.
+	processor.Process(machine.NewSession(), []rune("2"))
+	processor.Process(machine.NewSession(), []rune("2"))
+	if stats := processor.Stats(); stats.Entries != 1 {
+		t.Errorf("Expected errors not to be cached, got %+v", stats)
+	}
+}
+
+// TestOptimizedProcessor_Concurrent tests concurrent use of one processor
+func TestOptimizedProcessor_Concurrent(t *testing.T) {
+	machine, err := Compile[string, rune](newEndsWithZeroAutomaton())
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+	processor := NewOptimizedProcessorWithConfig[string, rune](CacheConfig{MaxEntries: 16})
+	inputs := randomInputs([]rune("01"), 100, 8)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			session := machine.NewSession()
+			for _, input := range inputs {
+				want, _ := machine.ProcessInput(input)
+				if result, err := processor.Process(session, input); err != nil || result.Accepted != want {
+					t.Errorf("Input %q: got %+v, %v; want %v", string(input), result, err, want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if size := processor.GetCacheSize(); size > 16 {
+		t.Errorf("Cache grew to %d entries", size)
+	}
+}
@@ -0,0 +1,422 @@
+package fsm
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ModulePath is the import path of this package, used by generated tests.
+const ModulePath = "github.com/dsonic0912/PolicyReporter-FSM/fsm"
+
+// GoGeneratorConfig configures the Go source emitted by GoGenerator.
+type GoGeneratorConfig struct {
+	// Package is the package clause of the generated files
+	Package string
+	// Prefix is prepended to every generated identifier, so that several
+	// machines can share a package. With the prefix "Parity" the state type is
+	// ParityState and the step function ParityStep.
+	Prefix string
+	// Generator names the tool in the "Code generated" header
+	Generator string
+	// TestInputs is the number of random inputs checked by the generated test
+	TestInputs int
+	// TestMaxLength bounds the length of the random inputs
+	TestMaxLength int
+}
+
+// DefaultGoGeneratorConfig returns a configuration for package "main"
+// without an identifier prefix.
+func DefaultGoGeneratorConfig() GoGeneratorConfig {
+	return GoGeneratorConfig{
+		Package:       "main",
+		Generator:     "fsmgen",
+		TestInputs:    1000,
+		TestMaxLength: 32,
+	}
+}
+
+// GoGenerator emits a standalone Go implementation of a FiniteAutomaton that
+// executes without maps, interfaces or locks.
+//
+// The generated file declares a State type with one constant per state, an
+// InitialState constant, State.String and State.IsAccepting methods, an
+// InAlphabet function, and Step and ProcessInput functions built from switch
+// statements. Step and ProcessInput accept, reject and fail on exactly the same
+// inputs as FiniteAutomaton.Step and FiniteAutomaton.ProcessInput. Their errors
+// are plain errors created with fmt.Errorf rather than AutomatonErrors, and
+// their messages differ; on error Step returns the state it was given.
+//
+// Symbols are written in the generated code with their underlying basic type
+// (string, rune, byte or an integer type); automata over other symbol types
+// cannot be generated. State names come from the state codec.
+type GoGenerator[Q State, S Symbol] struct {
+	config GoGeneratorConfig
+	states Codec[Q]
+}
+
+// NewGoGenerator creates a generator using DefaultCodec for state names.
+func NewGoGenerator[Q State, S Symbol](config GoGeneratorConfig) *GoGenerator[Q, S] {
+	return &GoGenerator[Q, S]{
+		config: config,
+		states: DefaultCodec[Q](),
+	}
+}
+
+// WithStateCodec replaces the codec naming the states in the generated code.
+func (g *GoGenerator[Q, S]) WithStateCodec(states Codec[Q]) *GoGenerator[Q, S] {
+	g.states = states
+	return g
+}
+
+// goMachine is the validated, named form of an automaton used by both templates.
+type goMachine[Q State, S Symbol] struct {
+	fa         *FiniteAutomaton[Q, S]
+	states     []Q
+	symbols    []S
+	names      map[Q]string
+	constants  map[Q]string
+	literals   map[S]string
+	literal    func(S) string
+	symbolType string
+}
+
+func (g *GoGenerator[Q, S]) prepare(automaton Automaton[Q, S]) (*goMachine[Q, S], error) {
+	if !token.IsIdentifier(g.config.Package) {
+		return nil, NewInvalidConfigurationError("package", fmt.Sprintf("%q is not a valid package name", g.config.Package))
+	}
+	if g.config.Prefix != "" && !token.IsIdentifier(g.config.Prefix) {
+		return nil, NewInvalidConfigurationError("prefix", fmt.Sprintf("%q is not a valid identifier", g.config.Prefix))
+	}
+	fa, err := finiteAutomatonOf(automaton)
+	if err != nil {
+		return nil, err
+	}
+	if err := fa.Validate(); err != nil {
+		return nil, NewErrorWithCause(ErrorTypeValidation, "cannot generate code for an invalid automaton", err)
+	}
+
+	symbolType, literal, err := goSymbolType[S]()
+	if err != nil {
+		return nil, err
+	}
+
+	fa.mutex.RLock()
+	defer fa.mutex.RUnlock()
+
+	m := &goMachine[Q, S]{
+		fa:         fa,
+		states:     sortValues(fa.getStatesList()),
+		symbols:    sortValues(fa.getAlphabetList()),
+		constants:  make(map[Q]string),
+		literals:   make(map[S]string),
+		literal:    literal,
+		symbolType: symbolType,
+	}
+	names, err := encodeAll(g.states, m.states, "state")
+	if err != nil {
+		return nil, err
+	}
+	m.names = make(map[Q]string, len(m.states))
+	taken := make(map[string]bool, len(m.states))
+	for i, state := range m.states {
+		m.names[state] = names[i]
+		constant := g.ident("State" + goIdentifierSuffix(names[i]))
+		for n := 2; taken[constant]; n++ {
+			constant = g.ident(fmt.Sprintf("State%s_%d", goIdentifierSuffix(names[i]), n))
+		}
+		taken[constant] = true
+		m.constants[state] = constant
+	}
+	for _, symbol := range m.symbols {
+		m.literals[symbol] = m.literal(symbol)
+	}
+	return m, nil
+}
+
+// ident returns a generated identifier with the configured prefix.
+func (g *GoGenerator[Q, S]) ident(name string) string {
+	return g.config.Prefix + name
+}
+
+// goIdentifierSuffix turns a state name into the CamelCase tail of a Go
+// identifier, e.g. "in review" becomes "InReview" and "" becomes "_".
+func goIdentifierSuffix(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 {
+		return "_"
+	}
+	return sb.String()
+}
+
+// goSymbolType returns the Go type used for symbols in generated code and a
+// function writing a symbol as a Go literal of that type.
+func goSymbolType[S Symbol]() (string, func(S) string, error) {
+	typ := reflect.TypeFor[S]()
+	switch typ.Kind() {
+	case reflect.String:
+		return "string", func(s S) string { return strconv.Quote(reflect.ValueOf(s).String()) }, nil
+	case reflect.Int32:
+		return "rune", func(s S) string { return strconv.QuoteRune(rune(reflect.ValueOf(s).Int())) }, nil
+	case reflect.Uint8:
+		return "byte", func(s S) string { return strconv.FormatUint(reflect.ValueOf(s).Uint(), 10) }, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int64:
+		return typ.Kind().String(), func(s S) string { return strconv.FormatInt(reflect.ValueOf(s).Int(), 10) }, nil
+	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return typ.Kind().String(), func(s S) string { return strconv.FormatUint(reflect.ValueOf(s).Uint(), 10) }, nil
+	default:
+		return "", nil, NewInvalidConfigurationError("symbol",
+			fmt.Sprintf("cannot generate code for symbols of type %s", typ))
+	}
+}
+
+// Generate returns the gofmt-formatted source of the generated machine.
+func (g *GoGenerator[Q, S]) Generate(automaton Automaton[Q, S]) ([]byte, error) {
+	m, err := g.prepare(automaton)
+	if err != nil {
+		return nil, err
+	}
+	stateType := g.ident("State")
+
+	var b bytes.Buffer
+	g.header(&b)
+	b.WriteString("import (\n\t\"errors\"\n\t\"fmt\"\n)\n\n")
+
+	fmt.Fprintf(&b, "// %s is a state of the generated machine.\n", stateType)
+	fmt.Fprintf(&b, "type %s int\n\n", stateType)
+	b.WriteString("const (\n")
+	for i, state := range m.states {
+		if i == 0 {
+			fmt.Fprintf(&b, "\t%s %s = iota\n", m.constants[state], stateType)
+		} else {
+			fmt.Fprintf(&b, "\t%s\n", m.constants[state])
+		}
+	}
+	b.WriteString(")\n\n")
+	fmt.Fprintf(&b, "// %s is the state the machine starts in.\n", g.ident("InitialState"))
+	fmt.Fprintf(&b, "const %s = %s\n\n", g.ident("InitialState"), m.constants[m.fa.initialState])
+
+	fmt.Fprintf(&b, "// String returns the name of the state in the source automaton.\n")
+	fmt.Fprintf(&b, "func (s %s) String() string {\n\tswitch s {\n", stateType)
+	for _, state := range m.states {
+		fmt.Fprintf(&b, "\tcase %s:\n\t\treturn %s\n", m.constants[state], strconv.Quote(m.names[state]))
+	}
+	fmt.Fprintf(&b, "\t}\n\treturn fmt.Sprintf(\"%s(%%d)\", int(s))\n}\n\n", stateType)
+
+	b.WriteString("// IsAccepting reports whether the state is an accepting state.\n")
+	fmt.Fprintf(&b, "func (s %s) IsAccepting() bool {\n", stateType)
+	var accepting []string
+	for _, state := range m.states {
+		if m.fa.acceptingStates[state] {
+			accepting = append(accepting, m.constants[state])
+		}
+	}
+	if len(accepting) > 0 {
+		fmt.Fprintf(&b, "\tswitch s {\n\tcase %s:\n\t\treturn true\n\t}\n", strings.Join(accepting, ", "))
+	}
+	b.WriteString("\treturn false\n}\n\n")
+
+	inAlphabet := g.ident("InAlphabet")
+	fmt.Fprintf(&b, "// %s reports whether symbol is in the alphabet.\n", inAlphabet)
+	fmt.Fprintf(&b, "func %s(symbol %s) bool {\n", inAlphabet, m.symbolType)
+	if len(m.symbols) > 0 {
+		literals := make([]string, len(m.symbols))
+		for i, symbol := range m.symbols {
+			literals[i] = m.literals[symbol]
+		}
+		fmt.Fprintf(&b, "\tswitch symbol {\n\tcase %s:\n\t\treturn true\n\t}\n", strings.Join(literals, ", "))
+	}
+	b.WriteString("\treturn false\n}\n\n")
+
+	step := g.ident("Step")
+	fmt.Fprintf(&b, "// %s returns the state reached from state on symbol. It fails when the\n", step)
+	b.WriteString("// symbol is not in the alphabet or no transition is defined, returning the\n// given state.\n")
+	fmt.Fprintf(&b, "func %s(state %s, symbol %s) (%s, error) {\n\tswitch state {\n", step, stateType, m.symbolType, stateType)
+	for _, state := range m.states {
+		transitions := m.fa.transitions[state]
+		if len(transitions) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\tcase %s:\n\t\tswitch symbol {\n", m.constants[state])
+		for _, symbol := range m.symbols {
+			if target, exists := transitions[symbol]; exists {
+				fmt.Fprintf(&b, "\t\tcase %s:\n\t\t\treturn %s, nil\n", m.literals[symbol], m.constants[target])
+			}
+		}
+		b.WriteString("\t\t}\n")
+	}
+	b.WriteString("\t}\n")
+	fmt.Fprintf(&b, "\tif !%s(symbol) {\n", inAlphabet)
+	b.WriteString("\t\treturn state, fmt.Errorf(\"symbol not in alphabet: %v\", symbol)\n\t}\n")
+	b.WriteString("\treturn state, fmt.Errorf(\"no transition defined for state %v with symbol %v\", state, symbol)\n}\n\n")
+
+	processInput := g.ident("ProcessInput")
+	fmt.Fprintf(&b, "// %s runs the machine from its initial state and reports\n", processInput)
+	b.WriteString("// whether it ends in an accepting state. Every symbol is checked against the\n// alphabet first.\n")
+	fmt.Fprintf(&b, "func %s(input []%s) (bool, error) {\n", processInput, m.symbolType)
+	b.WriteString("\tif input == nil {\n\t\treturn false, errors.New(\"input sequence cannot be nil\")\n\t}\n")
+	fmt.Fprintf(&b, "\tfor i, symbol := range input {\n\t\tif !%s(symbol) {\n", inAlphabet)
+	b.WriteString("\t\t\treturn false, fmt.Errorf(\"symbol at position %d is not in alphabet\", i)\n\t\t}\n\t}\n")
+	fmt.Fprintf(&b, "\tstate := %s\n", g.ident("InitialState"))
+	fmt.Fprintf(&b, "\tfor _, symbol := range input {\n\t\tnext, err := %s(state, symbol)\n", step)
+	b.WriteString("\t\tif err != nil {\n\t\t\treturn false, err\n\t\t}\n\t\tstate = next\n\t}\n")
+	b.WriteString("\treturn state.IsAccepting(), nil\n}\n")
+
+	return g.format(b.Bytes())
+}
+
+// GenerateTest returns the source of a test for the generated machine. The
+// test rebuilds the source automaton with this package and runs both on random
+// inputs drawn with a fixed seed from the alphabet and, where one exists, a
+// symbol outside it. Acceptance, failure and every state of the trace must agree.
+func (g *GoGenerator[Q, S]) GenerateTest(automaton Automaton[Q, S]) ([]byte, error) {
+	m, err := g.prepare(automaton)
+	if err != nil {
+		return nil, err
+	}
+	reference := "new" + g.config.Prefix + "ReferenceAutomaton"
+	if g.config.Prefix == "" {
+		reference = "newReferenceAutomaton"
+	}
+	inputs, maxLength := g.config.TestInputs, g.config.TestMaxLength
+	if inputs <= 0 {
+		inputs = DefaultGoGeneratorConfig().TestInputs
+	}
+	if maxLength <= 0 {
+		maxLength = DefaultGoGeneratorConfig().TestMaxLength
+	}
+
+	symbols := make([]string, len(m.symbols))
+	for i, symbol := range m.symbols {
+		symbols[i] = m.literals[symbol]
+	}
+	if outsider, found := outsideSymbol(m.fa.alphabet); found {
+		symbols = append(symbols, m.literal(outsider))
+	}
+
+	var b bytes.Buffer
+	g.header(&b)
+	b.WriteString("import (\n")
+	if len(symbols) > 0 {
+		b.WriteString("\t\"math/rand\"\n")
+	}
+	fmt.Fprintf(&b, "\t\"testing\"\n\n\t\"%s\"\n)\n\n", ModulePath)
+
+	fmt.Fprintf(&b, "// %s rebuilds the automaton the machine was generated from\n", reference)
+	fmt.Fprintf(&b, "func %s() *fsm.FiniteAutomaton[string, %s] {\n", reference, m.symbolType)
+	fmt.Fprintf(&b, "\treturn fsm.New[string, %s](%s)", m.symbolType, strconv.Quote(m.names[m.fa.initialState]))
+	for _, state := range m.states {
+		fmt.Fprintf(&b, ".\n\t\tAddState(%s)", strconv.Quote(m.names[state]))
+	}
+	for _, symbol := range m.symbols {
+		fmt.Fprintf(&b, ".\n\t\tAddSymbol(%s)", m.literals[symbol])
+	}
+	for _, state := range m.states {
+		if m.fa.acceptingStates[state] {
+			fmt.Fprintf(&b, ".\n\t\tAddAcceptingState(%s)", strconv.Quote(m.names[state]))
+		}
+	}
+	for _, state := range m.states {
+		for _, symbol := range m.symbols {
+			if target, exists := m.fa.transitions[state][symbol]; exists {
+				fmt.Fprintf(&b, ".\n\t\tAddTransition(%s, %s, %s)",
+					strconv.Quote(m.names[state]), m.literals[symbol], strconv.Quote(m.names[target]))
+			}
+		}
+	}
+	b.WriteString("\n}\n\n")
+
+	name := "Test" + g.config.Prefix + "GeneratedMachine"
+	fmt.Fprintf(&b, "// %s tests the generated machine against the source automaton\n", name)
+	fmt.Fprintf(&b, "func %s(t *testing.T) {\n", name)
+	fmt.Fprintf(&b, "\tfa := %s()\n", reference)
+	fmt.Fprintf(&b, "\tsymbols := []%s{%s}\n", m.symbolType, strings.Join(symbols, ", "))
+	if len(symbols) > 0 {
+		b.WriteString("\trng := rand.New(rand.NewSource(1))\n")
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "\tfor i := 0; i < %d; i++ {\n", inputs)
+	fmt.Fprintf(&b, "\t\tinput := []%s{}\n", m.symbolType)
+	if len(symbols) > 0 {
+		fmt.Fprintf(&b, "\t\tfor n := rng.Intn(%d); n > 0; n-- {\n", maxLength+1)
+		b.WriteString("\t\t\tinput = append(input, symbols[rng.Intn(len(symbols))])\n\t\t}\n")
+	}
+	b.WriteString("\n")
+	b.WriteString("\t\twantTrace, wantAccepted, wantErr := fa.ProcessInputWithTrace(input)\n")
+	fmt.Fprintf(&b, "\t\taccepted, err := %s(input)\n", g.ident("ProcessInput"))
+	b.WriteString("\t\tif accepted != wantAccepted || (err != nil) != (wantErr != nil) {\n")
+	b.WriteString("\t\t\tt.Fatalf(\"ProcessInput(%v) = %t, %v; want %t, %v\", input, accepted, err, wantAccepted, wantErr)\n\t\t}\n")
+	b.WriteString("\t\tif wantErr != nil && wantTrace == nil {\n\t\t\tcontinue\n\t\t}\n\n")
+	fmt.Fprintf(&b, "\t\tstate := %s\n", g.ident("InitialState"))
+	b.WriteString("\t\tfor j, symbol := range input {\n")
+	fmt.Fprintf(&b, "\t\t\tnext, err := %s(state, symbol)\n", g.ident("Step"))
+	b.WriteString("\t\t\tif err != nil {\n\t\t\t\tif wantErr == nil || len(wantTrace) != j+1 {\n")
+	b.WriteString("\t\t\t\t\tt.Fatalf(\"Step failed at position %d of %v: %v\", j, input, err)\n\t\t\t\t}\n\t\t\t\tbreak\n\t\t\t}\n")
+	b.WriteString("\t\t\tif j+1 >= len(wantTrace) || next.String() != wantTrace[j+1] {\n")
+	b.WriteString("\t\t\t\tt.Fatalf(\"Step at position %d of %v reached %v, want trace %v\", j, input, next, wantTrace)\n\t\t\t}\n")
+	b.WriteString("\t\t\tstate = next\n\t\t}\n\t}\n}\n")
+
+	return g.format(b.Bytes())
+}
+
+// outsideSymbol finds a value of the symbol type that is not in the alphabet,
+// used to test rejection of unknown symbols.
+func outsideSymbol[S Symbol](alphabet map[S]bool) (S, bool) {
+	typ := reflect.TypeFor[S]()
+	for i := 0; i <= len(alphabet); i++ {
+		candidate := reflect.New(typ).Elem()
+		switch typ.Kind() {
+		case reflect.String:
+			candidate.SetString(strings.Repeat("?", i+1))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			candidate.SetInt(int64(i))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if candidate.OverflowUint(uint64(i)) {
+				continue
+			}
+			candidate.SetUint(uint64(i))
+		default:
+			var zero S
+			return zero, false
+		}
+		if symbol := candidate.Interface().(S); !alphabet[symbol] {
+			return symbol, true
+		}
+	}
+	var zero S
+	return zero, false
+}
+
+// header writes the generated-code marker and package clause.
+func (g *GoGenerator[Q, S]) header(b *bytes.Buffer) {
+	generator := g.config.Generator
+	if generator == "" {
+		generator = "fsmgen"
+	}
+	fmt.Fprintf(b, "// Code generated by %s. DO NOT EDIT.\n\npackage %s\n\n", generator, g.config.Package)
+}
+
+func (g *GoGenerator[Q, S]) format(source []byte) ([]byte, error) {
+	formatted, err := format.Source(source)
+	if err != nil {
+		return nil, NewErrorWithCause(ErrorTypeInternal, "generated code does not compile", err)
+	}
+	return formatted, nil
+}
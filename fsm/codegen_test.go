@@ -0,0 +1,132 @@
+package fsm
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestGoGenerator_Generate tests the shape of the generated code
+func TestGoGenerator_Generate(t *testing.T) {
+	config := DefaultGoGeneratorConfig()
+	config.Package = "parity"
+	config.Prefix = "Parity"
+
+	source, err := NewGoGenerator[string, rune](config).Generate(newParityAutomaton())
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	got := string(source)
+
+	for _, fragment := range []string{
+		"// Code generated by fsmgen. DO NOT EDIT.\n\npackage parity\n",
+		"type ParityState int\n",
+		"\tParityStateEven ParityState = iota\n\tParityStateOdd\n",
+		"const ParityInitialState = ParityStateEven\n",
+		"func ParityInAlphabet(symbol rune) bool {\n\tswitch symbol {\n\tcase '0', '1':\n",
+		"\tcase ParityStateEven:\n\t\tswitch symbol {\n\t\tcase '0':\n\t\t\treturn ParityStateEven, nil\n\t\tcase '1':\n\t\t\treturn ParityStateOdd, nil\n",
+		"func ParityProcessInput(input []rune) (bool, error) {\n",
+	} {
+		if !strings.Contains(got, fragment) {
+			t.Errorf("Generated code is missing %q:\n%s", fragment, got)
+		}
+	}
+	if strings.Contains(got, "map[") {
+		t.Errorf("Generated code should not use maps:\n%s", got)
+	}
+}
+
+// TestGoGenerator_GeneratedTestPasses compiles the generated code and runs its test
+func TestGoGenerator_GeneratedTestPasses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go test of generated code in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not available")
+	}
+
+	// The generated test imports this package, so the directory is a module
+	// replacing this one with the working tree.
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatalf("Abs returned error: %v", err)
+	}
+	dir := t.TempDir()
+	module := fmt.Sprintf("module generated\n\ngo 1.24\n\nrequire %s v0.0.0\n\nreplace %s => %s\n",
+		path.Dir(ModulePath), path.Dir(ModulePath), strconv.Quote(root))
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(module), 0o644); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+
+	turnstile := newTurnstileAutomaton().AddState("in service").AddTransition("broken", "inspect", "in service")
+	write := func(name string, generate func() ([]byte, error)) {
+		source, err := generate()
+		if err != nil {
+			t.Fatalf("generating %s returned error: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), source, 0o644); err != nil {
+			t.Fatalf("WriteFile returned error: %v", err)
+		}
+	}
+
+	config := DefaultGoGeneratorConfig()
+	config.Package = "generated"
+	config.Prefix = "Parity"
+	parity := NewGoGenerator[string, rune](config)
+	write("parity.go", func() ([]byte, error) { return parity.Generate(newParityAutomaton()) })
+	write("parity_test.go", func() ([]byte, error) { return parity.GenerateTest(newParityAutomaton()) })
+
+	config.Prefix = "Turnstile"
+	generator := NewGoGenerator[string, string](config)
+	write("turnstile.go", func() ([]byte, error) { return generator.Generate(turnstile) })
+	write("turnstile_test.go", func() ([]byte, error) { return generator.GenerateTest(turnstile) })
+
+	cmd := exec.Command(goTool, "test", "-count=1", ".")
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test of generated code failed: %v\n%s", err, output)
+	}
+}
+
+// TestGoGenerator_Errors tests rejected configurations and automata
+func TestGoGenerator_Errors(t *testing.T) {
+	config := DefaultGoGeneratorConfig()
+	config.Package = "not a package"
+	if _, err := NewGoGenerator[string, rune](config).Generate(newParityAutomaton()); err == nil {
+		t.Error("Expected error for an invalid package name")
+	}
+
+	invalid := newParityAutomaton().AddTransition("even", 'x', "missing")
+	if _, err := NewGoGenerator[string, rune](DefaultGoGeneratorConfig()).Generate(invalid); !IsValidationError(err) {
+		t.Errorf("Expected validation error for an invalid automaton, got %v", err)
+	}
+
+	type point struct{ x, y int }
+	fa := New[string, point]("a").AddState("a").AddSymbol(point{1, 2}).AddTransition("a", point{1, 2}, "a")
+	if _, err := NewGoGenerator[string, point](DefaultGoGeneratorConfig()).Generate(fa); err == nil {
+		t.Error("Expected error for an unsupported symbol type")
+	}
+}
+
+// TestGoIdentifierSuffix tests the naming of state constants
+func TestGoIdentifierSuffix(t *testing.T) {
+	tests := map[string]string{
+		"even":      "Even",
+		"in review": "InReview",
+		"q0":        "Q0",
+		"0":         "0",
+		"a-b_c":     "ABC",
+		"":          "_",
+	}
+	for name, want := range tests {
+		if got := goIdentifierSuffix(name); got != want {
+			t.Errorf("goIdentifierSuffix(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
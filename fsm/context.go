@@ -0,0 +1,139 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// InterruptedError is returned when processing stops because its context was
+// cancelled or its deadline passed. It unwraps to the context's error, so
+// errors.Is(err, context.Canceled) and errors.Is(err, context.DeadlineExceeded)
+// hold as expected.
+type InterruptedError[Q State] struct {
+	// Position is the number of symbols consumed before the interruption
+	Position int
+	// Trace lists the states visited, starting with the initial state; it is
+	// empty when processing had not started
+	Trace []Q
+	// Cause is the error of the context
+	Cause error
+}
+
+// Error implements the error interface.
+func (e *InterruptedError[Q]) Error() string {
+	return fmt.Sprintf("processing interrupted after %d symbols: %v", e.Position, e.Cause)
+}
+
+// Unwrap returns the error of the context.
+func (e *InterruptedError[Q]) Unwrap() error {
+	return e.Cause
+}
+
+// interruptedAt lets IsInterruptedError recognize InterruptedErrors of any
+// state type.
+func (e *InterruptedError[Q]) interruptedAt() int {
+	return e.Position
+}
+
+// IsInterruptedError checks if an error is an InterruptedError, whatever its
+// state type.
+func IsInterruptedError(err error) bool {
+	var interrupted interface{ interruptedAt() int }
+	return errors.As(err, &interrupted)
+}
+
+// stepContext resets the automaton and steps it through the input, checking
+// the context before every symbol. It returns the trace so far with either a
+// step error or an InterruptedError.
+func stepContext[Q State, S Symbol](ctx context.Context, automaton Automaton[Q, S], input []S) ([]Q, error) {
+	done := ctx.Done()
+	automaton.Reset()
+	trace := []Q{automaton.GetCurrentState()}
+
+	for i, symbol := range input {
+		select {
+		case <-done:
+			return trace, &InterruptedError[Q]{Position: i, Trace: trace, Cause: ctx.Err()}
+		default:
+		}
+		next, err := automaton.Step(symbol)
+		if err != nil {
+			return trace, err
+		}
+		trace = append(trace, next)
+	}
+	return trace, nil
+}
+
+// ProcessInputContext is ProcessInput stopping with an InterruptedError as
+// soon as ctx is done. The context is checked before every symbol.
+func (fa *FiniteAutomaton[Q, S]) ProcessInputContext(ctx context.Context, input []S) (bool, error) {
+	_, accepted, err := fa.ProcessInputWithTraceContext(ctx, input)
+	return accepted, err
+}
+
+// ProcessInputWithTraceContext is ProcessInputWithTrace stopping with an
+// InterruptedError as soon as ctx is done. The context is checked before
+// every symbol.
+func (fa *FiniteAutomaton[Q, S]) ProcessInputWithTraceContext(ctx context.Context, input []S) ([]Q, bool, error) {
+	if err := ValidateInputSequence(input, fa.alphabet); err != nil {
+		return nil, false, err
+	}
+	trace, err := stepContext[Q, S](ctx, fa, input)
+	if err != nil {
+		return trace, false, err
+	}
+	return trace, fa.IsCurrentStateAccepting(), nil
+}
+
+// ProcessInputContext is ProcessInput stopping with an InterruptedError as
+// soon as ctx is done. The context is checked before every symbol.
+func (n *NFA[Q, S]) ProcessInputContext(ctx context.Context, input []S) (bool, error) {
+	_, accepted, err := n.ProcessInputWithTraceContext(ctx, input)
+	return accepted, err
+}
+
+// ProcessInputWithTraceContext is ProcessInputWithTrace stopping with an
+// InterruptedError as soon as ctx is done. The context is checked before
+// every symbol.
+func (n *NFA[Q, S]) ProcessInputWithTraceContext(ctx context.Context, input []S) ([]StateSet[Q], bool, error) {
+	if err := ValidateInputSequence(input, n.alphabet); err != nil {
+		return nil, false, err
+	}
+	trace, err := stepContext[StateSet[Q], S](ctx, n, input)
+	if err != nil {
+		return trace, false, err
+	}
+	return trace, n.IsCurrentStateAccepting(), nil
+}
+
+// ProcessInputContext processes an input sequence with cancellation and
+// notifies observers.
+func (oa *ObservableAutomaton[Q, S]) ProcessInputContext(ctx context.Context, input []S) (bool, error) {
+	_, accepted, err := oa.ProcessInputWithTraceContext(ctx, input)
+	return accepted, err
+}
+
+// ProcessInputWithTraceContext processes input with cancellation and returns
+// the state trace along with observers notification. A wrapped automaton
+// without context support is stepped symbol by symbol.
+func (oa *ObservableAutomaton[Q, S]) ProcessInputWithTraceContext(ctx context.Context, input []S) ([]Q, bool, error) {
+	var trace []Q
+	var accepted bool
+	var err error
+	if wrapped, ok := oa.automaton.(ContextAutomaton[Q, S]); ok {
+		trace, accepted, err = wrapped.ProcessInputWithTraceContext(ctx, input)
+	} else {
+		trace, err = stepContext(ctx, oa.automaton, input)
+		accepted = err == nil && oa.automaton.IsCurrentStateAccepting()
+	}
+
+	if err != nil {
+		oa.notifyError(err)
+	} else {
+		oa.notifyInputProcessed(input, accepted)
+	}
+
+	return trace, accepted, err
+}
@@ -0,0 +1,164 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// cancellingAutomaton cancels a context once it has taken a number of steps
+type cancellingAutomaton struct {
+	*FiniteAutomaton[string, rune]
+	steps  int
+	cancel context.CancelFunc
+}
+
+func (a *cancellingAutomaton) Step(symbol rune) (string, error) {
+	a.steps--
+	if a.steps == 0 {
+		a.cancel()
+	}
+	return a.FiniteAutomaton.Step(symbol)
+}
+
+// plainProcessor hides the context support of the processor it embeds
+type plainProcessor struct {
+	Processor[string, rune]
+}
+
+// TestFiniteAutomaton_ProcessInputContext tests processing with live, cancelled and expired contexts
+func TestFiniteAutomaton_ProcessInputContext(t *testing.T) {
+	var _ ContextAutomaton[string, rune] = newParityAutomaton()
+	var _ ContextAutomaton[StateSet[string], rune] = newEndsWithABNFA()
+	var _ ContextAutomaton[string, rune] = NewObservableAutomaton[string, rune](newParityAutomaton())
+
+	fa := newParityAutomaton()
+	accepted, err := fa.ProcessInputContext(context.Background(), []rune("0110"))
+	if err != nil || !accepted {
+		t.Errorf("ProcessInputContext = %v, %v; want true, nil", accepted, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	trace, accepted, err := fa.ProcessInputWithTraceContext(ctx, []rune("0110"))
+	var interrupted *InterruptedError[string]
+	if !errors.As(err, &interrupted) || accepted {
+		t.Fatalf("Expected InterruptedError, got %v (accepted %v)", err, accepted)
+	}
+	if interrupted.Position != 0 || !reflect.DeepEqual(interrupted.Trace, []string{"even"}) || !reflect.DeepEqual(trace, interrupted.Trace) {
+		t.Errorf("Unexpected interruption at %d with trace %v", interrupted.Position, interrupted.Trace)
+	}
+	if !errors.Is(err, context.Canceled) || !IsInterruptedError(err) {
+		t.Errorf("Expected the error to unwrap to context.Canceled, got %v", err)
+	}
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	if _, err := fa.ProcessInputContext(expired, []rune("1")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// Invalid input is still reported before the context is checked.
+	if _, err := fa.ProcessInputContext(ctx, []rune("2")); !IsInvalidInputError(err) {
+		t.Errorf("Expected invalid input error, got %v", err)
+	}
+}
+
+// TestNFA_ProcessInputContext tests that NFA processing reports the partial trace of state sets
+func TestNFA_ProcessInputContext(t *testing.T) {
+	nfa := newEndsWithABNFA()
+	accepted, err := nfa.ProcessInputContext(context.Background(), []rune("aab"))
+	if err != nil || !accepted {
+		t.Errorf("ProcessInputContext = %v, %v; want true, nil", accepted, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = nfa.ProcessInputWithTraceContext(ctx, []rune("ab"))
+	var interrupted *InterruptedError[StateSet[string]]
+	if !errors.As(err, &interrupted) || len(interrupted.Trace) != 1 {
+		t.Errorf("Expected InterruptedError with the initial state set, got %v", err)
+	}
+}
+
+// TestObservableAutomaton_ProcessInputContext tests that observers are notified of interruptions
+func TestObservableAutomaton_ProcessInputContext(t *testing.T) {
+	debug := NewDebugObserver[string, rune]()
+	observable := NewObservableAutomaton[string, rune](newParityAutomaton())
+	observable.AddObserver(debug)
+
+	if accepted, err := observable.ProcessInputContext(context.Background(), []rune("11")); err != nil || !accepted {
+		t.Errorf("ProcessInputContext = %v, %v; want true, nil", accepted, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := observable.ProcessInputContext(ctx, []rune("11")); !IsInterruptedError(err) {
+		t.Errorf("Expected InterruptedError, got %v", err)
+	}
+	if len(debug.GetInputs()) != 1 || len(debug.GetErrors()) != 1 {
+		t.Errorf("Expected one processed input and one error, got %d and %d", len(debug.GetInputs()), len(debug.GetErrors()))
+	}
+}
+
+// TestStandardProcessor_ProcessContext tests interruption between symbols
+func TestStandardProcessor_ProcessContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	automaton := &cancellingAutomaton{FiniteAutomaton: newParityAutomaton(), steps: 2, cancel: cancel}
+
+	result, err := NewStandardProcessor[string, rune]().ProcessContext(ctx, automaton, []rune("1101"))
+	var interrupted *InterruptedError[string]
+	if !errors.As(err, &interrupted) {
+		t.Fatalf("Expected InterruptedError, got %v", err)
+	}
+	want := []string{"even", "odd", "even"}
+	if interrupted.Position != 2 || !reflect.DeepEqual(interrupted.Trace, want) {
+		t.Errorf("Interrupted at %d with trace %v, want 2 and %v", interrupted.Position, interrupted.Trace, want)
+	}
+	if result.Accepted || result.FinalState != "even" || !reflect.DeepEqual(result.Trace, want) {
+		t.Errorf("Unexpected partial result %+v", result)
+	}
+}
+
+// TestProcessors_ProcessContext tests that wrapping processors pass the context on
+func TestProcessors_ProcessContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	standard := NewStandardProcessor[string, rune]()
+
+	processors := map[string]ContextProcessor[string, rune]{
+		"chain":      NewProcessorChain[string, rune](standard),
+		"tracing":    NewTracingProcessor[string, rune](standard, nil),
+		"validating": NewValidatingProcessor[string, rune](standard, nil),
+		"parallel":   NewParallelProcessor[string, rune](2),
+		"optimized":  NewOptimizedProcessor[string, rune](),
+		// A processor without context support is only checked before starting.
+		"chain of plain": NewProcessorChain[string, rune](plainProcessor{standard}),
+	}
+	for name, processor := range processors {
+		if _, err := processor.ProcessContext(ctx, newParityAutomaton(), []rune("01")); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", name, err)
+		}
+		if result, err := processor.ProcessContext(context.Background(), newParityAutomaton(), []rune("11")); err != nil || !result.Accepted {
+			t.Errorf("%s: ProcessContext = %+v, %v; want accepted", name, result, err)
+		}
+	}
+}
+
+// TestParallelProcessor_ProcessBatchContext tests that a cancelled batch skips its inputs
+func TestParallelProcessor_ProcessBatchContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	inputs := [][]rune{[]rune("0"), []rune("1"), []rune("11")}
+	results, err := NewParallelProcessor[string, rune](2).ProcessBatchContext(ctx, newParityAutomaton(), inputs)
+	if len(results) != len(inputs) || !IsInterruptedError(err) {
+		t.Fatalf("Expected interrupted results for every input, got %d results and %v", len(results), err)
+	}
+	var collector *ErrorCollector
+	if !errors.As(err, &collector) || len(collector.Errors()) != len(inputs) {
+		t.Errorf("Expected one error per input, got %v", err)
+	}
+}
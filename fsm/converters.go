@@ -0,0 +1,125 @@
+package fsm
+
+import (
+	"fmt"
+)
+
+// SubsetConverter determinizes an NFA (with or without ε-transitions) into a
+// FiniteAutomaton using the powerset (subset) construction.
+//
+// Each state of the resulting automaton corresponds to a set of NFA states.
+// Only subsets reachable from the ε-closure of the initial state are
+// materialized, and the empty subset is never created: inputs that would
+// reach it fail with an undefined transition, just as they do on the NFA.
+//
+// Type parameters:
+//   - Q: The state type of the source NFA
+//   - R: The state type of the resulting FiniteAutomaton
+//   - S: The symbol type shared by both automata
+type SubsetConverter[Q State, R State, S Symbol] struct {
+	// newNamer returns the naming function for a single conversion
+	newNamer func() func(StateSet[Q]) R
+}
+
+// NewSubsetConverter creates a converter whose resulting states are the
+// StateSet values themselves.
+func NewSubsetConverter[Q State, S Symbol]() *SubsetConverter[Q, StateSet[Q], S] {
+	return &SubsetConverter[Q, StateSet[Q], S]{
+		newNamer: func() func(StateSet[Q]) StateSet[Q] {
+			return func(set StateSet[Q]) StateSet[Q] { return set }
+		},
+	}
+}
+
+// NewNamedSubsetConverter creates a converter that names each materialized subset
+// with the given function. The function is called exactly once per subset, in
+// breadth-first discovery order starting with the initial subset, and must
+// return a distinct name for every subset.
+func NewNamedSubsetConverter[Q State, R State, S Symbol](name func(StateSet[Q]) R) *SubsetConverter[Q, R, S] {
+	return &SubsetConverter[Q, R, S]{
+		newNamer: func() func(StateSet[Q]) R { return name },
+	}
+}
+
+// NewNumberedSubsetConverter creates a converter that numbers subsets 0, 1, 2, ...
+// in breadth-first discovery order, so the initial state is always 0.
+func NewNumberedSubsetConverter[Q State, S Symbol]() *SubsetConverter[Q, int, S] {
+	return &SubsetConverter[Q, int, S]{
+		newNamer: func() func(StateSet[Q]) int {
+			next := 0
+			return func(StateSet[Q]) int {
+				next++
+				return next - 1
+			}
+		},
+	}
+}
+
+// Convert determinizes the source automaton (implements AutomatonConverter interface).
+// The source must be an *NFA.
+func (c *SubsetConverter[Q, R, S]) Convert(source Automaton[StateSet[Q], S]) (Automaton[R, S], error) {
+	nfa, ok := source.(*NFA[Q, S])
+	if !ok {
+		return nil, NewInvalidConfigurationError("converter",
+			fmt.Sprintf("subset construction requires an *NFA source, got %T", source))
+	}
+	return c.Determinize(nfa)
+}
+
+// Determinize builds a FiniteAutomaton that accepts the same language as the NFA.
+func (c *SubsetConverter[Q, R, S]) Determinize(nfa *NFA[Q, S]) (*FiniteAutomaton[R, S], error) {
+	if err := nfa.Validate(); err != nil {
+		return nil, NewErrorWithCause(ErrorTypeValidation, "cannot determinize invalid NFA", err)
+	}
+
+	nfa.mutex.RLock()
+	defer nfa.mutex.RUnlock()
+
+	name := c.newNamer()
+	symbols := sortValues(mapKeys(nfa.alphabet))
+	start := nfa.initialStateSet()
+
+	names := map[StateSet[Q]]R{start: name(start)}
+	subsets := map[R]StateSet[Q]{names[start]: start}
+
+	dfa := New[R, S](names[start])
+	dfa.AddSymbols(symbols...)
+	dfa.AddState(names[start])
+
+	queue := []StateSet[Q]{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if nfa.containsAccepting(current) {
+			dfa.AddAcceptingState(names[current])
+		}
+
+		for _, symbol := range symbols {
+			next := nfa.move(current, symbol)
+			if next.IsEmpty() {
+				continue
+			}
+			if _, seen := names[next]; !seen {
+				nextName := name(next)
+				if other, taken := subsets[nextName]; taken {
+					return nil, NewInvalidConfigurationError("converter", fmt.Sprintf(
+						"subsets %v and %v were both named %v", other, next, nextName))
+				}
+				names[next] = nextName
+				subsets[nextName] = next
+				dfa.AddState(nextName)
+				queue = append(queue, next)
+			}
+			dfa.AddTransition(names[current], symbol, names[next])
+		}
+	}
+
+	return dfa, nil
+}
+
+// Determinize converts an NFA into an equivalent FiniteAutomaton whose states are
+// the reachable subsets of NFA states.
+func Determinize[Q State, S Symbol](nfa *NFA[Q, S]) (*FiniteAutomaton[StateSet[Q], S], error) {
+	return NewSubsetConverter[Q, S]().Determinize(nfa)
+}
@@ -0,0 +1,124 @@
+package fsm
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestDeterminize_EndsWithAB tests the powerset construction on a classic NFA
+func TestDeterminize_EndsWithAB(t *testing.T) {
+	nfa := newEndsWithABNFA()
+
+	dfa, err := Determinize(nfa)
+	if err != nil {
+		t.Fatalf("Determinize returned error: %v", err)
+	}
+
+	// Reachable subsets: {q0}, {q0, q1}, {q0, q2}
+	if len(dfa.states) != 3 {
+		t.Errorf("DFA has %d states, want 3: %v", len(dfa.states), dfa.getStatesList())
+	}
+	if dfa.GetInitialState() != nfa.GetInitialState() {
+		t.Errorf("DFA initial state = %v, want %v", dfa.GetInitialState(), nfa.GetInitialState())
+	}
+
+	for _, input := range []string{"", "a", "b", "ab", "ba", "aab", "abab", "abb", "bbab"} {
+		want, err := nfa.ProcessInput([]rune(input))
+		if err != nil {
+			t.Fatalf("NFA ProcessInput(%q) returned error: %v", input, err)
+		}
+		got, err := dfa.ProcessInput([]rune(input))
+		if err != nil {
+			t.Fatalf("DFA ProcessInput(%q) returned error: %v", input, err)
+		}
+		if got != want {
+			t.Errorf("DFA ProcessInput(%q) = %v, NFA = %v", input, got, want)
+		}
+	}
+}
+
+// TestDeterminize_Epsilon tests that ε-closures are folded into the subsets
+func TestDeterminize_Epsilon(t *testing.T) {
+	nfa := NewNFABuilder[string, rune]("q0").
+		WithStates("q0", "q1", "q2").
+		WithAlphabet('a', 'b').
+		WithAcceptingStates("q2").
+		WithTransition("q0", 'a', "q0").
+		WithTransition("q1", 'b', "q2").
+		WithEpsilon("q0", "q1").
+		MustBuild()
+
+	dfa, err := NewNumberedSubsetConverter[string, rune]().Determinize(nfa)
+	if err != nil {
+		t.Fatalf("Determinize returned error: %v", err)
+	}
+
+	if dfa.GetInitialState() != 0 {
+		t.Errorf("Numbered initial state = %v, want 0", dfa.GetInitialState())
+	}
+
+	tests := map[string]bool{"b": true, "aab": true, "a": false, "": false}
+	for input, want := range tests {
+		got, err := dfa.ProcessInput([]rune(input))
+		if err != nil {
+			t.Fatalf("ProcessInput(%q) returned error: %v", input, err)
+		}
+		if got != want {
+			t.Errorf("ProcessInput(%q) = %v, want %v", input, got, want)
+		}
+	}
+
+	// The empty subset is not materialized
+	if _, err := dfa.ProcessInput([]rune("ba")); err == nil {
+		t.Error("Input leading to the empty subset should fail with undefined transition")
+	}
+}
+
+// TestSubsetConverter_Named tests caller-supplied names and the converter interface
+func TestSubsetConverter_Named(t *testing.T) {
+	var calls int
+	converter := NewNamedSubsetConverter[string, string, rune](func(set StateSet[string]) string {
+		calls++
+		return set.String()
+	})
+
+	var generic AutomatonConverter[StateSet[string], string, rune, rune] = converter
+	result, err := generic.Convert(newEndsWithABNFA())
+	if err != nil {
+		t.Fatalf("Convert returned error: %v", err)
+	}
+
+	if result.GetInitialState() != "{q0}" {
+		t.Errorf("Initial state = %q, want {q0}", result.GetInitialState())
+	}
+	if calls != 3 {
+		t.Errorf("Naming function called %d times, want once per subset (3)", calls)
+	}
+
+	collide := NewNamedSubsetConverter[string, string, rune](func(StateSet[string]) string { return "same" })
+	if _, err := collide.Determinize(newEndsWithABNFA()); err == nil {
+		t.Error("Determinize should fail when two subsets get the same name")
+	}
+
+	if _, err := generic.Convert(NewObservableAutomaton[StateSet[string], rune](newEndsWithABNFA())); err == nil {
+		t.Error("Convert should reject sources that are not *NFA")
+	}
+}
+
+// BenchmarkDeterminize measures subset construction on an NFA with an exponential DFA
+func BenchmarkDeterminize(b *testing.B) {
+	// The n-th symbol from the end is 'a'
+	const n = 8
+	builder := NewNFABuilder[string, rune]("s0").WithAlphabet('a', 'b')
+	builder.WithTransition("s0", 'a', "s0").WithTransition("s0", 'b', "s0").WithTransition("s0", 'a', "s1")
+	for i := 1; i < n; i++ {
+		from, to := fmt.Sprintf("s%d", i), fmt.Sprintf("s%d", i+1)
+		builder.WithStates(from, to).WithTransition(from, 'a', to).WithTransition(from, 'b', to)
+	}
+	nfa := builder.WithStates("s0").WithAcceptingStates(fmt.Sprintf("s%d", n)).MustBuild()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Determinize(nfa)
+	}
+}
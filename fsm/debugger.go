@@ -0,0 +1,267 @@
+package fsm
+
+import (
+	"fmt"
+	"io"
+)
+
+// BreakpointKind selects what a Breakpoint matches.
+type BreakpointKind int
+
+const (
+	// StateBreakpoint stops after a step entering the breakpoint's state
+	StateBreakpoint BreakpointKind = iota
+	// TransitionBreakpoint stops after a step taken from the breakpoint's
+	// state on its symbol
+	TransitionBreakpoint
+)
+
+// String returns the string representation of the breakpoint kind.
+func (k BreakpointKind) String() string {
+	switch k {
+	case StateBreakpoint:
+		return "state"
+	case TransitionBreakpoint:
+		return "transition"
+	default:
+		return "unknown"
+	}
+}
+
+// Breakpoint stops Debugger.Continue when a step matches it.
+type Breakpoint[Q State, S Symbol] struct {
+	ID     int
+	Kind   BreakpointKind
+	State  Q
+	Symbol S // only used by transition breakpoints
+}
+
+// String describes the breakpoint.
+func (b Breakpoint[Q, S]) String() string {
+	if b.Kind == TransitionBreakpoint {
+		return fmt.Sprintf("#%d transition from %s on %s", b.ID, defaultLabel(b.State), defaultLabel(b.Symbol))
+	}
+	return fmt.Sprintf("#%d state %s", b.ID, defaultLabel(b.State))
+}
+
+// matches reports whether the step from --symbol--> to triggers the breakpoint.
+func (b Breakpoint[Q, S]) matches(from Q, symbol S, to Q) bool {
+	if b.Kind == TransitionBreakpoint {
+		return b.State == from && b.Symbol == symbol
+	}
+	return b.State == to
+}
+
+// DebugStep describes one symbol consumed by a Debugger.
+type DebugStep[Q State, S Symbol] struct {
+	// Position is the index of the symbol among the consumed symbols
+	Position int
+	From     Q
+	Symbol   S
+	To       Q
+	// Hit lists the breakpoints matched by the step
+	Hit []Breakpoint[Q, S]
+}
+
+// Debugger feeds input to an automaton one symbol at a time using Step and
+// Reset. Symbols are queued with Feed and consumed by Step or Continue;
+// Continue stops at the first step matching a breakpoint. Back undoes steps
+// by resetting the automaton and replaying the symbols consumed before.
+//
+// The debugger owns the automaton's current state: stepping the automaton
+// directly while debugging it leaves the debugger out of sync. A Debugger is
+// not safe for concurrent use.
+type Debugger[Q State, S Symbol] struct {
+	automaton   Automaton[Q, S]
+	trace       []Q
+	consumed    []S
+	pending     []S
+	breakpoints []Breakpoint[Q, S]
+	nextID      int
+}
+
+// NewDebugger creates a debugger for the automaton, resetting it and queuing
+// the input, if any.
+func NewDebugger[Q State, S Symbol](automaton Automaton[Q, S], input ...S) *Debugger[Q, S] {
+	automaton.Reset()
+	return &Debugger[Q, S]{
+		automaton: automaton,
+		trace:     []Q{automaton.GetCurrentState()},
+		pending:   append([]S{}, input...),
+		nextID:    1,
+	}
+}
+
+// Feed queues symbols after the pending input.
+func (d *Debugger[Q, S]) Feed(symbols ...S) {
+	d.pending = append(d.pending, symbols...)
+}
+
+// Step consumes the next pending symbol. If the automaton rejects it, the
+// debugger stays in the current state with the symbol still pending.
+func (d *Debugger[Q, S]) Step() (DebugStep[Q, S], error) {
+	if len(d.pending) == 0 {
+		return DebugStep[Q, S]{}, NewError(ErrorTypeInvalidInput, "no pending input")
+	}
+	from := d.State()
+	symbol := d.pending[0]
+	to, err := d.automaton.Step(symbol)
+	if err != nil {
+		return DebugStep[Q, S]{}, err
+	}
+
+	step := DebugStep[Q, S]{Position: len(d.consumed), From: from, Symbol: symbol, To: to}
+	for _, breakpoint := range d.breakpoints {
+		if breakpoint.matches(from, symbol, to) {
+			step.Hit = append(step.Hit, breakpoint)
+		}
+	}
+	d.pending = d.pending[1:]
+	d.consumed = append(d.consumed, symbol)
+	d.trace = append(d.trace, to)
+	return step, nil
+}
+
+// Continue steps through the pending input until a step hits a breakpoint,
+// the input runs out or a step fails. It returns the steps taken; the last
+// one holds the breakpoints that stopped it.
+func (d *Debugger[Q, S]) Continue() ([]DebugStep[Q, S], error) {
+	var steps []DebugStep[Q, S]
+	for len(d.pending) > 0 {
+		step, err := d.Step()
+		if err != nil {
+			return steps, err
+		}
+		steps = append(steps, step)
+		if len(step.Hit) > 0 {
+			break
+		}
+	}
+	return steps, nil
+}
+
+// Back undoes the last n steps, returning their symbols to the front of the
+// pending input.
+func (d *Debugger[Q, S]) Back(n int) error {
+	if n < 0 || n > len(d.consumed) {
+		return NewInvalidConfigurationError("debugger",
+			fmt.Sprintf("cannot step back %d of %d steps", n, len(d.consumed)))
+	}
+	keep := len(d.consumed) - n
+	undone := d.consumed[keep:]
+	d.pending = append(append([]S{}, undone...), d.pending...)
+	return d.replay(d.consumed[:keep])
+}
+
+// Restart returns every consumed symbol to the pending input and resets the
+// automaton. Breakpoints are kept.
+func (d *Debugger[Q, S]) Restart() error {
+	return d.Back(len(d.consumed))
+}
+
+// replay resets the automaton and steps it through the symbols.
+func (d *Debugger[Q, S]) replay(symbols []S) error {
+	symbols = append([]S{}, symbols...)
+	d.automaton.Reset()
+	d.trace = []Q{d.automaton.GetCurrentState()}
+	d.consumed = nil
+	for _, symbol := range symbols {
+		to, err := d.automaton.Step(symbol)
+		if err != nil {
+			return NewErrorWithCause(ErrorTypeInternal, "replaying the consumed input failed", err)
+		}
+		d.consumed = append(d.consumed, symbol)
+		d.trace = append(d.trace, to)
+	}
+	return nil
+}
+
+// AddStateBreakpoint adds a breakpoint hit by every step entering state and
+// returns its ID.
+func (d *Debugger[Q, S]) AddStateBreakpoint(state Q) int {
+	return d.addBreakpoint(Breakpoint[Q, S]{Kind: StateBreakpoint, State: state})
+}
+
+// AddTransitionBreakpoint adds a breakpoint hit by every step from state on
+// symbol and returns its ID.
+func (d *Debugger[Q, S]) AddTransitionBreakpoint(state Q, symbol S) int {
+	return d.addBreakpoint(Breakpoint[Q, S]{Kind: TransitionBreakpoint, State: state, Symbol: symbol})
+}
+
+func (d *Debugger[Q, S]) addBreakpoint(breakpoint Breakpoint[Q, S]) int {
+	breakpoint.ID = d.nextID
+	d.nextID++
+	d.breakpoints = append(d.breakpoints, breakpoint)
+	return breakpoint.ID
+}
+
+// RemoveBreakpoint removes the breakpoint with the given ID, reporting
+// whether it existed.
+func (d *Debugger[Q, S]) RemoveBreakpoint(id int) bool {
+	for i, breakpoint := range d.breakpoints {
+		if breakpoint.ID == id {
+			d.breakpoints = append(d.breakpoints[:i], d.breakpoints[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Breakpoints returns the breakpoints in the order they were added.
+func (d *Debugger[Q, S]) Breakpoints() []Breakpoint[Q, S] {
+	return append([]Breakpoint[Q, S]{}, d.breakpoints...)
+}
+
+// State returns the current state.
+func (d *Debugger[Q, S]) State() Q {
+	return d.trace[len(d.trace)-1]
+}
+
+// Accepting reports whether the current state is accepting.
+func (d *Debugger[Q, S]) Accepting() bool {
+	return d.automaton.IsAcceptingState(d.State())
+}
+
+// Position returns the number of symbols consumed.
+func (d *Debugger[Q, S]) Position() int {
+	return len(d.consumed)
+}
+
+// Trace returns the states visited so far, starting with the initial state.
+func (d *Debugger[Q, S]) Trace() []Q {
+	return append([]Q{}, d.trace...)
+}
+
+// Consumed returns the symbols consumed so far.
+func (d *Debugger[Q, S]) Consumed() []S {
+	return append([]S{}, d.consumed...)
+}
+
+// Pending returns the symbols queued but not consumed yet.
+func (d *Debugger[Q, S]) Pending() []S {
+	return append([]S{}, d.pending...)
+}
+
+// DumpTrace writes the trace so far, one step per line, marking accepting
+// states with '*':
+//
+//	0: even*
+//	1: even* --1--> odd
+func (d *Debugger[Q, S]) DumpTrace(w io.Writer) error {
+	mark := func(state Q) string {
+		if d.automaton.IsAcceptingState(state) {
+			return defaultLabel(state) + "*"
+		}
+		return defaultLabel(state)
+	}
+	if _, err := fmt.Fprintf(w, "0: %s\n", mark(d.trace[0])); err != nil {
+		return err
+	}
+	for i, symbol := range d.consumed {
+		if _, err := fmt.Fprintf(w, "%d: %s --%s--> %s\n",
+			i+1, mark(d.trace[i]), defaultLabel(symbol), mark(d.trace[i+1])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
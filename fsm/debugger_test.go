@@ -0,0 +1,147 @@
+package fsm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDebugger_Step tests stepping through queued input and inspecting the state
+func TestDebugger_Step(t *testing.T) {
+	d := NewDebugger[string, rune](newParityAutomaton(), []rune("10")...)
+
+	if d.State() != "even" || !d.Accepting() || d.Position() != 0 {
+		t.Fatalf("Unexpected initial state %s (accepting %v, position %d)", d.State(), d.Accepting(), d.Position())
+	}
+
+	step, err := d.Step()
+	if err != nil {
+		t.Fatalf("Step returned error: %v", err)
+	}
+	want := DebugStep[string, rune]{Position: 0, From: "even", Symbol: '1', To: "odd"}
+	if !reflect.DeepEqual(step, want) {
+		t.Errorf("Step() = %+v, want %+v", step, want)
+	}
+	if d.State() != "odd" || d.Accepting() {
+		t.Errorf("Expected non-accepting state odd, got %s", d.State())
+	}
+
+	d.Feed('1')
+	if got := string(d.Pending()); got != "01" {
+		t.Errorf("Pending() = %q, want %q", got, "01")
+	}
+	if _, err := d.Step(); err != nil {
+		t.Fatalf("Step returned error: %v", err)
+	}
+	if _, err := d.Step(); err != nil {
+		t.Fatalf("Step returned error: %v", err)
+	}
+	if !reflect.DeepEqual(d.Trace(), []string{"even", "odd", "odd", "even"}) {
+		t.Errorf("Unexpected trace %v", d.Trace())
+	}
+	if string(d.Consumed()) != "101" || d.Position() != 3 {
+		t.Errorf("Unexpected consumed input %q", string(d.Consumed()))
+	}
+
+	if _, err := d.Step(); err == nil {
+		t.Error("Expected error stepping without pending input")
+	}
+}
+
+// TestDebugger_StepError tests that a rejected symbol leaves the debugger unchanged
+func TestDebugger_StepError(t *testing.T) {
+	d := NewDebugger[string, rune](newParityAutomaton(), 'x', '1')
+
+	if _, err := d.Step(); err == nil {
+		t.Fatal("Expected error for a symbol outside the alphabet")
+	}
+	if d.State() != "even" || d.Position() != 0 || string(d.Pending()) != "x1" {
+		t.Errorf("Debugger changed after a failed step: state %s, pending %q", d.State(), string(d.Pending()))
+	}
+}
+
+// TestDebugger_Breakpoints tests that Continue stops at state and transition breakpoints
+func TestDebugger_Breakpoints(t *testing.T) {
+	d := NewDebugger[string, rune](newParityAutomaton(), []rune("0011010")...)
+	oddID := d.AddStateBreakpoint("odd")
+	transitionID := d.AddTransitionBreakpoint("odd", '0')
+
+	steps, err := d.Continue()
+	if err != nil {
+		t.Fatalf("Continue returned error: %v", err)
+	}
+	if len(steps) != 3 || d.State() != "odd" {
+		t.Fatalf("Expected to stop entering odd after 3 steps, took %d to %s", len(steps), d.State())
+	}
+	last := steps[len(steps)-1]
+	if len(last.Hit) != 1 || last.Hit[0].ID != oddID {
+		t.Errorf("Expected the state breakpoint to be hit, got %v", last.Hit)
+	}
+
+	if !d.RemoveBreakpoint(oddID) || d.RemoveBreakpoint(oddID) {
+		t.Error("RemoveBreakpoint should remove an existing breakpoint exactly once")
+	}
+
+	// odd --1--> even, even --0--> even, even --1--> odd, odd --0--> odd
+	steps, err = d.Continue()
+	if err != nil {
+		t.Fatalf("Continue returned error: %v", err)
+	}
+	if len(steps) != 4 || d.Position() != 7 {
+		t.Fatalf("Expected 4 steps to position 7, took %d to %d", len(steps), d.Position())
+	}
+	last = steps[len(steps)-1]
+	if len(last.Hit) != 1 || last.Hit[0].ID != transitionID || last.Hit[0].Kind != TransitionBreakpoint {
+		t.Errorf("Expected the transition breakpoint to be hit, got %v", last.Hit)
+	}
+
+	if steps, err := d.Continue(); err != nil || len(steps) != 0 {
+		t.Errorf("Continue without pending input = %v, %v", steps, err)
+	}
+	if got := d.Breakpoints(); len(got) != 1 || got[0].String() != "#2 transition from odd on 0" {
+		t.Errorf("Unexpected breakpoints %v", got)
+	}
+}
+
+// TestDebugger_Back tests stepping back and restarting
+func TestDebugger_Back(t *testing.T) {
+	d := NewDebugger[string, rune](newParityAutomaton(), []rune("110")...)
+	if _, err := d.Continue(); err != nil {
+		t.Fatalf("Continue returned error: %v", err)
+	}
+
+	if err := d.Back(2); err != nil {
+		t.Fatalf("Back returned error: %v", err)
+	}
+	if d.State() != "odd" || string(d.Pending()) != "10" || !reflect.DeepEqual(d.Trace(), []string{"even", "odd"}) {
+		t.Errorf("Unexpected state after Back: %s, pending %q, trace %v", d.State(), string(d.Pending()), d.Trace())
+	}
+
+	if err := d.Back(2); err == nil {
+		t.Error("Expected error stepping back past the start")
+	}
+
+	if err := d.Restart(); err != nil {
+		t.Fatalf("Restart returned error: %v", err)
+	}
+	if d.State() != "even" || string(d.Pending()) != "110" || d.Position() != 0 {
+		t.Errorf("Unexpected state after Restart: %s, pending %q", d.State(), string(d.Pending()))
+	}
+}
+
+// TestDebugger_DumpTrace tests the trace listing
+func TestDebugger_DumpTrace(t *testing.T) {
+	d := NewDebugger[string, rune](newParityAutomaton(), '1', '1')
+	if _, err := d.Continue(); err != nil {
+		t.Fatalf("Continue returned error: %v", err)
+	}
+
+	var b strings.Builder
+	if err := d.DumpTrace(&b); err != nil {
+		t.Fatalf("DumpTrace returned error: %v", err)
+	}
+	want := "0: even*\n1: even* --1--> odd\n2: odd --1--> even*\n"
+	if b.String() != want {
+		t.Errorf("DumpTrace() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
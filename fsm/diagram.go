@@ -0,0 +1,197 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diagram is the drawable form of a FiniteAutomaton shared by the diagram
+// exporters: states in sorted order, with transitions between the same pair
+// of states merged into a single edge.
+type diagram[Q State, S Symbol] struct {
+	states    []Q
+	initial   Q
+	accepting map[Q]bool
+	edges     []diagramEdge[Q, S]
+}
+
+// diagramEdge is a merged edge; symbols are sorted.
+type diagramEdge[Q State, S Symbol] struct {
+	from, to Q
+	symbols  []S
+}
+
+// newDiagram collects the states and merged edges of an automaton. States
+// referenced only by transitions are drawn as well. Edges are ordered by
+// source state, then by target state.
+func newDiagram[Q State, S Symbol](automaton Automaton[Q, S]) (*diagram[Q, S], error) {
+	fa, err := finiteAutomatonOf(automaton)
+	if err != nil {
+		return nil, err
+	}
+
+	fa.mutex.RLock()
+	defer fa.mutex.RUnlock()
+
+	present := make(map[Q]bool, len(fa.states)+1)
+	for state := range fa.states {
+		present[state] = true
+	}
+	present[fa.initialState] = true
+	for from, transitions := range fa.transitions {
+		present[from] = true
+		for _, to := range transitions {
+			present[to] = true
+		}
+	}
+
+	d := &diagram[Q, S]{
+		states:    sortValues(mapKeys(present)),
+		initial:   fa.initialState,
+		accepting: make(map[Q]bool, len(fa.acceptingStates)),
+	}
+	for state := range fa.acceptingStates {
+		d.accepting[state] = true
+	}
+
+	symbols := sortValues(fa.getAlphabetList())
+	for _, from := range d.states {
+		transitions := fa.transitions[from]
+		if len(transitions) == 0 {
+			continue
+		}
+		merged := make(map[Q][]S)
+		for _, symbol := range symbols {
+			if to, exists := transitions[symbol]; exists {
+				merged[to] = append(merged[to], symbol)
+			}
+		}
+		for _, to := range sortValues(mapKeys(merged)) {
+			d.edges = append(d.edges, diagramEdge[Q, S]{from: from, to: to, symbols: merged[to]})
+		}
+	}
+	return d, nil
+}
+
+// stateNames labels every state, failing if two states share a label since
+// the exporters identify states by name.
+func (d *diagram[Q, S]) stateNames(label func(Q) string) (map[Q]string, error) {
+	names := make(map[Q]string, len(d.states))
+	owners := make(map[string]Q, len(d.states))
+	for _, state := range d.states {
+		name := label(state)
+		if other, taken := owners[name]; taken {
+			return nil, NewInvalidConfigurationError("diagram",
+				fmt.Sprintf("states %v and %v both have the label %q", other, state, name))
+		}
+		owners[name] = state
+		names[state] = name
+	}
+	return names, nil
+}
+
+// defaultLabel writes runes as the character they represent and every other
+// value with %v.
+func defaultLabel[T comparable](value T) string {
+	if r, ok := any(value).(rune); ok {
+		return string(r)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// DiagramOptions configures the Mermaid and PlantUML exporters.
+type DiagramOptions[Q State, S Symbol] struct {
+	// LeftToRight lays the diagram out horizontally instead of top to bottom
+	LeftToRight bool
+	// StateLabel names each state; nil writes runes as characters and other
+	// states with %v
+	StateLabel func(Q) string
+	// SymbolLabel writes each symbol of an edge label; nil writes runes as
+	// characters and other symbols with %v
+	SymbolLabel func(S) string
+	// SymbolSeparator joins the symbols of transitions merged into one edge
+	SymbolSeparator string
+}
+
+// DefaultDiagramOptions returns the options used by ToMermaid and ToPlantUML:
+// a left-to-right diagram with merged edge labels separated by ", ".
+func DefaultDiagramOptions[Q State, S Symbol]() DiagramOptions[Q, S] {
+	return DiagramOptions[Q, S]{
+		LeftToRight:     true,
+		SymbolSeparator: ", ",
+	}
+}
+
+// labels returns the name of every state and a function labelling edges,
+// applying the defaults for unset options.
+func (o DiagramOptions[Q, S]) labels(d *diagram[Q, S]) (map[Q]string, func(diagramEdge[Q, S]) string, error) {
+	stateLabel := o.StateLabel
+	if stateLabel == nil {
+		stateLabel = defaultLabel[Q]
+	}
+	symbolLabel := o.SymbolLabel
+	if symbolLabel == nil {
+		symbolLabel = defaultLabel[S]
+	}
+	names, err := d.stateNames(stateLabel)
+	if err != nil {
+		return nil, nil, err
+	}
+	edgeLabel := func(edge diagramEdge[Q, S]) string {
+		labels := make([]string, len(edge.symbols))
+		for i, symbol := range edge.symbols {
+			labels[i] = symbolLabel(symbol)
+		}
+		return strings.Join(labels, o.SymbolSeparator)
+	}
+	return names, edgeLabel, nil
+}
+
+// stateIDs picks the identifier of each state for formats whose identifiers
+// are restricted. A name accepted by isPlain is used as is; other states get a
+// generated identifier "sN" that collides with no plain name, and must be
+// declared with their name as a description.
+func (d *diagram[Q, S]) stateIDs(names map[Q]string, isPlain func(string) bool) map[Q]string {
+	ids := make(map[Q]string, len(d.states))
+	taken := make(map[string]bool, len(d.states))
+	for _, state := range d.states {
+		if isPlain(names[state]) {
+			ids[state] = names[state]
+			taken[names[state]] = true
+		}
+	}
+	next := 0
+	for _, state := range d.states {
+		if _, plain := ids[state]; plain {
+			continue
+		}
+		id := fmt.Sprintf("s%d", next)
+		for taken[id] {
+			next++
+			id = fmt.Sprintf("s%d", next)
+		}
+		next++
+		ids[state] = id
+		taken[id] = true
+	}
+	return ids
+}
+
+// isDiagramIdentifier reports whether name consists only of ASCII letters,
+// digits and underscores, starts with a letter and is not a keyword.
+func isDiagramIdentifier(name string, keywords ...string) bool {
+	if name == "" || !(name[0] >= 'a' && name[0] <= 'z' || name[0] >= 'A' && name[0] <= 'Z') {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	for _, keyword := range keywords {
+		if strings.EqualFold(name, keyword) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,206 @@
+package fsm
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// DOTOptions configures the Graphviz output of ToDOTWithOptions.
+type DOTOptions[Q State, S Symbol] struct {
+	// Name is the name of the digraph
+	Name string
+	// RankDir is the layout direction ("LR", "TB", ...); empty leaves the Graphviz default
+	RankDir string
+	// StateLabel names each state; nil writes runes as characters and other
+	// states with %v
+	StateLabel func(Q) string
+	// SymbolLabel writes each symbol of an edge label; nil writes runes as
+	// characters and other symbols with %v
+	SymbolLabel func(S) string
+	// SymbolSeparator joins the symbols of transitions merged into one edge
+	SymbolSeparator string
+	// StateAttributes returns extra Graphviz attributes for a state, e.g.
+	// "color" or "tooltip". They override the default shape.
+	StateAttributes func(state Q) map[string]string
+	// EdgeAttributes returns extra Graphviz attributes for the merged edge
+	// from one state to another. They override the default label.
+	EdgeAttributes func(from, to Q, symbols []S) map[string]string
+}
+
+// DefaultDOTOptions returns the options used by ToDOT: a left-to-right graph
+// named "fsm" with merged edge labels separated by ", ".
+func DefaultDOTOptions[Q State, S Symbol]() DOTOptions[Q, S] {
+	return DOTOptions[Q, S]{
+		Name:            "fsm",
+		RankDir:         "LR",
+		SymbolSeparator: ", ",
+	}
+}
+
+// diagramOptions returns the labelling options shared with the other diagram
+// exporters.
+func (o DOTOptions[Q, S]) diagramOptions() DiagramOptions[Q, S] {
+	return DiagramOptions[Q, S]{
+		StateLabel:      o.StateLabel,
+		SymbolLabel:     o.SymbolLabel,
+		SymbolSeparator: o.SymbolSeparator,
+	}
+}
+
+// HighlightTrace returns a copy of the options that also draws the states and
+// edges visited by a trace, as returned by ProcessInputWithTrace, in the given
+// color with a thicker pen. Attributes from existing callbacks are kept unless
+// they set the same keys.
+func (o DOTOptions[Q, S]) HighlightTrace(trace []Q, color string) DOTOptions[Q, S] {
+	visited := make(map[Q]bool, len(trace))
+	traversed := make(map[[2]Q]bool, len(trace))
+	for i, state := range trace {
+		visited[state] = true
+		if i > 0 {
+			traversed[[2]Q{trace[i-1], state}] = true
+		}
+	}
+	highlight := map[string]string{"color": color, "penwidth": "2"}
+
+	stateAttributes := o.StateAttributes
+	o.StateAttributes = func(state Q) map[string]string {
+		attributes := make(map[string]string)
+		if stateAttributes != nil {
+			maps.Copy(attributes, stateAttributes(state))
+		}
+		if visited[state] {
+			maps.Copy(attributes, highlight)
+		}
+		return attributes
+	}
+
+	edgeAttributes := o.EdgeAttributes
+	o.EdgeAttributes = func(from, to Q, symbols []S) map[string]string {
+		attributes := make(map[string]string)
+		if edgeAttributes != nil {
+			maps.Copy(attributes, edgeAttributes(from, to, symbols))
+		}
+		if traversed[[2]Q{from, to}] {
+			maps.Copy(attributes, highlight)
+			attributes["fontcolor"] = color
+		}
+		return attributes
+	}
+	return o
+}
+
+// ToDOT renders an automaton as a Graphviz DOT digraph using DefaultDOTOptions.
+func ToDOT[Q State, S Symbol](automaton Automaton[Q, S]) (string, error) {
+	return ToDOTWithOptions(automaton, DefaultDOTOptions[Q, S]())
+}
+
+// ToDOTWithOptions renders an automaton as a Graphviz DOT digraph.
+//
+// The initial state is marked by an arrow from an invisible point, accepting
+// states are drawn as double circles and all transitions between the same pair
+// of states are merged into one edge whose label lists their symbols. States
+// and edges are written in sorted order, so the output is deterministic.
+// States are identified by their label, which must therefore be unique.
+//
+// Only a *FiniteAutomaton, or an ObservableAutomaton wrapping one, can be
+// drawn; other automata, including an *NFA or a compiled Session, fail with
+// an InvalidConfigurationError. Draw an NFA through Determinize.
+func ToDOTWithOptions[Q State, S Symbol](automaton Automaton[Q, S], options DOTOptions[Q, S]) (string, error) {
+	d, err := newDiagram(automaton)
+	if err != nil {
+		return "", err
+	}
+	names, edgeLabel, err := options.diagramOptions().labels(d)
+	if err != nil {
+		return "", err
+	}
+
+	// The invisible start node must not share its ID with a state.
+	taken := make(map[string]bool, len(names))
+	for _, name := range names {
+		taken[name] = true
+	}
+	start := "__start"
+	for taken[start] {
+		start += "_"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", quoteDOT(options.Name))
+	if options.RankDir != "" {
+		fmt.Fprintf(&sb, "  rankdir=%s;\n", quoteDOT(options.RankDir))
+	}
+	sb.WriteString("  node [shape=circle];\n")
+	fmt.Fprintf(&sb, "  %s [shape=point, label=\"\"];\n", quoteDOT(start))
+	fmt.Fprintf(&sb, "  %s -> %s;\n", quoteDOT(start), quoteDOT(names[d.initial]))
+
+	for _, state := range d.states {
+		attributes := make(map[string]string)
+		if d.accepting[state] {
+			attributes["shape"] = "doublecircle"
+		}
+		if options.StateAttributes != nil {
+			maps.Copy(attributes, options.StateAttributes(state))
+		}
+		fmt.Fprintf(&sb, "  %s%s;\n", quoteDOT(names[state]), dotAttributes(attributes))
+	}
+
+	for _, edge := range d.edges {
+		attributes := map[string]string{"label": edgeLabel(edge)}
+		if options.EdgeAttributes != nil {
+			maps.Copy(attributes, options.EdgeAttributes(edge.from, edge.to, edge.symbols))
+		}
+		fmt.Fprintf(&sb, "  %s -> %s%s;\n", quoteDOT(names[edge.from]), quoteDOT(names[edge.to]), dotAttributes(attributes))
+	}
+
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
+
+// dotAttributes formats an attribute list in key order, or nothing when empty.
+func dotAttributes(attributes map[string]string) string {
+	if len(attributes) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(attributes))
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		parts = append(parts, quoteDOT(key)+"="+quoteDOT(attributes[key]))
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// quoteDOT writes an ID as a plain identifier when possible and as a quoted
+// string otherwise.
+func quoteDOT(id string) string {
+	if isPlainDOTID(id) {
+		return id
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(id) + `"`
+}
+
+// isPlainDOTID reports whether id is a DOT identifier that is not a keyword,
+// or a string of digits.
+func isPlainDOTID(id string) bool {
+	if id == "" {
+		return false
+	}
+	if strings.Trim(id, "0123456789") == "" {
+		return true
+	}
+	switch strings.ToLower(id) {
+	case "node", "edge", "graph", "digraph", "subgraph", "strict":
+		return false
+	}
+	for i, r := range id {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
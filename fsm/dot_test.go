@@ -0,0 +1,147 @@
+package fsm
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestToDOT tests the exact output for a simple automaton
+func TestToDOT(t *testing.T) {
+	fa := New[string, rune]("start").
+		AddStates("start", "seen a", "done").
+		AddSymbols('a', 'b', 'c').
+		AddAcceptingState("done").
+		AddTransition("start", 'a', "seen a").
+		AddTransition("start", 'b', "start").
+		AddTransition("start", 'c', "start").
+		AddTransition("seen a", 'b', "done")
+
+	got, err := ToDOT[string, rune](fa)
+	if err != nil {
+		t.Fatalf("ToDOT returned error: %v", err)
+	}
+
+	want := `digraph fsm {
+  rankdir=LR;
+  node [shape=circle];
+  __start [shape=point, label=""];
+  __start -> start;
+  done [shape=doublecircle];
+  "seen a";
+  start;
+  "seen a" -> done [label=b];
+  start -> "seen a" [label=a];
+  start -> start [label="b, c"];
+}
+`
+	if got != want {
+		t.Errorf("ToDOT() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestToDOTWithOptions_Attributes tests the styling callbacks and label options
+func TestToDOTWithOptions_Attributes(t *testing.T) {
+	options := DefaultDOTOptions[string, rune]()
+	options.Name = "parity"
+	options.RankDir = ""
+	options.SymbolSeparator = "|"
+	options.StateLabel = strings.ToUpper
+	options.StateAttributes = func(state string) map[string]string {
+		return map[string]string{"tooltip": "state " + state}
+	}
+	options.EdgeAttributes = func(from, to string, symbols []rune) map[string]string {
+		if from == to {
+			return map[string]string{"style": "dashed"}
+		}
+		return nil
+	}
+
+	got, err := ToDOTWithOptions[string, rune](newParityAutomaton(), options)
+	if err != nil {
+		t.Fatalf("ToDOTWithOptions returned error: %v", err)
+	}
+
+	for _, line := range []string{
+		"digraph parity {",
+		`  EVEN [shape=doublecircle, tooltip="state even"];`,
+		`  ODD [tooltip="state odd"];`,
+		`  EVEN -> EVEN [label=0, style=dashed];`,
+		`  EVEN -> ODD [label=1];`,
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("Output is missing %q:\n%s", line, got)
+		}
+	}
+	if strings.Contains(got, "rankdir") {
+		t.Errorf("Output should not set rankdir:\n%s", got)
+	}
+}
+
+// TestDOTOptions_HighlightTrace tests highlighting the path taken by an input
+func TestDOTOptions_HighlightTrace(t *testing.T) {
+	fa := newParityAutomaton()
+	trace, _, err := fa.ProcessInputWithTrace([]rune("01"))
+	if err != nil {
+		t.Fatalf("ProcessInputWithTrace returned error: %v", err)
+	}
+
+	options := DefaultDOTOptions[string, rune]()
+	options.StateAttributes = func(state string) map[string]string {
+		return map[string]string{"tooltip": state, "color": "black"}
+	}
+	got, err := ToDOTWithOptions[string, rune](fa, options.HighlightTrace(trace, "red"))
+	if err != nil {
+		t.Fatalf("ToDOTWithOptions returned error: %v", err)
+	}
+
+	for _, line := range []string{
+		`  even [color=red, penwidth=2, shape=doublecircle, tooltip=even];`,
+		`  odd [color=red, penwidth=2, tooltip=odd];`,
+		`  even -> even [color=red, fontcolor=red, label=0, penwidth=2];`,
+		`  even -> odd [color=red, fontcolor=red, label=1, penwidth=2];`,
+		`  odd -> even [label=1];`,
+		`  odd -> odd [label=0];`,
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("Output is missing %q:\n%s", line, got)
+		}
+	}
+}
+
+// TestToDOT_Escaping tests quoting of names that are not DOT identifiers
+func TestToDOT_Escaping(t *testing.T) {
+	fa := New[string, string]("__start").
+		AddStates("__start", `say "hi"`, "node").
+		AddSymbols(`a\b`, "new\nline").
+		AddTransition("__start", `a\b`, `say "hi"`).
+		AddTransition(`say "hi"`, "new\nline", "node")
+
+	got, err := ToDOT[string, string](fa)
+	if err != nil {
+		t.Fatalf("ToDOT returned error: %v", err)
+	}
+
+	for _, line := range []string{
+		`  __start_ [shape=point, label=""];`,
+		`  __start_ -> __start;`,
+		`  __start -> "say \"hi\"" [label="a\\b"];`,
+		`  "say \"hi\"" -> "node" [label="new\nline"];`,
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("Output is missing %q:\n%s", line, got)
+		}
+	}
+}
+
+// TestToDOT_Errors tests rejected automata and options
+func TestToDOT_Errors(t *testing.T) {
+	options := DefaultDOTOptions[string, rune]()
+	options.StateLabel = func(string) string { return "same" }
+	if _, err := ToDOTWithOptions[string, rune](newParityAutomaton(), options); err == nil {
+		t.Error("Expected error for states sharing a label")
+	}
+
+	if _, err := ToDOT[StateSet[string], rune](newEndsWithABNFA()); err == nil {
+		t.Error("Expected error for an NFA")
+	}
+}
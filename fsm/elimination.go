@@ -0,0 +1,519 @@
+package fsm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// EmptyLanguageRegex is returned by ToRegex for automata that accept no input.
+const EmptyLanguageRegex = "∅"
+
+// ToRegex converts an automaton into an equivalent regular expression using
+// state elimination. The result is simplified for readability (ε and ∅ are
+// folded away, single-symbol alternatives become classes, x x* becomes x+)
+// and is deterministic for a given automaton.
+//
+// Rune symbols are escaped so that the pattern compiles back with CompileRegex
+// into an equivalent automaton. Other symbol types are formatted with %v; see
+// ToRegexWithFormatter. The empty input is written "()" and an automaton that
+// accepts nothing yields EmptyLanguageRegex.
+func ToRegex[Q State, S Symbol](automaton Automaton[Q, S]) (string, error) {
+	return toRegex(automaton, nil)
+}
+
+// ToRegexWithFormatter converts an automaton into a regular expression like
+// ToRegex, writing each symbol with the given function. When any symbol is
+// written with more than one character, concatenated symbols are separated
+// by a space and grouped before a repetition operator, e.g. "(login)+ logout".
+func ToRegexWithFormatter[Q State, S Symbol](automaton Automaton[Q, S], format func(S) string) (string, error) {
+	if format == nil {
+		return "", NewInvalidConfigurationError("format", "symbol formatter cannot be nil")
+	}
+	return toRegex(automaton, format)
+}
+
+func toRegex[Q State, S Symbol](automaton Automaton[Q, S], format func(S) string) (string, error) {
+	fa, err := finiteAutomatonOf(automaton)
+	if err != nil {
+		return "", err
+	}
+	if err := fa.Validate(); err != nil {
+		return "", NewErrorWithCause(ErrorTypeValidation, "cannot convert invalid automaton", err)
+	}
+
+	fa.mutex.RLock()
+	defer fa.mutex.RUnlock()
+
+	symbols := sortValues(fa.getAlphabetList())
+	printer := newRegexPrinter(symbols, format)
+	states := fa.productiveStates(fa.reachableStates(symbols))
+	if len(states) == 0 {
+		return EmptyLanguageRegex, nil
+	}
+
+	// Generalized automaton over states 0..n-1 plus a fresh start n and final n+1
+	n := len(states)
+	start, final := n, n+1
+	index := make(map[Q]int, n)
+	for i, state := range states {
+		index[state] = i
+	}
+	edges := make([]map[int]*regexTerm, n+2)
+	for i := range edges {
+		edges[i] = make(map[int]*regexTerm)
+	}
+	addEdge := func(from, to int, term *regexTerm) {
+		edges[from][to] = alternateTerms(edges[from][to], term)
+	}
+
+	addEdge(start, index[fa.initialState], epsilonTerm())
+	for i, state := range states {
+		if fa.acceptingStates[state] {
+			addEdge(i, final, epsilonTerm())
+		}
+		for s, symbol := range symbols {
+			if target, exists := fa.transitions[state][symbol]; exists {
+				if j, productive := index[target]; productive {
+					addEdge(i, j, symbolTerm(s))
+				}
+			}
+		}
+	}
+
+	eliminated := make([]bool, n)
+	for range states {
+		k := nextToEliminate(edges, eliminated)
+		eliminated[k] = true
+
+		loop := starTerm(edges[k][k])
+		delete(edges[k], k)
+		for i := range edges {
+			into, exists := edges[i][k]
+			if !exists || i == k {
+				continue
+			}
+			delete(edges[i], k)
+			for _, j := range sortValues(mapKeys(edges[k])) {
+				addEdge(i, j, concatTerms(into, loop, edges[k][j]))
+			}
+		}
+		edges[k] = make(map[int]*regexTerm)
+	}
+
+	result, exists := edges[start][final]
+	if !exists {
+		return EmptyLanguageRegex, nil
+	}
+	return printer.print(result), nil
+}
+
+// productiveStates filters the given states down to those from which an
+// accepting state is reachable, keeping their order. Callers must hold the mutex.
+func (fa *FiniteAutomaton[Q, S]) productiveStates(states []Q) []Q {
+	predecessors := make(map[Q][]Q)
+	for _, state := range states {
+		for _, target := range fa.transitions[state] {
+			predecessors[target] = append(predecessors[target], state)
+		}
+	}
+
+	productive := make(map[Q]bool)
+	queue := make([]Q, 0)
+	for _, state := range states {
+		if fa.acceptingStates[state] {
+			productive[state] = true
+			queue = append(queue, state)
+		}
+	}
+	for i := 0; i < len(queue); i++ {
+		for _, previous := range predecessors[queue[i]] {
+			if !productive[previous] {
+				productive[previous] = true
+				queue = append(queue, previous)
+			}
+		}
+	}
+
+	result := make([]Q, 0, len(productive))
+	for _, state := range states {
+		if productive[state] {
+			result = append(result, state)
+		}
+	}
+	return result
+}
+
+// nextToEliminate picks the remaining state whose removal creates the fewest
+// new edges, preferring states discovered earlier on ties.
+func nextToEliminate(edges []map[int]*regexTerm, eliminated []bool) int {
+	best, bestCost := -1, 0
+	for k := range eliminated {
+		if eliminated[k] {
+			continue
+		}
+		in, out := 0, len(edges[k])
+		if _, loops := edges[k][k]; loops {
+			out--
+		}
+		for i := range edges {
+			if _, exists := edges[i][k]; exists && i != k {
+				in++
+			}
+		}
+		if cost := in * out; best < 0 || cost < bestCost {
+			best, bestCost = k, cost
+		}
+	}
+	return best
+}
+
+// termKind identifies the type of a regular expression term built during state elimination.
+type termKind int
+
+const (
+	termEpsilon termKind = iota
+	termSymbol
+	termConcat
+	termAlternate
+	termStar
+	termPlus
+)
+
+// regexTerm is a simplified regular expression over symbol indices.
+type regexTerm struct {
+	kind     termKind
+	symbol   int
+	children []*regexTerm
+	// key is a canonical rendering used to compare and order terms
+	key string
+}
+
+func epsilonTerm() *regexTerm {
+	return &regexTerm{kind: termEpsilon, key: "ε"}
+}
+
+func symbolTerm(symbol int) *regexTerm {
+	return &regexTerm{kind: termSymbol, symbol: symbol, key: fmt.Sprintf("s%06d", symbol)}
+}
+
+func newCompoundTerm(kind termKind, children []*regexTerm) *regexTerm {
+	keys := make([]string, len(children))
+	for i, child := range children {
+		keys[i] = child.key
+	}
+	return &regexTerm{
+		kind:     kind,
+		children: children,
+		key:      fmt.Sprintf("%d(%s)", kind, strings.Join(keys, ",")),
+	}
+}
+
+// concatTerms concatenates terms, dropping ε and collapsing to nil (∅) when any part is nil.
+func concatTerms(terms ...*regexTerm) *regexTerm {
+	parts := make([]*regexTerm, 0, len(terms))
+	for _, term := range terms {
+		switch {
+		case term == nil:
+			return nil
+		case term.kind == termEpsilon:
+			continue
+		case term.kind == termConcat:
+			parts = append(parts, term.children...)
+		default:
+			parts = append(parts, term)
+		}
+	}
+
+	// x x* and x* x both become x+
+	merged := make([]*regexTerm, 0, len(parts))
+	for _, part := range parts {
+		if last := len(merged) - 1; last >= 0 {
+			previous := merged[last]
+			var repeated *regexTerm
+			if part.kind == termStar && part.children[0].key == previous.key {
+				repeated = previous
+			} else if previous.kind == termStar && previous.children[0].key == part.key {
+				repeated = part
+			}
+			if repeated != nil {
+				merged[last] = newCompoundTerm(termPlus, []*regexTerm{repeated})
+				continue
+			}
+		}
+		merged = append(merged, part)
+	}
+
+	switch len(merged) {
+	case 0:
+		return epsilonTerm()
+	case 1:
+		return merged[0]
+	default:
+		return newCompoundTerm(termConcat, merged)
+	}
+}
+
+// alternateTerms forms the union of two terms; nil stands for ∅.
+func alternateTerms(a, b *regexTerm) *regexTerm {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	seen := make(map[string]bool)
+	options := make([]*regexTerm, 0)
+	hasEpsilon := false
+	for _, term := range []*regexTerm{a, b} {
+		members := []*regexTerm{term}
+		if term.kind == termAlternate {
+			members = term.children
+		}
+		for _, member := range members {
+			if member.kind == termEpsilon {
+				hasEpsilon = true
+				continue
+			}
+			if !seen[member.key] {
+				seen[member.key] = true
+				options = append(options, member)
+			}
+		}
+	}
+
+	if hasEpsilon {
+		// ε is already included in any x* or via x+ → x*
+		absorbed := false
+		for i, option := range options {
+			switch option.kind {
+			case termStar:
+				absorbed = true
+			case termPlus:
+				options[i] = newCompoundTerm(termStar, option.children)
+				absorbed = true
+			}
+			if absorbed {
+				break
+			}
+		}
+		if !absorbed {
+			options = append(options, epsilonTerm())
+		}
+	}
+
+	slices.SortFunc(options, func(x, y *regexTerm) int { return strings.Compare(x.key, y.key) })
+	if len(options) == 1 {
+		return options[0]
+	}
+	return newCompoundTerm(termAlternate, options)
+}
+
+// starTerm returns t*, or ε when t is nil (∅* = ε).
+func starTerm(t *regexTerm) *regexTerm {
+	if t == nil || t.kind == termEpsilon {
+		return epsilonTerm()
+	}
+	switch t.kind {
+	case termStar:
+		return t
+	case termPlus:
+		return newCompoundTerm(termStar, t.children)
+	case termAlternate:
+		// (x|ε)* = x*
+		options := make([]*regexTerm, 0, len(t.children))
+		for _, option := range t.children {
+			if option.kind != termEpsilon {
+				options = append(options, option)
+			}
+		}
+		if len(options) == 1 {
+			return starTerm(options[0])
+		}
+		if len(options) < len(t.children) {
+			t = newCompoundTerm(termAlternate, options)
+		}
+	}
+	return newCompoundTerm(termStar, []*regexTerm{t})
+}
+
+// Precedence levels used when printing terms.
+const (
+	precedenceAlternate = iota
+	precedenceConcat
+	precedenceRepeat
+	precedenceAtom
+)
+
+// regexPrinter renders regexTerms as pattern text.
+type regexPrinter struct {
+	texts []string
+	// runes holds the symbol of each index when the alphabet is made of runes
+	runes     []rune
+	separator string
+}
+
+func newRegexPrinter[S Symbol](symbols []S, format func(S) string) *regexPrinter {
+	printer := &regexPrinter{texts: make([]string, len(symbols))}
+
+	if format == nil {
+		runes := make([]rune, len(symbols))
+		for i, symbol := range symbols {
+			r, ok := any(symbol).(rune)
+			if !ok {
+				format = func(symbol S) string { return fmt.Sprintf("%v", symbol) }
+				break
+			}
+			runes[i] = r
+			printer.texts[i] = escapeRegexRune(r, false)
+		}
+		if format == nil {
+			printer.runes = runes
+			return printer
+		}
+	}
+
+	for i, symbol := range symbols {
+		printer.texts[i] = format(symbol)
+		if utf8.RuneCountInString(printer.texts[i]) != 1 {
+			printer.separator = " "
+		}
+	}
+	return printer
+}
+
+func (p *regexPrinter) print(t *regexTerm) string {
+	text, _ := p.render(t)
+	return text
+}
+
+// render returns the text of a term along with its precedence.
+func (p *regexPrinter) render(t *regexTerm) (string, int) {
+	switch t.kind {
+	case termEpsilon:
+		return "()", precedenceAtom
+	case termSymbol:
+		if p.separator != "" && utf8.RuneCountInString(p.texts[t.symbol]) != 1 {
+			// Multi-character symbols group like a concatenation
+			return p.texts[t.symbol], precedenceConcat
+		}
+		return p.texts[t.symbol], precedenceAtom
+	case termStar:
+		return p.operand(t.children[0], precedenceAtom) + "*", precedenceRepeat
+	case termPlus:
+		return p.operand(t.children[0], precedenceAtom) + "+", precedenceRepeat
+	case termConcat:
+		parts := make([]string, len(t.children))
+		for i, child := range t.children {
+			parts[i] = p.operand(child, precedenceConcat)
+		}
+		return strings.Join(parts, p.separator), precedenceConcat
+	default:
+		return p.renderAlternation(t)
+	}
+}
+
+func (p *regexPrinter) renderAlternation(t *regexTerm) (string, int) {
+	optional := false
+	var symbols []rune
+	options := make([]string, 0, len(t.children))
+	for _, child := range t.children {
+		switch {
+		case child.kind == termEpsilon:
+			optional = true
+		case child.kind == termSymbol && p.runes != nil:
+			symbols = append(symbols, p.runes[child.symbol])
+		default:
+			options = append(options, p.operand(child, precedenceAlternate+1))
+		}
+	}
+
+	switch len(symbols) {
+	case 0:
+	case 1:
+		options = append(options, escapeRegexRune(symbols[0], false))
+	default:
+		options = append(options, runeClass(symbols))
+	}
+	slices.Sort(options)
+
+	if !optional {
+		if len(options) == 1 {
+			return options[0], precedenceAtom
+		}
+		return strings.Join(options, "|"), precedenceAlternate
+	}
+
+	// x|ε is written x?
+	if len(options) == 1 && len(t.children) == 2 {
+		return p.operand(t.children[optionIndex(t)], precedenceAtom) + "?", precedenceRepeat
+	}
+	return "(" + strings.Join(options, "|") + ")?", precedenceRepeat
+}
+
+// optionIndex returns the index of the non-ε child of a two-child alternation.
+func optionIndex(t *regexTerm) int {
+	if t.children[0].kind == termEpsilon {
+		return 1
+	}
+	return 0
+}
+
+// operand renders a term, adding parentheses when it binds looser than required.
+func (p *regexPrinter) operand(t *regexTerm, required int) string {
+	text, precedence := p.render(t)
+	if precedence < required {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+// runeClass renders sorted runes as a bracket expression, collapsing runs into ranges.
+func runeClass(runes []rune) string {
+	runes = sortValues(runes)
+	var builder strings.Builder
+	builder.WriteByte('[')
+	for i := 0; i < len(runes); {
+		j := i
+		for j+1 < len(runes) && runes[j+1] == runes[j]+1 {
+			j++
+		}
+		builder.WriteString(escapeRegexRune(runes[i], true))
+		if j-i >= 2 {
+			builder.WriteByte('-')
+			builder.WriteString(escapeRegexRune(runes[j], true))
+		} else if j > i {
+			builder.WriteString(escapeRegexRune(runes[j], true))
+		}
+		i = j + 1
+	}
+	builder.WriteByte(']')
+	return builder.String()
+}
+
+// escapeRegexRune writes a rune so that CompileRegex reads it back as a literal.
+func escapeRegexRune(r rune, inClass bool) string {
+	switch r {
+	case '\n':
+		return `\n`
+	case '\t':
+		return `\t`
+	case '\r':
+		return `\r`
+	case '\f':
+		return `\f`
+	case '\v':
+		return `\v`
+	}
+
+	special := `\.+*?()|[]{}^$`
+	if inClass {
+		special = `\]^-[`
+	}
+	if strings.ContainsRune(special, r) {
+		return `\` + string(r)
+	}
+	return string(r)
+}
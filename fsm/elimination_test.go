@@ -0,0 +1,128 @@
+package fsm
+
+import (
+	"testing"
+)
+
+// TestToRegex tests the rendering of simple automata
+func TestToRegex(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"abc", "abc"},
+		{"a|b|c", "[a-c]"},
+		{"a*", "a*"},
+		{"aa*", "a+"},
+		{"a?", "a?"},
+		{"()", "()"},
+		{"ab|ac", "a[bc]"},
+		{`\.\*`, `\.\*`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			fa, err := CompileRegex(tt.pattern)
+			if err != nil {
+				t.Fatalf("CompileRegex returned error: %v", err)
+			}
+			got, err := ToRegex[int, rune](fa)
+			if err != nil {
+				t.Fatalf("ToRegex returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ToRegex() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestToRegex_RoundTrip tests that converted patterns compile back to equivalent automata
+func TestToRegex_RoundTrip(t *testing.T) {
+	automata := map[string]*FiniteAutomaton[string, rune]{
+		"parity":       newParityAutomaton(),
+		"endsWithZero": newEndsWithZeroAutomaton(),
+	}
+	for _, pattern := range []string{"(a|b)*abb", "(ab|ba)*", "a(b|c)*d?", "((0|1)(0|1))*1", `[.\]^-]+x`} {
+		fa, err := CompileRegex(pattern)
+		if err != nil {
+			t.Fatalf("CompileRegex(%q) returned error: %v", pattern, err)
+		}
+		automata[pattern] = renumberedAsStrings(fa)
+	}
+
+	for name, fa := range automata {
+		t.Run(name, func(t *testing.T) {
+			pattern, err := ToRegex[string, rune](fa)
+			if err != nil {
+				t.Fatalf("ToRegex returned error: %v", err)
+			}
+			compiled, err := CompileRegexWithAlphabet(pattern, fa.getAlphabetList())
+			if err != nil {
+				t.Fatalf("CompileRegex(%q) returned error: %v", pattern, err)
+			}
+			for _, word := range wordsOver(string(sortValues(fa.getAlphabetList())), 5) {
+				if accepts(t, fa, word) != accepts(t, compiled, word) {
+					t.Errorf("pattern %q disagrees with the automaton on %q", pattern, word)
+				}
+			}
+		})
+	}
+}
+
+// renumberedAsStrings converts integer states to strings so automata can share a map
+func renumberedAsStrings(fa *FiniteAutomaton[int, rune]) *FiniteAutomaton[string, rune] {
+	name := func(state int) string { return string(rune('A' + state)) }
+	result := New[string, rune](name(fa.GetInitialState()))
+	result.AddSymbols(fa.getAlphabetList()...)
+	for _, state := range fa.getStatesList() {
+		result.AddState(name(state))
+		if fa.IsAcceptingState(state) {
+			result.AddAcceptingState(name(state))
+		}
+		for symbol, target := range fa.transitions[state] {
+			result.AddTransition(name(state), symbol, name(target))
+		}
+	}
+	return result
+}
+
+// TestToRegex_EmptyLanguage tests automata that accept nothing
+func TestToRegex_EmptyLanguage(t *testing.T) {
+	fa := New[string, rune]("q0").
+		AddStates("q0", "q1", "unreachable").
+		AddSymbols('a').
+		AddAcceptingState("unreachable").
+		AddTransition("q0", 'a', "q1")
+
+	got, err := ToRegex[string, rune](fa)
+	if err != nil {
+		t.Fatalf("ToRegex returned error: %v", err)
+	}
+	if got != EmptyLanguageRegex {
+		t.Errorf("ToRegex() = %q, want %q", got, EmptyLanguageRegex)
+	}
+}
+
+// TestToRegexWithFormatter tests multi-character symbols
+func TestToRegexWithFormatter(t *testing.T) {
+	fa := New[string, string]("out").
+		AddStates("out", "in").
+		AddSymbols("login", "logout", "read").
+		AddAcceptingState("out").
+		AddTransition("out", "login", "in").
+		AddTransition("in", "read", "in").
+		AddTransition("in", "logout", "out")
+
+	got, err := ToRegexWithFormatter[string, string](fa, func(symbol string) string { return symbol })
+	if err != nil {
+		t.Fatalf("ToRegexWithFormatter returned error: %v", err)
+	}
+	if want := "(login (read)* logout)*"; got != want {
+		t.Errorf("ToRegexWithFormatter() = %q, want %q", got, want)
+	}
+
+	if _, err := ToRegexWithFormatter[string, string](fa, nil); err == nil {
+		t.Error("Expected error for nil formatter")
+	}
+}
@@ -1,6 +1,7 @@
 package fsm
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 )
@@ -39,6 +40,11 @@ func (et ErrorType) String() string {
 	}
 }
 
+// MarshalText encodes the error type as its name, e.g. "ValidationError".
+func (et ErrorType) MarshalText() ([]byte, error) {
+	return []byte(et.String()), nil
+}
+
 // AutomatonError represents a structured error with context.
 type AutomatonError struct {
 	Type    ErrorType
@@ -69,6 +75,34 @@ func (e *AutomatonError) Error() string {
 	return strings.Join(parts, " ")
 }
 
+// MarshalJSON encodes the error as an object with its type name, message,
+// context and the text of its cause. Context values that cannot be encoded as
+// JSON are written with %v.
+func (e *AutomatonError) MarshalJSON() ([]byte, error) {
+	context := make(map[string]interface{}, len(e.Context))
+	for key, value := range e.Context {
+		if _, err := json.Marshal(value); err != nil {
+			value = fmt.Sprintf("%v", value)
+		}
+		context[key] = value
+	}
+
+	encoded := struct {
+		Type    ErrorType              `json:"type"`
+		Message string                 `json:"message"`
+		Context map[string]interface{} `json:"context"`
+		Cause   string                 `json:"cause,omitempty"`
+	}{
+		Type:    e.Type,
+		Message: e.Message,
+		Context: context,
+	}
+	if e.Cause != nil {
+		encoded.Cause = e.Cause.Error()
+	}
+	return json.Marshal(encoded)
+}
+
 // Unwrap returns the underlying cause of the error.
 func (e *AutomatonError) Unwrap() error {
 	return e.Cause
@@ -202,6 +236,12 @@ func (ec *ErrorCollector) Errors() []error {
 	return ec.errors
 }
 
+// Unwrap returns the collected errors so that errors.Is and errors.As look
+// through the collector.
+func (ec *ErrorCollector) Unwrap() []error {
+	return ec.errors
+}
+
 // ToError returns the collector as an error if there are any errors, nil otherwise.
 func (ec *ErrorCollector) ToError() error {
 	if ec.HasErrors() {
@@ -0,0 +1,38 @@
+package fsm
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestAutomatonError_MarshalJSON tests the machine-readable form of errors
+func TestAutomatonError_MarshalJSON(t *testing.T) {
+	err := NewTransitionError("locked", "push", "no transition").
+		WithContext("callback", func() {}).
+		WithCause(errors.New("underlying"))
+
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatalf("Marshal returned error: %v", marshalErr)
+	}
+
+	var decoded struct {
+		Type    string                 `json:"type"`
+		Message string                 `json:"message"`
+		Context map[string]interface{} `json:"context"`
+		Cause   string                 `json:"cause"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if decoded.Type != "TransitionError" || decoded.Message != "no transition" || decoded.Cause != "underlying" {
+		t.Errorf("Unexpected encoding: %s", data)
+	}
+	if decoded.Context["state"] != "locked" || decoded.Context["symbol"] != "push" {
+		t.Errorf("Expected state and symbol in context, got %v", decoded.Context)
+	}
+	if _, ok := decoded.Context["callback"].(string); !ok {
+		t.Errorf("Expected unencodable context value as text, got %v", decoded.Context["callback"])
+	}
+}
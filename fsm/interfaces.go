@@ -1,5 +1,7 @@
 package fsm
 
+import "context"
+
 // Automaton defines the core interface for finite state automata.
 // This interface allows for different implementations while maintaining
 // a consistent API for users.
@@ -21,6 +23,20 @@ type Automaton[Q State, S Symbol] interface {
 	String() string
 }
 
+// ContextAutomaton is an Automaton whose input processing can be cancelled.
+// FiniteAutomaton, NFA and ObservableAutomaton implement it.
+type ContextAutomaton[Q State, S Symbol] interface {
+	Automaton[Q, S]
+	ProcessInputContext(ctx context.Context, input []S) (bool, error)
+	ProcessInputWithTraceContext(ctx context.Context, input []S) ([]Q, bool, error)
+}
+
+// Versioned is implemented by mutable automata that count changes to their
+// definition. FiniteAutomaton, NFA and ObservableAutomaton implement it.
+type Versioned interface {
+	Version() uint64
+}
+
 // Builder defines the interface for building automata using the builder pattern.
 // This allows for different builder implementations and makes the API more flexible.
 type Builder[Q State, S Symbol] interface {
@@ -39,6 +55,13 @@ type Processor[Q State, S Symbol] interface {
 	Process(automaton Automaton[Q, S], input []S) (ProcessResult[Q], error)
 }
 
+// ContextProcessor is a Processor that stops with an InterruptedError when
+// its context is done.
+type ContextProcessor[Q State, S Symbol] interface {
+	Processor[Q, S]
+	ProcessContext(ctx context.Context, automaton Automaton[Q, S], input []S) (ProcessResult[Q], error)
+}
+
 // ProcessResult encapsulates the result of processing input through an automaton.
 type ProcessResult[Q State] struct {
 	Accepted   bool
@@ -118,4 +141,12 @@ type AutomatonConverter[Q1, Q2 State, S1, S2 Symbol] interface {
 var (
 	_ Automaton[string, rune] = (*FiniteAutomaton[string, rune])(nil)
 	_ Builder[string, rune]   = (*AutomatonBuilder[string, rune])(nil)
+
+	_ Automaton[StateSet[string], rune] = (*NFA[string, rune])(nil)
+
+	_ AutomatonConverter[StateSet[string], int, rune, rune] = (*SubsetConverter[string, int, rune])(nil)
+
+	_ Serializer[string, rune]   = (*JSONSerializer[string, rune])(nil)
+	_ Serializer[string, rune]   = (*YAMLSerializer[string, rune])(nil)
+	_ Serializer[string, string] = (*SCXMLSerializer)(nil)
 )
@@ -0,0 +1,131 @@
+package fsm
+
+// Language operations compare the sets of inputs accepted by automata.
+//
+// An input belongs to the language of a FiniteAutomaton when ProcessInput
+// returns true without error. Inputs that use a symbol outside the alphabet
+// or reach an undefined transition are not in the language.
+
+// Equivalent decides whether two automata accept exactly the same inputs.
+// When they differ it returns the shortest input accepted by exactly one of
+// them (the first in symbol order among inputs of that length); the
+// counterexample is nil when the automata are equivalent.
+func Equivalent[Q State, S Symbol](a, b Automaton[Q, S]) (bool, []S, error) {
+	witness, found, err := findProductWitness(a, b, func(leftAccepts, rightAccepts bool) bool {
+		return leftAccepts != rightAccepts
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	return !found, witness, nil
+}
+
+// IsSubset decides whether every input accepted by a is also accepted by b,
+// i.e. whether L(a) ⊆ L(b). When inclusion fails it returns the shortest input
+// in L(a) \ L(b) as a witness; the witness is nil when inclusion holds.
+func IsSubset[Q State, S Symbol](a, b Automaton[Q, S]) (bool, []S, error) {
+	witness, found, err := findProductWitness(a, b, func(leftAccepts, rightAccepts bool) bool {
+		return leftAccepts && !rightAccepts
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	return !found, witness, nil
+}
+
+// findProductWitness explores the product of two automata breadth-first over the
+// union of their alphabets and returns the shortest input whose pair of
+// acceptance results satisfies the predicate.
+func findProductWitness[Q State, S Symbol](
+	a, b Automaton[Q, S],
+	isWitness func(leftAccepts, rightAccepts bool) bool,
+) ([]S, bool, error) {
+	left, err := finiteAutomatonOf(a)
+	if err != nil {
+		return nil, false, err
+	}
+	right, err := finiteAutomatonOf(b)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := left.Validate(); err != nil {
+		return nil, false, NewErrorWithCause(ErrorTypeValidation, "invalid left operand", err)
+	}
+	if err := right.Validate(); err != nil {
+		return nil, false, NewErrorWithCause(ErrorTypeValidation, "invalid right operand", err)
+	}
+
+	left.mutex.RLock()
+	defer left.mutex.RUnlock()
+	if right != left {
+		right.mutex.RLock()
+		defer right.mutex.RUnlock()
+	}
+
+	symbols := unionAlphabet(left, right)
+	accepts := func(p ProductState[Q]) (bool, bool) {
+		return productAccepts(left, right, p)
+	}
+
+	type visit struct {
+		parent int
+		symbol S
+	}
+	start := ProductState[Q]{Left: left.initialState, Right: right.initialState}
+	if isWitness(accepts(start)) {
+		return []S{}, true, nil
+	}
+
+	seen := map[ProductState[Q]]bool{start: true}
+	queue := []ProductState[Q]{start}
+	visits := []visit{{parent: -1}}
+
+	for i := 0; i < len(queue); i++ {
+		current := queue[i]
+		for _, symbol := range symbols {
+			next := productStep(left, right, current, symbol)
+			if seen[next] || (next.LeftDead && next.RightDead) {
+				continue
+			}
+			seen[next] = true
+			queue = append(queue, next)
+			visits = append(visits, visit{parent: i, symbol: symbol})
+
+			if isWitness(accepts(next)) {
+				var word []S
+				for j := len(visits) - 1; visits[j].parent >= 0; j = visits[j].parent {
+					word = append(word, visits[j].symbol)
+				}
+				for l, r := 0, len(word)-1; l < r; l, r = l+1, r-1 {
+					word[l], word[r] = word[r], word[l]
+				}
+				return word, true, nil
+			}
+		}
+	}
+
+	return nil, false, nil
+}
+
+// step returns δ(state, symbol) if the symbol is in Σ and the transition is defined.
+// Callers must hold the mutex.
+func (fa *FiniteAutomaton[Q, S]) step(state Q, symbol S) (Q, bool) {
+	if !fa.alphabet[symbol] {
+		var zero Q
+		return zero, false
+	}
+	next, exists := fa.transitions[state][symbol]
+	return next, exists
+}
+
+// unionAlphabet returns Σ₁ ∪ Σ₂ in deterministic order. Callers must hold both mutexes.
+func unionAlphabet[Q State, S Symbol](left, right *FiniteAutomaton[Q, S]) []S {
+	union := make(map[S]bool, len(left.alphabet)+len(right.alphabet))
+	for symbol := range left.alphabet {
+		union[symbol] = true
+	}
+	for symbol := range right.alphabet {
+		union[symbol] = true
+	}
+	return sortValues(mapKeys(union))
+}
@@ -0,0 +1,138 @@
+package fsm
+
+import (
+	"testing"
+)
+
+// newParityAutomaton accepts binary strings with an even number of 1s
+func newParityAutomaton() *FiniteAutomaton[string, rune] {
+	return New[string, rune]("even").
+		AddStates("even", "odd").
+		AddSymbols('0', '1').
+		AddAcceptingState("even").
+		AddTransition("even", '0', "even").
+		AddTransition("even", '1', "odd").
+		AddTransition("odd", '0', "odd").
+		AddTransition("odd", '1', "even")
+}
+
+// TestEquivalent_SameLanguage tests equivalence of structurally different automata
+func TestEquivalent_SameLanguage(t *testing.T) {
+	equivalent, counterexample, err := Equivalent[string, rune](newParityAutomaton(), newRedundantParityAutomaton())
+	if err != nil {
+		t.Fatalf("Equivalent returned error: %v", err)
+	}
+	if !equivalent {
+		t.Errorf("Automata should be equivalent, counterexample %q", string(counterexample))
+	}
+	if counterexample != nil {
+		t.Errorf("Counterexample should be nil for equivalent automata, got %q", string(counterexample))
+	}
+}
+
+// TestEquivalent_Counterexample tests that the shortest distinguishing input is returned
+func TestEquivalent_Counterexample(t *testing.T) {
+	// Accepts strings with an even number of 1s, except that "11" is rejected
+	modified := newParityAutomaton().
+		AddStates("one", "two").
+		AddTransition("even", '1', "one").
+		AddTransition("one", '0', "odd").
+		AddTransition("one", '1', "two").
+		AddTransition("two", '0', "even").
+		AddTransition("two", '1', "odd")
+
+	equivalent, counterexample, err := Equivalent[string, rune](newParityAutomaton(), modified)
+	if err != nil {
+		t.Fatalf("Equivalent returned error: %v", err)
+	}
+	if equivalent {
+		t.Fatal("Automata should not be equivalent")
+	}
+	if string(counterexample) != "11" {
+		t.Errorf("Counterexample = %q, want \"11\"", string(counterexample))
+	}
+}
+
+// TestEquivalent_EmptyInput tests a counterexample of length zero
+func TestEquivalent_EmptyInput(t *testing.T) {
+	rejectsEmpty := newParityAutomaton()
+	rejectsEmpty.acceptingStates = map[string]bool{"odd": true}
+
+	equivalent, counterexample, err := Equivalent[string, rune](newParityAutomaton(), rejectsEmpty)
+	if err != nil {
+		t.Fatalf("Equivalent returned error: %v", err)
+	}
+	if equivalent || counterexample == nil || len(counterexample) != 0 {
+		t.Errorf("Expected empty counterexample, got equivalent=%v counterexample=%q", equivalent, string(counterexample))
+	}
+}
+
+// TestEquivalent_PartialAndDifferentAlphabets tests undefined transitions and extra symbols
+func TestEquivalent_PartialAndDifferentAlphabets(t *testing.T) {
+	onlyA := New[int, rune](0).AddStates(0, 1).AddSymbols('a').AddAcceptingState(1).AddTransition(0, 'a', 1)
+
+	// Same language, but with a dead trap state and an unused symbol
+	withTrap := New[int, rune](0).
+		AddStates(0, 1, 2).
+		AddSymbols('a', 'b').
+		AddAcceptingState(1).
+		AddTransition(0, 'a', 1).
+		AddTransition(0, 'b', 2).
+		AddTransition(1, 'a', 2).
+		AddTransition(2, 'a', 2)
+
+	equivalent, counterexample, err := Equivalent[int, rune](onlyA, withTrap)
+	if err != nil {
+		t.Fatalf("Equivalent returned error: %v", err)
+	}
+	if !equivalent {
+		t.Errorf("Automata should be equivalent, counterexample %q", string(counterexample))
+	}
+
+	withTrap.AddAcceptingState(2)
+	equivalent, counterexample, _ = Equivalent[int, rune](onlyA, withTrap)
+	if equivalent || string(counterexample) != "b" {
+		t.Errorf("Expected counterexample \"b\", got equivalent=%v counterexample=%q", equivalent, string(counterexample))
+	}
+}
+
+// TestEquivalent_InvalidOperands tests error handling
+func TestEquivalent_InvalidOperands(t *testing.T) {
+	if _, _, err := Equivalent[string, rune](newParityAutomaton(), New[string, rune]("q0")); err == nil {
+		t.Error("Equivalent should fail for invalid operand")
+	}
+}
+
+// TestIsSubset tests language inclusion and witnesses
+func TestIsSubset(t *testing.T) {
+	// Accepts exactly the strings of 0s, which all have an even number of 1s
+	zeros := New[string, rune]("even").
+		AddStates("even").
+		AddSymbols('0', '1').
+		AddAcceptingState("even").
+		AddTransition("even", '0', "even")
+
+	subset, witness, err := IsSubset[string, rune](zeros, newParityAutomaton())
+	if err != nil {
+		t.Fatalf("IsSubset returned error: %v", err)
+	}
+	if !subset {
+		t.Errorf("0* should be included in the even-parity language, witness %q", string(witness))
+	}
+
+	subset, witness, err = IsSubset[string, rune](newParityAutomaton(), zeros)
+	if err != nil {
+		t.Fatalf("IsSubset returned error: %v", err)
+	}
+	if subset {
+		t.Fatal("Even-parity language should not be included in 0*")
+	}
+	if string(witness) != "11" {
+		t.Errorf("Witness = %q, want \"11\"", string(witness))
+	}
+
+	accepted, _ := newParityAutomaton().ProcessInput(witness)
+	if !accepted {
+		t.Error("Witness should be accepted by the left operand")
+	}
+}
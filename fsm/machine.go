@@ -0,0 +1,325 @@
+package fsm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Machine is an immutable, compiled FiniteAutomaton. States and symbols are
+// numbered and the transition function is a flat table, so a Machine can be
+// shared by any number of goroutines without locking. Runs are carried by
+// Cursors and Sessions, which hold their own current state.
+//
+// Example usage:
+//
+//	machine, _ := Compile[string, rune](automaton)
+//	go func() {
+//		accepted, _ := machine.ProcessInput([]rune("aab"))
+//	}()
+//	session := machine.NewSession() // one per goroutine
+//	session.Step('a')
+type Machine[Q State, S Symbol] struct {
+	states    []Q
+	index     map[Q]int
+	accepting []bool
+	initial   int
+	symbols   map[S]int
+	// next[state*len(symbols)+symbol] is the next state, or -1 when the
+	// transition is undefined
+	next []int
+}
+
+// Compile builds the Machine of a FiniteAutomaton, looking through wrappers
+// such as ObservableAutomaton. States referenced only by transitions or as
+// the initial state are included. Later changes to the automaton do not
+// affect the Machine.
+func Compile[Q State, S Symbol](automaton Automaton[Q, S]) (*Machine[Q, S], error) {
+	fa, err := finiteAutomatonOf(automaton)
+	if err != nil {
+		return nil, err
+	}
+
+	fa.mutex.RLock()
+	defer fa.mutex.RUnlock()
+
+	present := make(map[Q]bool, len(fa.states)+1)
+	for state := range fa.states {
+		present[state] = true
+	}
+	present[fa.initialState] = true
+	for from, transitions := range fa.transitions {
+		present[from] = true
+		for _, to := range transitions {
+			present[to] = true
+		}
+	}
+
+	m := &Machine[Q, S]{
+		states:  sortValues(mapKeys(present)),
+		symbols: make(map[S]int, len(fa.alphabet)),
+	}
+	index := make(map[Q]int, len(m.states))
+	m.index = index
+	m.accepting = make([]bool, len(m.states))
+	for i, state := range m.states {
+		index[state] = i
+		m.accepting[i] = fa.acceptingStates[state]
+	}
+	m.initial = index[fa.initialState]
+
+	alphabet := sortValues(fa.getAlphabetList())
+	for i, symbol := range alphabet {
+		m.symbols[symbol] = i
+	}
+
+	m.next = make([]int, len(m.states)*len(alphabet))
+	for i := range m.next {
+		m.next[i] = -1
+	}
+	for from, transitions := range fa.transitions {
+		for symbol, to := range transitions {
+			// Transitions on symbols outside the alphabet can never be taken.
+			if column, exists := m.symbols[symbol]; exists {
+				m.next[index[from]*len(alphabet)+column] = index[to]
+			}
+		}
+	}
+	return m, nil
+}
+
+// NewCursor returns a cursor in the initial state.
+func (m *Machine[Q, S]) NewCursor() Cursor[Q, S] {
+	return Cursor[Q, S]{machine: m, state: m.initial}
+}
+
+// NewSession returns a session in the initial state.
+func (m *Machine[Q, S]) NewSession() *Session[Q, S] {
+	return &Session[Q, S]{cursor: m.NewCursor()}
+}
+
+// ProcessInput runs the input from the initial state. Unlike
+// FiniteAutomaton.ProcessInput it keeps no state and is safe for concurrent
+// use.
+func (m *Machine[Q, S]) ProcessInput(input []S) (bool, error) {
+	if err := m.validateInput(input); err != nil {
+		return false, err
+	}
+	cursor := m.NewCursor()
+	for _, symbol := range input {
+		if _, err := cursor.Step(symbol); err != nil {
+			return false, err
+		}
+	}
+	return cursor.Accepting(), nil
+}
+
+// ProcessInputWithTrace runs the input from the initial state and returns
+// the states visited. It is safe for concurrent use.
+func (m *Machine[Q, S]) ProcessInputWithTrace(input []S) ([]Q, bool, error) {
+	if err := m.validateInput(input); err != nil {
+		return nil, false, err
+	}
+	cursor := m.NewCursor()
+	trace := make([]Q, 1, len(input)+1)
+	trace[0] = cursor.State()
+	for _, symbol := range input {
+		next, err := cursor.Step(symbol)
+		if err != nil {
+			return trace, false, err
+		}
+		trace = append(trace, next)
+	}
+	return trace, cursor.Accepting(), nil
+}
+
+// Transition returns the state reached from the given state on symbol
+// (implements StateTransitioner interface). The errors are those of
+// Cursor.Step; a state unknown to the machine has no transitions.
+func (m *Machine[Q, S]) Transition(state Q, symbol S) (Q, error) {
+	i, exists := m.index[state]
+	if !exists {
+		var zero Q
+		return zero, NewTransitionError(state, symbol,
+			fmt.Sprintf("no transition defined for state %v with symbol %v", state, symbol))
+	}
+	cursor := Cursor[Q, S]{machine: m, state: i}
+	return cursor.Step(symbol)
+}
+
+// validateInput checks the input like ValidateInputSequence does for a
+// FiniteAutomaton, so that the machine fails on the same inputs.
+func (m *Machine[Q, S]) validateInput(input []S) error {
+	if input == nil {
+		return NewInvalidInputError(*new(S), 0, "input sequence cannot be nil")
+	}
+	for i, symbol := range input {
+		if _, exists := m.symbols[symbol]; !exists {
+			return NewInvalidInputError(symbol, i, fmt.Sprintf("symbol at position %d is not in alphabet", i))
+		}
+	}
+	return nil
+}
+
+// InitialState returns the initial state.
+func (m *Machine[Q, S]) InitialState() Q {
+	return m.states[m.initial]
+}
+
+// IsAcceptingState checks if the given state is an accepting state.
+func (m *Machine[Q, S]) IsAcceptingState(state Q) bool {
+	i, exists := m.index[state]
+	return exists && m.accepting[i]
+}
+
+// String returns a summary of the machine.
+func (m *Machine[Q, S]) String() string {
+	var sb strings.Builder
+	sb.WriteString("Compiled Machine:\n")
+	sb.WriteString(fmt.Sprintf("  Q (States): %v\n", m.states))
+	sb.WriteString(fmt.Sprintf("  |Σ| (Symbols): %d\n", len(m.symbols)))
+	sb.WriteString(fmt.Sprintf("  q0 (Initial): %v\n", m.states[m.initial]))
+	return sb.String()
+}
+
+// Cursor is a position in a run of a Machine. It is a small value: copying a
+// cursor forks the run. A Cursor must not be stepped by several goroutines at
+// once, but any number of cursors can share a Machine.
+type Cursor[Q State, S Symbol] struct {
+	machine *Machine[Q, S]
+	state   int
+}
+
+// Step takes the transition on symbol. On error the cursor does not move.
+// The errors are those of FiniteAutomaton.Step.
+func (c *Cursor[Q, S]) Step(symbol S) (Q, error) {
+	m := c.machine
+	column, exists := m.symbols[symbol]
+	if !exists {
+		var zero Q
+		return zero, NewErrorWithContext(ErrorTypeInvalidInput,
+			fmt.Sprintf("symbol not in alphabet: %v", symbol), map[string]interface{}{"symbol": symbol})
+	}
+	next := m.next[c.state*len(m.symbols)+column]
+	if next < 0 {
+		var zero Q
+		state := m.states[c.state]
+		return zero, NewTransitionError(state, symbol,
+			fmt.Sprintf("no transition defined for state %v with symbol %v", state, symbol))
+	}
+	c.state = next
+	return m.states[next], nil
+}
+
+// State returns the current state.
+func (c *Cursor[Q, S]) State() Q {
+	return c.machine.states[c.state]
+}
+
+// Accepting reports whether the current state is accepting.
+func (c *Cursor[Q, S]) Accepting() bool {
+	return c.machine.accepting[c.state]
+}
+
+// Reset returns the cursor to the initial state.
+func (c *Cursor[Q, S]) Reset() {
+	c.state = c.machine.initial
+}
+
+// Machine returns the machine the cursor runs on.
+func (c *Cursor[Q, S]) Machine() *Machine[Q, S] {
+	return c.machine
+}
+
+// Session is a run of a Machine implementing the Automaton and
+// ContextAutomaton interfaces, so it can be passed to processors and other
+// code written against Automaton. Each session carries its own current state;
+// a session must not be used by several goroutines at once.
+type Session[Q State, S Symbol] struct {
+	cursor Cursor[Q, S]
+}
+
+// Machine returns the machine the session runs on.
+func (s *Session[Q, S]) Machine() *Machine[Q, S] {
+	return s.cursor.machine
+}
+
+// GetInitialState returns the initial state of the machine.
+func (s *Session[Q, S]) GetInitialState() Q {
+	return s.cursor.machine.InitialState()
+}
+
+// GetCurrentState returns the current state of the session.
+func (s *Session[Q, S]) GetCurrentState() Q {
+	return s.cursor.State()
+}
+
+// Reset returns the session to the initial state.
+func (s *Session[Q, S]) Reset() {
+	s.cursor.Reset()
+}
+
+// IsAcceptingState checks if the given state is an accepting state.
+func (s *Session[Q, S]) IsAcceptingState(state Q) bool {
+	return s.cursor.machine.IsAcceptingState(state)
+}
+
+// IsCurrentStateAccepting checks if the current state is an accepting state.
+func (s *Session[Q, S]) IsCurrentStateAccepting() bool {
+	return s.cursor.Accepting()
+}
+
+// Step processes a single input symbol and transitions to the next state.
+func (s *Session[Q, S]) Step(symbol S) (Q, error) {
+	return s.cursor.Step(symbol)
+}
+
+// Transition returns the state reached from the given state on symbol
+// without changing the current state (implements StateTransitioner
+// interface).
+func (s *Session[Q, S]) Transition(state Q, symbol S) (Q, error) {
+	return s.cursor.machine.Transition(state, symbol)
+}
+
+// ProcessInput resets the session and processes a sequence of input symbols.
+func (s *Session[Q, S]) ProcessInput(input []S) (bool, error) {
+	_, accepted, err := s.ProcessInputWithTrace(input)
+	return accepted, err
+}
+
+// ProcessInputWithTrace resets the session, processes input and returns a
+// trace of state transitions.
+func (s *Session[Q, S]) ProcessInputWithTrace(input []S) ([]Q, bool, error) {
+	return s.ProcessInputWithTraceContext(context.Background(), input)
+}
+
+// ProcessInputContext is ProcessInput stopping with an InterruptedError as
+// soon as ctx is done.
+func (s *Session[Q, S]) ProcessInputContext(ctx context.Context, input []S) (bool, error) {
+	_, accepted, err := s.ProcessInputWithTraceContext(ctx, input)
+	return accepted, err
+}
+
+// ProcessInputWithTraceContext is ProcessInputWithTrace stopping with an
+// InterruptedError as soon as ctx is done.
+func (s *Session[Q, S]) ProcessInputWithTraceContext(ctx context.Context, input []S) ([]Q, bool, error) {
+	if err := s.cursor.machine.validateInput(input); err != nil {
+		return nil, false, err
+	}
+	trace, err := stepContext[Q, S](ctx, s, input)
+	if err != nil {
+		return trace, false, err
+	}
+	return trace, s.cursor.Accepting(), nil
+}
+
+// Validate always succeeds: compiling already resolved every state and
+// symbol the machine refers to.
+func (s *Session[Q, S]) Validate() error {
+	return nil
+}
+
+// String returns a summary of the machine and the current state.
+func (s *Session[Q, S]) String() string {
+	return fmt.Sprintf("%s  Current: %v\n", s.cursor.machine.String(), s.cursor.State())
+}
@@ -0,0 +1,206 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// randomInputs returns count inputs over the alphabet with lengths below maxLength
+func randomInputs(alphabet []rune, count, maxLength int) [][]rune {
+	rng := rand.New(rand.NewSource(1))
+	inputs := make([][]rune, count)
+	for i := range inputs {
+		input := make([]rune, rng.Intn(maxLength))
+		for j := range input {
+			input[j] = alphabet[rng.Intn(len(alphabet))]
+		}
+		inputs[i] = input
+	}
+	return inputs
+}
+
+// TestCompile tests that a compiled machine agrees with its automaton
+func TestCompile(t *testing.T) {
+	fa := newEndsWithZeroAutomaton()
+	machine, err := Compile[string, rune](fa)
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+
+	for _, input := range randomInputs([]rune("01"), 200, 12) {
+		wantTrace, wantAccepted, wantErr := fa.ProcessInputWithTrace(input)
+		trace, accepted, err := machine.ProcessInputWithTrace(input)
+		if accepted != wantAccepted || !reflect.DeepEqual(trace, wantTrace) || (err == nil) != (wantErr == nil) {
+			t.Fatalf("ProcessInputWithTrace(%q) = %v, %v, %v; want %v, %v, %v",
+				string(input), trace, accepted, err, wantTrace, wantAccepted, wantErr)
+		}
+		if accepted, _ := machine.ProcessInput(input); accepted != wantAccepted {
+			t.Fatalf("ProcessInput(%q) = %v, want %v", string(input), accepted, wantAccepted)
+		}
+	}
+
+	if machine.InitialState() != fa.GetInitialState() {
+		t.Errorf("InitialState() = %v, want %v", machine.InitialState(), fa.GetInitialState())
+	}
+	if _, err := Compile[StateSet[string], rune](newEndsWithABNFA()); err == nil {
+		t.Error("Expected error compiling an NFA")
+	}
+}
+
+// TestCompile_Snapshot tests that later changes to the automaton do not affect the machine
+func TestCompile_Snapshot(t *testing.T) {
+	fa := New[string, rune]("a").
+		AddStates("a", "b").
+		AddSymbols('x').
+		AddTransition("a", 'x', "b")
+	machine, err := Compile[string, rune](fa)
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+
+	fa.AddAcceptingState("b").AddTransition("b", 'x', "a")
+	if accepted, err := machine.ProcessInput([]rune("x")); accepted || err != nil {
+		t.Errorf("ProcessInput(x) = %v, %v; want false, nil", accepted, err)
+	}
+	if _, err := machine.ProcessInput([]rune("xx")); !IsTransitionError(err) {
+		t.Errorf("Expected transition error, got %v", err)
+	}
+}
+
+// TestCursor tests stepping, errors and forking by copying
+func TestCursor(t *testing.T) {
+	machine, err := Compile[string, rune](newParityAutomaton())
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+
+	cursor := machine.NewCursor()
+	if _, err := cursor.Step('1'); err != nil {
+		t.Fatalf("Step returned error: %v", err)
+	}
+	fork := cursor
+	if _, err := fork.Step('1'); err != nil {
+		t.Fatalf("Step returned error: %v", err)
+	}
+	if cursor.State() != "odd" || cursor.Accepting() || fork.State() != "even" || !fork.Accepting() {
+		t.Errorf("Copies should run independently: %s and %s", cursor.State(), fork.State())
+	}
+
+	_, err = cursor.Step('2')
+	var automatonErr *AutomatonError
+	if !errors.As(err, &automatonErr) || automatonErr.Type != ErrorTypeInvalidInput || cursor.State() != "odd" {
+		t.Errorf("Expected invalid input error without moving, got %v in %s", err, cursor.State())
+	}
+
+	cursor.Reset()
+	if cursor.State() != "even" || cursor.Machine() != machine {
+		t.Errorf("Reset should return to the initial state, got %s", cursor.State())
+	}
+}
+
+// TestSession tests that sessions work wherever an Automaton is expected
+func TestSession(t *testing.T) {
+	machine, err := Compile[string, rune](newParityAutomaton())
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+	var session ContextAutomaton[string, rune] = machine.NewSession()
+
+	result, err := NewStandardProcessor[string, rune]().Process(session, []rune("101"))
+	if err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if !result.Accepted || !reflect.DeepEqual(result.Trace, []string{"even", "odd", "odd", "even"}) || result.FinalState != "even" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if session.GetCurrentState() != "even" || !session.IsCurrentStateAccepting() || session.Validate() != nil {
+		t.Errorf("Unexpected session state %s", session.GetCurrentState())
+	}
+	if !session.IsAcceptingState("even") || session.IsAcceptingState("odd") || session.IsAcceptingState("missing") {
+		t.Error("IsAcceptingState returned wrong results")
+	}
+
+	if _, err := session.ProcessInput([]rune("1x")); !IsInvalidInputError(err) {
+		t.Errorf("Expected invalid input error, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := session.ProcessInputContext(ctx, []rune("1")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	debugger := NewDebugger[string, rune](session, '1')
+	if _, err := debugger.Step(); err != nil || debugger.State() != "odd" {
+		t.Errorf("Debugger on a session: %v in %s", err, debugger.State())
+	}
+}
+
+// TestMachine_Concurrent tests many goroutines sharing one machine
+func TestMachine_Concurrent(t *testing.T) {
+	fa := newEndsWithZeroAutomaton()
+	machine, err := Compile[string, rune](fa)
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+	inputs := randomInputs([]rune("01"), 50, 20)
+	want := make([]bool, len(inputs))
+	for i, input := range inputs {
+		want[i], _ = fa.ProcessInput(input)
+	}
+
+	var wg sync.WaitGroup
+	failures := make(chan string, 100)
+	for g := 0; g < 100; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			session := machine.NewSession()
+			for i, input := range inputs {
+				if accepted, _ := session.ProcessInput(input); accepted != want[i] {
+					failures <- fmt.Sprintf("goroutine %d: input %q accepted %v", g, string(input), accepted)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(failures)
+	for failure := range failures {
+		t.Error(failure)
+	}
+}
+
+// TestParallelProcessor_IndependentWorkers tests that batch traces are not interleaved
+func TestParallelProcessor_IndependentWorkers(t *testing.T) {
+	fa := newEndsWithZeroAutomaton()
+	inputs := randomInputs([]rune("01"), 300, 30)
+
+	results, err := NewParallelProcessor[string, rune](8).ProcessBatch(fa, inputs)
+	if err != nil {
+		t.Fatalf("ProcessBatch returned error: %v", err)
+	}
+	for i, input := range inputs {
+		trace, accepted, _ := fa.ProcessInputWithTrace(input)
+		if results[i].Accepted != accepted || !reflect.DeepEqual(results[i].Trace, trace) {
+			t.Fatalf("Input %q: got %+v, want trace %v", string(input), results[i], trace)
+		}
+	}
+
+	// Other automata are shared and processed one input at a time.
+	observable := NewObservableAutomaton[string, rune](fa)
+	results, err = NewParallelProcessor[string, rune](8).ProcessBatch(observable, inputs)
+	if err != nil {
+		t.Fatalf("ProcessBatch returned error: %v", err)
+	}
+	for i, input := range inputs {
+		trace, _, _ := fa.ProcessInputWithTrace(input)
+		if !reflect.DeepEqual(results[i].Trace, trace) {
+			t.Fatalf("Input %q: got trace %v, want %v", string(input), results[i].Trace, trace)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mermaidKeywords cannot be used as Mermaid state identifiers.
+var mermaidKeywords = []string{"state", "direction", "note", "end", "class", "classDef", "click", "style"}
+
+// ToMermaid renders an automaton as a Mermaid stateDiagram-v2 using DefaultDiagramOptions.
+func ToMermaid[Q State, S Symbol](automaton Automaton[Q, S]) (string, error) {
+	return ToMermaidWithOptions(automaton, DefaultDiagramOptions[Q, S]())
+}
+
+// ToMermaidWithOptions renders an automaton as a Mermaid stateDiagram-v2, for
+// embedding in Markdown documentation.
+//
+// The initial state is entered from [*] and accepting states have a
+// transition to [*]. Transitions between the same pair of states are merged
+// into one edge whose label lists their symbols. States whose name is not a
+// plain identifier are declared as `state "name" as sN`, and characters that
+// Mermaid would interpret in names and labels are written as entity codes
+// (#quot;, #59;, ...). The output is deterministic.
+//
+// As with ToDOTWithOptions, the automaton must be a *FiniteAutomaton or an
+// ObservableAutomaton wrapping one; an *NFA must be determinized first.
+func ToMermaidWithOptions[Q State, S Symbol](automaton Automaton[Q, S], options DiagramOptions[Q, S]) (string, error) {
+	d, err := newDiagram(automaton)
+	if err != nil {
+		return "", err
+	}
+	names, edgeLabel, err := options.labels(d)
+	if err != nil {
+		return "", err
+	}
+	ids := d.stateIDs(names, func(name string) bool {
+		return isDiagramIdentifier(name, mermaidKeywords...)
+	})
+
+	var sb strings.Builder
+	sb.WriteString("stateDiagram-v2\n")
+	if options.LeftToRight {
+		sb.WriteString("  direction LR\n")
+	}
+	for _, state := range d.states {
+		if ids[state] == names[state] {
+			fmt.Fprintf(&sb, "  %s\n", ids[state])
+		} else {
+			fmt.Fprintf(&sb, "  state \"%s\" as %s\n", escapeMermaid(names[state]), ids[state])
+		}
+	}
+	fmt.Fprintf(&sb, "  [*] --> %s\n", ids[d.initial])
+	for _, edge := range d.edges {
+		fmt.Fprintf(&sb, "  %s --> %s", ids[edge.from], ids[edge.to])
+		if label := edgeLabel(edge); label != "" {
+			fmt.Fprintf(&sb, " : %s", escapeMermaid(label))
+		}
+		sb.WriteString("\n")
+	}
+	for _, state := range d.states {
+		if d.accepting[state] {
+			fmt.Fprintf(&sb, "  %s --> [*]\n", ids[state])
+		}
+	}
+	return sb.String(), nil
+}
+
+// escapeMermaid replaces the characters that end or alter a Mermaid state
+// description or transition label with entity codes, and line breaks with <br>.
+func escapeMermaid(text string) string {
+	return strings.NewReplacer(
+		"#", "#35;",
+		`"`, "#quot;",
+		";", "#59;",
+		"<", "#lt;",
+		">", "#gt;",
+		"\r\n", "<br>",
+		"\n", "<br>",
+		"\r", "<br>",
+	).Replace(text)
+}
@@ -0,0 +1,99 @@
+package fsm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newDiagramAutomaton returns an automaton with names that need escaping
+func newDiagramAutomaton() *FiniteAutomaton[string, string] {
+	return New[string, string]("idle").
+		AddStates("idle", "in review", "s0", "end", "done").
+		AddSymbols("submit", "approve", "reject", `say "no"; #1`).
+		AddAcceptingState("done").
+		AddTransition("idle", "submit", "in review").
+		AddTransition("in review", "approve", "done").
+		AddTransition("in review", "reject", "end").
+		AddTransition("in review", `say "no"; #1`, "end")
+}
+
+// TestToMermaid tests the exact output including escaping and aliasing
+func TestToMermaid(t *testing.T) {
+	got, err := ToMermaid[string, string](newDiagramAutomaton())
+	if err != nil {
+		t.Fatalf("ToMermaid returned error: %v", err)
+	}
+
+	want := `stateDiagram-v2
+  direction LR
+  done
+  state "end" as s1
+  idle
+  state "in review" as s2
+  s0
+  [*] --> idle
+  idle --> s2 : submit
+  s2 --> done : approve
+  s2 --> s1 : reject, say #quot;no#quot;#59; #35;1
+  done --> [*]
+`
+	if got != want {
+		t.Errorf("ToMermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestToMermaidWithOptions tests direction and label options
+func TestToMermaidWithOptions(t *testing.T) {
+	options := DefaultDiagramOptions[string, rune]()
+	options.LeftToRight = false
+	options.SymbolSeparator = " / "
+	options.StateLabel = func(state string) string { return "<" + state + ">\nparity" }
+
+	fa := newParityAutomaton().AddTransition("odd", '2', "even").AddSymbol('2')
+	got, err := ToMermaidWithOptions[string, rune](fa, options)
+	if err != nil {
+		t.Fatalf("ToMermaidWithOptions returned error: %v", err)
+	}
+
+	for _, line := range []string{
+		`  state "#lt;even#gt;<br>parity" as s0`,
+		"  s1 --> s0 : 1 / 2",
+		"  s0 --> [*]",
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("Output is missing %q:\n%s", line, got)
+		}
+	}
+	if strings.Contains(got, "direction") {
+		t.Errorf("Output should not set a direction:\n%s", got)
+	}
+}
+
+// TestDiagrams_NFA tests that NFAs are rejected by the exporters and drawn once determinized
+func TestDiagrams_NFA(t *testing.T) {
+	nfa := newEndsWithABNFA()
+	exporters := map[string]func(Automaton[StateSet[string], rune]) (string, error){
+		"dot":      ToDOT[StateSet[string], rune],
+		"mermaid":  ToMermaid[StateSet[string], rune],
+		"plantuml": ToPlantUML[StateSet[string], rune],
+	}
+	for name, export := range exporters {
+		var automatonErr *AutomatonError
+		if _, err := export(nfa); !errors.As(err, &automatonErr) || automatonErr.Type != ErrorTypeInvalidConfiguration {
+			t.Errorf("%s: expected invalid configuration error for an NFA, got %v", name, err)
+		}
+	}
+
+	dfa, err := Determinize(nfa)
+	if err != nil {
+		t.Fatalf("Determinize returned error: %v", err)
+	}
+	got, err := ToMermaid[StateSet[string], rune](dfa)
+	if err != nil {
+		t.Fatalf("ToMermaid returned error: %v", err)
+	}
+	if !strings.Contains(got, `state "{q0, q1}" as`) {
+		t.Errorf("Expected the state sets to be drawn:\n%s", got)
+	}
+}
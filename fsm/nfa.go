@@ -0,0 +1,606 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// stateIndex assigns a stable position to every state of an NFA so that
+// sets of states can be encoded canonically as bitsets. The index is
+// append-only and has its own lock, because StateSets read it without holding
+// the mutex of their NFA.
+type stateIndex[Q State] struct {
+	mutex    sync.RWMutex
+	order    []Q
+	position map[Q]int
+}
+
+func newStateIndex[Q State]() *stateIndex[Q] {
+	return &stateIndex[Q]{
+		order:    make([]Q, 0),
+		position: make(map[Q]int),
+	}
+}
+
+// add registers a state and returns its position.
+func (idx *stateIndex[Q]) add(state Q) int {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	if pos, exists := idx.position[state]; exists {
+		return pos
+	}
+	pos := len(idx.order)
+	idx.order = append(idx.order, state)
+	idx.position[state] = pos
+	return pos
+}
+
+// lookup returns the position of a registered state.
+func (idx *stateIndex[Q]) lookup(state Q) (int, bool) {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	pos, exists := idx.position[state]
+	return pos, exists
+}
+
+// at returns the state registered at a position.
+func (idx *stateIndex[Q]) at(pos int) Q {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	return idx.order[pos]
+}
+
+// StateSet is an immutable set of NFA states.
+//
+// A StateSet is comparable, which allows it to be used as the state type of the
+// Automaton interface: two sets produced by the same NFA are equal (==) exactly
+// when they contain the same states. Sets belonging to different NFAs never
+// compare equal; use Equal to compare their contents.
+type StateSet[Q State] struct {
+	// bits is a canonical bitset over the owning index, with trailing zero bytes trimmed
+	bits  string
+	index *stateIndex[Q]
+}
+
+// newStateSet builds the canonical set of the given positions.
+func newStateSet[Q State](index *stateIndex[Q], positions map[int]bool) StateSet[Q] {
+	size := 0
+	for pos := range positions {
+		if pos/8+1 > size {
+			size = pos/8 + 1
+		}
+	}
+	bits := make([]byte, size)
+	for pos := range positions {
+		bits[pos/8] |= 1 << (pos % 8)
+	}
+	return StateSet[Q]{bits: string(bits), index: index}
+}
+
+// Contains reports whether the state is a member of the set.
+func (s StateSet[Q]) Contains(state Q) bool {
+	if s.index == nil {
+		return false
+	}
+	pos, exists := s.index.lookup(state)
+	if !exists || pos/8 >= len(s.bits) {
+		return false
+	}
+	return s.bits[pos/8]&(1<<(pos%8)) != 0
+}
+
+// Len returns the number of states in the set.
+func (s StateSet[Q]) Len() int {
+	count := 0
+	for i := 0; i < len(s.bits); i++ {
+		for b := s.bits[i]; b != 0; b &= b - 1 {
+			count++
+		}
+	}
+	return count
+}
+
+// IsEmpty reports whether the set has no members.
+func (s StateSet[Q]) IsEmpty() bool {
+	return len(s.bits) == 0
+}
+
+// States returns the members of the set in the order they were added to the NFA.
+func (s StateSet[Q]) States() []Q {
+	states := make([]Q, 0, s.Len())
+	for _, pos := range s.positions() {
+		states = append(states, s.index.at(pos))
+	}
+	return states
+}
+
+// Equal reports whether both sets contain the same states, regardless of
+// which NFA produced them.
+func (s StateSet[Q]) Equal(other StateSet[Q]) bool {
+	if s.index == other.index {
+		return s.bits == other.bits
+	}
+	if s.Len() != other.Len() {
+		return false
+	}
+	for _, state := range s.States() {
+		if !other.Contains(state) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the set in the form {q0, q1}.
+func (s StateSet[Q]) String() string {
+	parts := make([]string, 0, s.Len())
+	for _, state := range s.States() {
+		parts = append(parts, fmt.Sprintf("%v", state))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func (s StateSet[Q]) positions() []int {
+	positions := make([]int, 0)
+	for i := 0; i < len(s.bits); i++ {
+		for bit := 0; bit < 8; bit++ {
+			if s.bits[i]&(1<<bit) != 0 {
+				positions = append(positions, i*8+bit)
+			}
+		}
+	}
+	return positions
+}
+
+// NFA represents a nondeterministic finite automaton as a 5-tuple
+// (Q, Σ, q0, F, δ) where the transition function maps to sets of states:
+// δ: Q × (Σ ∪ {ε}) → P(Q).
+//
+// The NFA tracks every active branch at once, so its current "state" is the
+// set of states it may be in. Epsilon (ε) transitions consume no input: the
+// active set is always closed under them, starting with the ε-closure of q0.
+//
+// The NFA satisfies the Automaton interface with StateSet[Q] as the state
+// type; an input is accepted when any active state is accepting.
+//
+// Type parameters:
+//   - Q: The type used for states
+//   - S: The type used for input symbols
+type NFA[Q State, S Symbol] struct {
+	// Q: Set of states
+	states map[Q]bool
+
+	// Σ (Sigma): Input alphabet
+	alphabet map[S]bool
+
+	// q0: Initial state
+	initialState Q
+
+	// F: Set of accepting/final states
+	acceptingStates map[Q]bool
+
+	// δ (delta): Transition function Q × Σ → P(Q)
+	transitions map[Q]map[S]map[Q]bool
+
+	// ε-moves: Q → P(Q), taken without consuming input
+	epsilons map[Q]map[Q]bool
+
+	// Canonical positions of the states for StateSet encoding
+	index *stateIndex[Q]
+
+	// Current set of active states (for stateful processing); the zero set
+	// until the first Reset or Step, standing for the ε-closure of q0
+	currentStates StateSet[Q]
+
+	// Number of changes made to the definition, see Version
+	version uint64
+
+	// Thread safety
+	mutex sync.RWMutex
+}
+
+// NewNFA creates a new NFA with the specified initial state.
+// Use the builder methods to configure the automaton.
+func NewNFA[Q State, S Symbol](initialState Q) *NFA[Q, S] {
+	nfa := &NFA[Q, S]{
+		states:          make(map[Q]bool),
+		alphabet:        make(map[S]bool),
+		initialState:    initialState,
+		acceptingStates: make(map[Q]bool),
+		transitions:     make(map[Q]map[S]map[Q]bool),
+		epsilons:        make(map[Q]map[Q]bool),
+		index:           newStateIndex[Q](),
+	}
+	nfa.index.add(initialState)
+	return nfa
+}
+
+// AddState adds a state to the set Q.
+// Returns the automaton for method chaining.
+func (n *NFA[Q, S]) AddState(state Q) *NFA[Q, S] {
+	n.states[state] = true
+	n.index.add(state)
+	n.version++
+	return n
+}
+
+// AddStates adds multiple states to the set Q.
+// Returns the automaton for method chaining.
+func (n *NFA[Q, S]) AddStates(states ...Q) *NFA[Q, S] {
+	for _, state := range states {
+		n.AddState(state)
+	}
+	return n
+}
+
+// AddSymbol adds a symbol to the alphabet Σ.
+// Returns the automaton for method chaining.
+func (n *NFA[Q, S]) AddSymbol(symbol S) *NFA[Q, S] {
+	n.alphabet[symbol] = true
+	n.version++
+	return n
+}
+
+// AddSymbols adds multiple symbols to the alphabet Σ.
+// Returns the automaton for method chaining.
+func (n *NFA[Q, S]) AddSymbols(symbols ...S) *NFA[Q, S] {
+	for _, symbol := range symbols {
+		n.alphabet[symbol] = true
+	}
+	n.version++
+	return n
+}
+
+// AddAcceptingState adds a state to the set of accepting states F.
+// Returns the automaton for method chaining.
+func (n *NFA[Q, S]) AddAcceptingState(state Q) *NFA[Q, S] {
+	n.acceptingStates[state] = true
+	n.index.add(state)
+	n.version++
+	return n
+}
+
+// AddAcceptingStates adds multiple states to the set of accepting states F.
+// Returns the automaton for method chaining.
+func (n *NFA[Q, S]) AddAcceptingStates(states ...Q) *NFA[Q, S] {
+	for _, state := range states {
+		n.AddAcceptingState(state)
+	}
+	return n
+}
+
+// AddTransition adds toState to the set δ(fromState, symbol).
+// Unlike FiniteAutomaton.AddTransition, earlier targets are kept.
+// Returns the automaton for method chaining.
+func (n *NFA[Q, S]) AddTransition(fromState Q, symbol S, toState Q) *NFA[Q, S] {
+	if n.transitions[fromState] == nil {
+		n.transitions[fromState] = make(map[S]map[Q]bool)
+	}
+	if n.transitions[fromState][symbol] == nil {
+		n.transitions[fromState][symbol] = make(map[Q]bool)
+	}
+	n.transitions[fromState][symbol][toState] = true
+	n.index.add(fromState)
+	n.index.add(toState)
+	n.version++
+	return n
+}
+
+// AddEpsilonTransition adds an ε-move from fromState to toState.
+// Returns the automaton for method chaining.
+func (n *NFA[Q, S]) AddEpsilonTransition(fromState Q, toState Q) *NFA[Q, S] {
+	if n.epsilons[fromState] == nil {
+		n.epsilons[fromState] = make(map[Q]bool)
+	}
+	n.epsilons[fromState][toState] = true
+	n.index.add(fromState)
+	n.index.add(toState)
+	n.version++
+	return n
+}
+
+// HasEpsilonTransitions reports whether the automaton has any ε-moves.
+func (n *NFA[Q, S]) HasEpsilonTransitions() bool {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	return len(n.epsilons) > 0
+}
+
+// EpsilonClosure returns the set of states reachable from the given states
+// using only ε-moves, including the states themselves.
+// This method is thread-safe.
+func (n *NFA[Q, S]) EpsilonClosure(states ...Q) StateSet[Q] {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	positions := make(map[int]bool, len(states))
+	for _, state := range states {
+		positions[n.index.add(state)] = true
+	}
+	return n.closure(positions)
+}
+
+// NewStateSet returns the set of the given states in the canonical form used by
+// this NFA, so that it compares equal (==) to sets produced while processing input.
+func (n *NFA[Q, S]) NewStateSet(states ...Q) StateSet[Q] {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	positions := make(map[int]bool, len(states))
+	for _, state := range states {
+		positions[n.index.add(state)] = true
+	}
+	return newStateSet(n.index, positions)
+}
+
+// Version returns a counter incremented by every change to the definition.
+// This method is thread-safe.
+func (n *NFA[Q, S]) Version() uint64 {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	return n.version
+}
+
+// GetInitialState returns the ε-closure of the initial state q0.
+// This method is thread-safe.
+func (n *NFA[Q, S]) GetInitialState() StateSet[Q] {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	return n.initialStateSet()
+}
+
+// GetCurrentState returns the set of active states during processing.
+// This method is thread-safe.
+func (n *NFA[Q, S]) GetCurrentState() StateSet[Q] {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	return n.current()
+}
+
+// IsAcceptingState checks if the given set contains an accepting state.
+// This method is thread-safe.
+func (n *NFA[Q, S]) IsAcceptingState(state StateSet[Q]) bool {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	return n.containsAccepting(state)
+}
+
+// IsCurrentStateAccepting checks if any active state is an accepting state.
+// This method is thread-safe.
+func (n *NFA[Q, S]) IsCurrentStateAccepting() bool {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	return n.containsAccepting(n.current())
+}
+
+// Reset resets the automaton to the ε-closure of its initial state q0.
+// This method is thread-safe.
+func (n *NFA[Q, S]) Reset() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.currentStates = n.initialStateSet()
+}
+
+// Step processes a single input symbol on every active branch and follows
+// ε-moves from the states reached. Returns the new set of active states, or
+// an error if the symbol is not in the alphabet or no active state has a
+// transition for it. On error the active set is left unchanged.
+// This method is thread-safe.
+func (n *NFA[Q, S]) Step(symbol S) (StateSet[Q], error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	next, err := n.transition(n.current(), symbol)
+	if err != nil {
+		return next, err
+	}
+
+	n.currentStates = next
+	return n.currentStates, nil
+}
+
+// Transition returns the state set reached from the given set on symbol
+// without changing the current states (implements StateTransitioner
+// interface). The errors are those of Step.
+func (n *NFA[Q, S]) Transition(states StateSet[Q], symbol S) (StateSet[Q], error) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+	if states.index != n.index {
+		positions := make(map[int]bool)
+		for _, state := range states.States() {
+			if pos, exists := n.index.lookup(state); exists {
+				positions[pos] = true
+			}
+		}
+		states = newStateSet(n.index, positions)
+	}
+	return n.transition(states, symbol)
+}
+
+// transition computes the ε-closed move from a set of this automaton on
+// symbol. Callers must hold the mutex.
+func (n *NFA[Q, S]) transition(states StateSet[Q], symbol S) (StateSet[Q], error) {
+	if !n.alphabet[symbol] {
+		return StateSet[Q]{}, NewErrorWithContext(ErrorTypeInvalidInput,
+			fmt.Sprintf("symbol not in alphabet: %v", symbol), map[string]interface{}{"symbol": symbol})
+	}
+
+	next := n.move(states, symbol)
+	if next.IsEmpty() {
+		return StateSet[Q]{}, NewTransitionError(states, symbol, fmt.Sprintf(
+			"no transition defined for any of states %v with symbol %v", states, symbol))
+	}
+	return next, nil
+}
+
+// ProcessInput processes a sequence of input symbols.
+// Returns true if any branch ends in an accepting state, false otherwise.
+// Returns an error if every branch dies on an undefined transition.
+// This method is thread-safe and includes input validation.
+func (n *NFA[Q, S]) ProcessInput(input []S) (bool, error) {
+	_, accepted, err := n.ProcessInputWithTrace(input)
+	return accepted, err
+}
+
+// ProcessInputWithTrace processes input and returns the trace of active state sets.
+// Returns the trace and whether the input was accepted.
+// This method is thread-safe and includes input validation.
+func (n *NFA[Q, S]) ProcessInputWithTrace(input []S) ([]StateSet[Q], bool, error) {
+	if err := ValidateInputSequence(input, n.alphabet); err != nil {
+		return nil, false, err
+	}
+
+	n.Reset()
+	trace := []StateSet[Q]{n.GetCurrentState()}
+
+	for _, symbol := range input {
+		next, err := n.Step(symbol)
+		if err != nil {
+			return trace, false, err
+		}
+		trace = append(trace, next)
+	}
+
+	return trace, n.IsCurrentStateAccepting(), nil
+}
+
+// Validate checks if the automaton is properly configured.
+// Returns an error if the configuration is invalid.
+func (n *NFA[Q, S]) Validate() error {
+	if !n.states[n.initialState] {
+		return fmt.Errorf("initial state %v is not in the set of states Q", n.initialState)
+	}
+
+	for state := range n.acceptingStates {
+		if !n.states[state] {
+			return fmt.Errorf("accepting state %v is not in the set of states Q", state)
+		}
+	}
+
+	for fromState, transitions := range n.transitions {
+		if !n.states[fromState] {
+			return fmt.Errorf("transition from state %v, but state is not in Q", fromState)
+		}
+		for symbol, targets := range transitions {
+			if !n.alphabet[symbol] {
+				return fmt.Errorf("transition uses symbol %v, but symbol is not in Σ", symbol)
+			}
+			for toState := range targets {
+				if !n.states[toState] {
+					return fmt.Errorf("transition to state %v, but state is not in Q", toState)
+				}
+			}
+		}
+	}
+
+	for fromState, targets := range n.epsilons {
+		if !n.states[fromState] {
+			return fmt.Errorf("ε-transition from state %v, but state is not in Q", fromState)
+		}
+		for toState := range targets {
+			if !n.states[toState] {
+				return fmt.Errorf("ε-transition to state %v, but state is not in Q", toState)
+			}
+		}
+	}
+
+	return nil
+}
+
+// String returns a string representation of the automaton configuration.
+func (n *NFA[Q, S]) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("Nondeterministic Finite Automaton:\n")
+	sb.WriteString(fmt.Sprintf("  Q (States): %v\n", mapKeys(n.states)))
+	sb.WriteString(fmt.Sprintf("  Σ (Alphabet): %v\n", mapKeys(n.alphabet)))
+	sb.WriteString(fmt.Sprintf("  q0 (Initial): %v\n", n.initialState))
+	sb.WriteString(fmt.Sprintf("  F (Accepting): %v\n", mapKeys(n.acceptingStates)))
+	sb.WriteString("  δ (Transitions):\n")
+	for state, transitions := range n.transitions {
+		for symbol, targets := range transitions {
+			sb.WriteString(fmt.Sprintf("    δ(%v, %v) = %v\n", state, symbol, mapKeys(targets)))
+		}
+	}
+	for state, targets := range n.epsilons {
+		sb.WriteString(fmt.Sprintf("    δ(%v, ε) = %v\n", state, mapKeys(targets)))
+	}
+
+	return sb.String()
+}
+
+// current returns the set of active states. Before the first Reset or Step it
+// is the ε-closure of q0, computed on demand so that adding transitions never
+// recomputes it. Callers must hold the mutex.
+func (n *NFA[Q, S]) current() StateSet[Q] {
+	if n.currentStates.index == nil {
+		return n.initialStateSet()
+	}
+	return n.currentStates
+}
+
+// initialStateSet returns the ε-closure of {q0}. Callers must hold the mutex.
+func (n *NFA[Q, S]) initialStateSet() StateSet[Q] {
+	initial, _ := n.index.lookup(n.initialState)
+	return n.closure(map[int]bool{initial: true})
+}
+
+// move returns the ε-closure of the states reachable from any state in from on symbol.
+// Callers must hold the mutex.
+func (n *NFA[Q, S]) move(from StateSet[Q], symbol S) StateSet[Q] {
+	positions := make(map[int]bool)
+	for _, pos := range from.positions() {
+		for target := range n.transitions[n.index.at(pos)][symbol] {
+			targetPos, _ := n.index.lookup(target)
+			positions[targetPos] = true
+		}
+	}
+	return n.closure(positions)
+}
+
+// closure extends the given positions with every state reachable through ε-moves
+// and returns the resulting set. Callers must hold the mutex.
+func (n *NFA[Q, S]) closure(positions map[int]bool) StateSet[Q] {
+	stack := make([]int, 0, len(positions))
+	for pos := range positions {
+		stack = append(stack, pos)
+	}
+	for len(stack) > 0 {
+		pos := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for target := range n.epsilons[n.index.at(pos)] {
+			targetPos, _ := n.index.lookup(target)
+			if !positions[targetPos] {
+				positions[targetPos] = true
+				stack = append(stack, targetPos)
+			}
+		}
+	}
+	return newStateSet(n.index, positions)
+}
+
+// containsAccepting reports whether the set has an accepting member.
+// Callers must hold the mutex.
+func (n *NFA[Q, S]) containsAccepting(set StateSet[Q]) bool {
+	if set.index != n.index {
+		for _, state := range set.States() {
+			if n.acceptingStates[state] {
+				return true
+			}
+		}
+		return false
+	}
+	for _, pos := range set.positions() {
+		if n.acceptingStates[n.index.at(pos)] {
+			return true
+		}
+	}
+	return false
+}
+
+func mapKeys[K comparable, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
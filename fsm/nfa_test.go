@@ -0,0 +1,395 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// newEndsWithABNFA returns an NFA accepting strings over {a, b} that end in "ab"
+func newEndsWithABNFA() *NFA[string, rune] {
+	return NewNFABuilder[string, rune]("q0").
+		WithStates("q0", "q1", "q2").
+		WithAlphabet('a', 'b').
+		WithAcceptingStates("q2").
+		WithTransitions(
+			T("q0", 'a', "q0"),
+			T("q0", 'b', "q0"),
+			T("q0", 'a', "q1"),
+			T("q1", 'b', "q2"),
+		).
+		MustBuild()
+}
+
+// TestNFA_AddTransitionKeepsAllTargets tests that transitions accumulate instead of overwriting
+func TestNFA_AddTransitionKeepsAllTargets(t *testing.T) {
+	nfa := NewNFA[string, rune]("q0").
+		AddTransition("q0", 'a', "q0").
+		AddTransition("q0", 'a', "q1")
+
+	if len(nfa.transitions["q0"]['a']) != 2 {
+		t.Errorf("δ(q0, 'a') has %d targets, want 2", len(nfa.transitions["q0"]['a']))
+	}
+}
+
+// TestNFA_Step tests that Step tracks every active branch
+func TestNFA_Step(t *testing.T) {
+	nfa := newEndsWithABNFA()
+
+	states, err := nfa.Step('a')
+	if err != nil {
+		t.Fatalf("Step('a') returned error: %v", err)
+	}
+	if states != nfa.NewStateSet("q0", "q1") {
+		t.Errorf("Step('a') = %v, want {q0, q1}", states)
+	}
+
+	states, err = nfa.Step('b')
+	if err != nil {
+		t.Fatalf("Step('b') returned error: %v", err)
+	}
+	if !states.Contains("q0") || !states.Contains("q2") || states.Contains("q1") {
+		t.Errorf("Step('b') = %v, want {q0, q2}", states)
+	}
+	if !nfa.IsCurrentStateAccepting() {
+		t.Error("Current state set should be accepting after 'ab'")
+	}
+}
+
+// TestNFA_ProcessInput tests acceptance over all branches
+func TestNFA_ProcessInput(t *testing.T) {
+	nfa := newEndsWithABNFA()
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"ab", true},
+		{"aab", true},
+		{"babab", true},
+		{"", false},
+		{"a", false},
+		{"ba", false},
+		{"abb", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			accepted, err := nfa.ProcessInput([]rune(tt.input))
+			if err != nil {
+				t.Fatalf("ProcessInput(%q) returned error: %v", tt.input, err)
+			}
+			if accepted != tt.expected {
+				t.Errorf("ProcessInput(%q) = %v, want %v", tt.input, accepted, tt.expected)
+			}
+		})
+	}
+}
+
+// TestNFA_ProcessInputWithTrace tests the trace of active state sets
+func TestNFA_ProcessInputWithTrace(t *testing.T) {
+	nfa := newEndsWithABNFA()
+
+	trace, accepted, err := nfa.ProcessInputWithTrace([]rune("ab"))
+	if err != nil {
+		t.Fatalf("ProcessInputWithTrace returned error: %v", err)
+	}
+	if !accepted {
+		t.Error("Input 'ab' should be accepted")
+	}
+
+	expected := []StateSet[string]{
+		nfa.NewStateSet("q0"),
+		nfa.NewStateSet("q0", "q1"),
+		nfa.NewStateSet("q0", "q2"),
+	}
+	if len(trace) != len(expected) {
+		t.Fatalf("Trace length = %d, want %d", len(trace), len(expected))
+	}
+	for i := range expected {
+		if trace[i] != expected[i] {
+			t.Errorf("trace[%d] = %v, want %v", i, trace[i], expected[i])
+		}
+	}
+}
+
+// TestNFA_DeadBranches tests that an error is returned once every branch has died
+func TestNFA_DeadBranches(t *testing.T) {
+	nfa := NewNFABuilder[string, rune]("q0").
+		WithStates("q0", "q1").
+		WithAlphabet('a', 'b').
+		WithAcceptingStates("q1").
+		WithTransition("q0", 'a', "q1").
+		MustBuild()
+
+	_, err := nfa.ProcessInput([]rune("ab"))
+	if err == nil {
+		t.Fatal("ProcessInput should fail when no branch can consume the input")
+	}
+	if !IsTransitionError(err) {
+		t.Errorf("Expected transition error, got %v", err)
+	}
+	if nfa.GetCurrentState() != nfa.NewStateSet("q1") {
+		t.Errorf("Active states should be left unchanged on error, got %v", nfa.GetCurrentState())
+	}
+
+	_, err = nfa.ProcessInput([]rune("c"))
+	if !IsInvalidInputError(err) {
+		t.Errorf("Expected invalid input error for symbol outside Σ, got %v", err)
+	}
+}
+
+// TestNFA_Reset tests resetting to the initial state set
+func TestNFA_Reset(t *testing.T) {
+	nfa := newEndsWithABNFA()
+
+	if _, err := nfa.Step('a'); err != nil {
+		t.Fatalf("Step returned error: %v", err)
+	}
+	nfa.Reset()
+
+	if nfa.GetCurrentState() != nfa.GetInitialState() {
+		t.Errorf("After Reset current state = %v, want %v", nfa.GetCurrentState(), nfa.GetInitialState())
+	}
+	if nfa.GetInitialState().String() != "{q0}" {
+		t.Errorf("Initial state = %v, want {q0}", nfa.GetInitialState())
+	}
+}
+
+// TestStateSet_Equal tests set comparison across NFAs
+func TestStateSet_Equal(t *testing.T) {
+	first := newEndsWithABNFA()
+	second := NewNFA[string, rune]("q2").AddStates("q2", "q0")
+
+	a := first.NewStateSet("q0", "q2")
+	b := second.NewStateSet("q2", "q0")
+
+	if !a.Equal(b) {
+		t.Errorf("%v should equal %v", a, b)
+	}
+	if a.Equal(first.NewStateSet("q0")) {
+		t.Error("Sets with different members should not be equal")
+	}
+	if a.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", a.Len())
+	}
+	if !(StateSet[string]{}).IsEmpty() {
+		t.Error("Zero value StateSet should be empty")
+	}
+}
+
+// TestNFABuilder_ValidationErrors tests NFA construction validation
+func TestNFABuilder_ValidationErrors(t *testing.T) {
+	_, err := NewNFABuilder[string, rune]("q0").
+		WithStates("q0").
+		WithAlphabet('a').
+		WithTransition("q0", 'a', "q9").
+		Build()
+	if err == nil {
+		t.Error("Build should fail for transition to unknown state")
+	}
+
+	_, err = NewNFABuilderWithValidation[string, rune]("q0", StrictValidatorConfig()).
+		WithStates("q0", "q1").
+		WithAlphabet('a').
+		Build()
+	if err == nil {
+		t.Error("Strict Build should fail for unreachable state")
+	}
+}
+
+// TestNFA_EpsilonClosure tests that ε-moves are followed without consuming input
+func TestNFA_EpsilonClosure(t *testing.T) {
+	// Accepts a*b* : q0 loops on 'a', ε to q1 which loops on 'b'
+	nfa := NewNFABuilder[string, rune]("q0").
+		WithStates("q0", "q1").
+		WithAlphabet('a', 'b').
+		WithAcceptingStates("q1").
+		WithTransition("q0", 'a', "q0").
+		WithTransition("q1", 'b', "q1").
+		WithEpsilon("q0", "q1").
+		MustBuild()
+
+	if nfa.GetInitialState() != nfa.NewStateSet("q0", "q1") {
+		t.Errorf("Initial state = %v, want ε-closure {q0, q1}", nfa.GetInitialState())
+	}
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"", true},
+		{"aaa", true},
+		{"aabb", true},
+		{"bbb", true},
+	}
+	for _, tt := range tests {
+		accepted, err := nfa.ProcessInput([]rune(tt.input))
+		if err != nil {
+			t.Fatalf("ProcessInput(%q) returned error: %v", tt.input, err)
+		}
+		if accepted != tt.expected {
+			t.Errorf("ProcessInput(%q) = %v, want %v", tt.input, accepted, tt.expected)
+		}
+	}
+
+	if _, err := nfa.ProcessInput([]rune("ba")); !IsTransitionError(err) {
+		t.Errorf("ProcessInput(\"ba\") should fail with transition error, got %v", err)
+	}
+}
+
+// TestNFA_EpsilonChain tests closure through several ε-moves after a step
+func TestNFA_EpsilonChain(t *testing.T) {
+	nfa := NewNFA[int, rune](0).
+		AddStates(0, 1, 2, 3).
+		AddSymbols('x').
+		AddAcceptingState(3).
+		AddTransition(0, 'x', 1).
+		AddEpsilonTransition(1, 2).
+		AddEpsilonTransition(2, 3).
+		AddEpsilonTransition(3, 1)
+
+	states, err := nfa.Step('x')
+	if err != nil {
+		t.Fatalf("Step returned error: %v", err)
+	}
+	if states != nfa.EpsilonClosure(1) || states.Len() != 3 {
+		t.Errorf("Step('x') = %v, want {1, 2, 3}", states)
+	}
+	if !nfa.IsCurrentStateAccepting() {
+		t.Error("State set containing 3 should be accepting")
+	}
+}
+
+// TestNFA_AddEpsilonTransitionKeepsRun tests that adding ε-moves extends the
+// initial closure without resetting a run in progress
+func TestNFA_AddEpsilonTransitionKeepsRun(t *testing.T) {
+	nfa := NewNFA[int, rune](0).
+		AddStates(0, 1, 2).
+		AddSymbols('x').
+		AddTransition(0, 'x', 1).
+		AddEpsilonTransition(0, 2)
+	if states := nfa.GetCurrentState(); states != nfa.EpsilonClosure(0) || states.Len() != 2 {
+		t.Errorf("GetCurrentState() = %v, want {0, 2}", states)
+	}
+
+	if _, err := nfa.Step('x'); err != nil {
+		t.Fatalf("Step returned error: %v", err)
+	}
+	nfa.AddEpsilonTransition(2, 0)
+	if states := nfa.GetCurrentState(); states != nfa.NewStateSet(1) {
+		t.Errorf("AddEpsilonTransition reset the run to %v, want {1}", states)
+	}
+	nfa.Reset()
+	if states := nfa.GetCurrentState(); states != nfa.EpsilonClosure(0) {
+		t.Errorf("Reset() = %v, want {0, 2}", states)
+	}
+}
+
+// TestBuilder_WithEpsilon tests converting a deterministic builder into an NFA builder
+func TestBuilder_WithEpsilon(t *testing.T) {
+	builder := NewBuilder[string, rune]("start")
+	builder.WithStates("start", "a1", "b0", "b1").
+		WithAlphabet('a', 'b').
+		WithAcceptingStates("b1").
+		WithTransitions(
+			T("start", 'a', "a1"),
+			T("b0", 'b', "b1"),
+		)
+
+	// "a" then "b", stitched with an ε-move
+	nfa := builder.WithEpsilon("a1", "b0").MustBuild()
+
+	accepted, err := nfa.ProcessInput([]rune("ab"))
+	if err != nil {
+		t.Fatalf("ProcessInput returned error: %v", err)
+	}
+	if !accepted {
+		t.Error("Stitched automaton should accept \"ab\"")
+	}
+	if !nfa.HasEpsilonTransitions() {
+		t.Error("HasEpsilonTransitions() should be true")
+	}
+}
+
+// TestNFAValidator_EpsilonReachability tests that strict reachability follows ε-moves
+func TestNFAValidator_EpsilonReachability(t *testing.T) {
+	_, err := NewNFABuilderWithValidation[string, rune]("q0", StrictValidatorConfig()).
+		WithStates("q0", "q1", "q2").
+		WithAlphabet('a').
+		WithAcceptingStates("q2").
+		WithEpsilon("q0", "q1").
+		WithTransition("q1", 'a', "q2").
+		Build()
+	if err != nil {
+		t.Errorf("States reachable through ε-moves should pass strict validation: %v", err)
+	}
+
+	_, err = NewNFABuilder[string, rune]("q0").
+		WithStates("q0").
+		WithAlphabet('a').
+		WithEpsilon("q0", "missing").
+		Build()
+	if err == nil {
+		t.Error("Build should fail for ε-transition to unknown state")
+	}
+}
+
+// TestNFAValidator_Context tests that NFA validation errors carry the same context as FiniteAutomaton ones
+func TestNFAValidator_Context(t *testing.T) {
+	config := DefaultValidatorConfig()
+	config.MaxStates = 2
+	tests := []struct {
+		name string
+		nfa  *NFA[string, rune]
+		key  string
+		want interface{}
+	}{
+		{"no states", NewNFA[string, rune]("q0").AddSymbol('a'), "field", "states"},
+		{"initial state", NewNFA[string, rune]("q0").AddStates("q1").AddSymbol('a'), "field", "initialState"},
+		{"target", NewNFA[string, rune]("q0").AddStates("q0").AddSymbol('a').AddTransition("q0", 'a', "q9"), "state", "q9"},
+		{"ε target", NewNFA[string, rune]("q0").AddStates("q0").AddSymbol('a').AddEpsilonTransition("q0", "q8"), "state", "q8"},
+		{"symbol", NewNFA[string, rune]("q0").AddStates("q0").AddSymbol('a').AddTransition("q0", 'b', "q0"), "symbol", 'b'},
+		{"too many states", NewNFA[string, rune]("q0").AddStates("q0", "q1", "q2").AddSymbol('a'), "field", "states"},
+	}
+	for _, tt := range tests {
+		err := NewNFAValidator[string, rune](config).Validate(tt.nfa)
+		var collector *ErrorCollector
+		if !errors.As(err, &collector) {
+			t.Fatalf("%s: expected validation errors, got %v", tt.name, err)
+		}
+		automatonErr, ok := collector.Errors()[0].(*AutomatonError)
+		if !ok || automatonErr.Context[tt.key] != tt.want {
+			t.Errorf("%s: expected %s %v in the context, got %v", tt.name, tt.key, tt.want, collector.Errors()[0])
+		}
+	}
+}
+
+// TestStateSet_ConcurrentIndex tests reading sets while unknown states are registered (run with -race)
+func TestStateSet_ConcurrentIndex(t *testing.T) {
+	nfa := newEndsWithABNFA()
+	set := nfa.NewStateSet("q0", "q1")
+	initial := nfa.GetInitialState()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 2000; i++ {
+			nfa.NewStateSet(fmt.Sprintf("new%d", i))
+			nfa.EpsilonClosure(fmt.Sprintf("closure%d", i))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 2000; i++ {
+			if !set.Contains("q1") || set.Contains(fmt.Sprintf("new%d", i)) || len(set.States()) != 2 {
+				t.Errorf("Unexpected members %v", set)
+				return
+			}
+			set.Equal(initial)
+		}
+	}()
+	wg.Wait()
+}
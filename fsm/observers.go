@@ -367,6 +367,15 @@ func (oa *ObservableAutomaton[Q, S]) ProcessInputWithTrace(input []S) ([]Q, bool
 	return trace, accepted, err
 }
 
+// Version returns the version of the wrapped automaton, or 0 when it is not
+// Versioned.
+func (oa *ObservableAutomaton[Q, S]) Version() uint64 {
+	if versioned, ok := oa.automaton.(Versioned); ok {
+		return versioned.Version()
+	}
+	return 0
+}
+
 // Validate validates the wrapped automaton.
 func (oa *ObservableAutomaton[Q, S]) Validate() error {
 	return oa.automaton.Validate()
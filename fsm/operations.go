@@ -0,0 +1,381 @@
+package fsm
+
+import (
+	"fmt"
+)
+
+// ProductState is a state of a product automaton: the pair of states the two
+// operands are in. A side whose run has left its automaton (undefined transition
+// or symbol outside its alphabet) is marked dead, has a zero state, and rejects
+// every extension of the input.
+type ProductState[Q State] struct {
+	Left      Q
+	Right     Q
+	LeftDead  bool
+	RightDead bool
+}
+
+// String returns the pair in the form (left, right), with ∅ for a dead side.
+func (p ProductState[Q]) String() string {
+	left, right := fmt.Sprintf("%v", p.Left), fmt.Sprintf("%v", p.Right)
+	if p.LeftDead {
+		left = "∅"
+	}
+	if p.RightDead {
+		right = "∅"
+	}
+	return "(" + left + ", " + right + ")"
+}
+
+// Product builds the product of two automata over the union of their alphabets.
+// A pair state is accepting when accept returns true for the acceptance of its
+// two sides. Only pairs reachable from the pair of initial states are created,
+// and the pair where both sides are dead is omitted, so inputs rejected by both
+// operands for every extension fail with an undefined transition.
+func Product[Q State, S Symbol](
+	a, b Automaton[Q, S],
+	accept func(leftAccepts, rightAccepts bool) bool,
+) (*FiniteAutomaton[ProductState[Q], S], error) {
+	left, err := finiteAutomatonOf(a)
+	if err != nil {
+		return nil, err
+	}
+	right, err := finiteAutomatonOf(b)
+	if err != nil {
+		return nil, err
+	}
+	if err := left.Validate(); err != nil {
+		return nil, NewErrorWithCause(ErrorTypeValidation, "invalid left operand", err)
+	}
+	if err := right.Validate(); err != nil {
+		return nil, NewErrorWithCause(ErrorTypeValidation, "invalid right operand", err)
+	}
+
+	left.mutex.RLock()
+	defer left.mutex.RUnlock()
+	if right != left {
+		right.mutex.RLock()
+		defer right.mutex.RUnlock()
+	}
+
+	symbols := unionAlphabet(left, right)
+	start := ProductState[Q]{Left: left.initialState, Right: right.initialState}
+
+	product := New[ProductState[Q], S](start)
+	product.AddSymbols(symbols...)
+	product.AddState(start)
+
+	queue := []ProductState[Q]{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if accept(productAccepts(left, right, current)) {
+			product.AddAcceptingState(current)
+		}
+
+		for _, symbol := range symbols {
+			next := productStep(left, right, current, symbol)
+			if next.LeftDead && next.RightDead {
+				continue
+			}
+			if !product.states[next] {
+				product.AddState(next)
+				queue = append(queue, next)
+			}
+			product.AddTransition(current, symbol, next)
+		}
+	}
+
+	return product, nil
+}
+
+// Intersection returns an automaton accepting the inputs accepted by both a and b.
+func Intersection[Q State, S Symbol](a, b Automaton[Q, S]) (*FiniteAutomaton[ProductState[Q], S], error) {
+	return Product(a, b, func(leftAccepts, rightAccepts bool) bool {
+		return leftAccepts && rightAccepts
+	})
+}
+
+// Union returns an automaton accepting the inputs accepted by a or b.
+func Union[Q State, S Symbol](a, b Automaton[Q, S]) (*FiniteAutomaton[ProductState[Q], S], error) {
+	return Product(a, b, func(leftAccepts, rightAccepts bool) bool {
+		return leftAccepts || rightAccepts
+	})
+}
+
+// Difference returns an automaton accepting the inputs accepted by a but not by b.
+func Difference[Q State, S Symbol](a, b Automaton[Q, S]) (*FiniteAutomaton[ProductState[Q], S], error) {
+	return Product(a, b, func(leftAccepts, rightAccepts bool) bool {
+		return leftAccepts && !rightAccepts
+	})
+}
+
+// SymmetricDifference returns an automaton accepting the inputs accepted by
+// exactly one of a and b.
+func SymmetricDifference[Q State, S Symbol](a, b Automaton[Q, S]) (*FiniteAutomaton[ProductState[Q], S], error) {
+	return Product(a, b, func(leftAccepts, rightAccepts bool) bool {
+		return leftAccepts != rightAccepts
+	})
+}
+
+// Complete returns a copy of the automaton whose transition function is total:
+// every missing δ(q, σ) is routed to the given non-accepting sink state, which
+// loops on every symbol. The result satisfies the RequireCompleteTransitions
+// validation rule. The sink must not already be a state of the automaton.
+func Complete[Q State, S Symbol](automaton Automaton[Q, S], sink Q) (*FiniteAutomaton[Q, S], error) {
+	fa, err := finiteAutomatonOf(automaton)
+	if err != nil {
+		return nil, err
+	}
+	if err := fa.Validate(); err != nil {
+		return nil, NewErrorWithCause(ErrorTypeValidation, "cannot complete invalid automaton", err)
+	}
+
+	fa.mutex.RLock()
+	defer fa.mutex.RUnlock()
+
+	if fa.states[sink] {
+		return nil, NewInvalidConfigurationError("sink",
+			fmt.Sprintf("sink state %v is already a state of the automaton", sink))
+	}
+
+	completed := fa.clone()
+	sinkUsed := false
+	for state := range fa.states {
+		for symbol := range fa.alphabet {
+			if _, exists := fa.transitions[state][symbol]; !exists {
+				completed.AddTransition(state, symbol, sink)
+				sinkUsed = true
+			}
+		}
+	}
+	if sinkUsed {
+		completed.AddState(sink)
+		for symbol := range fa.alphabet {
+			completed.AddTransition(sink, symbol, sink)
+		}
+	}
+
+	return completed, nil
+}
+
+// Complement returns an automaton accepting exactly the inputs over Σ that the
+// given automaton rejects. The transition function is first completed with the
+// sink state (see Complete), then accepting and non-accepting states are swapped.
+func Complement[Q State, S Symbol](automaton Automaton[Q, S], sink Q) (*FiniteAutomaton[Q, S], error) {
+	completed, err := Complete(automaton, sink)
+	if err != nil {
+		return nil, err
+	}
+	// Ensure the sink exists even if the automaton was already complete, so
+	// callers can rely on it being part of the result
+	completed.AddState(sink)
+	for symbol := range completed.alphabet {
+		completed.AddTransition(sink, symbol, sink)
+	}
+
+	accepting := make(map[Q]bool, len(completed.states))
+	for state := range completed.states {
+		if !completed.acceptingStates[state] {
+			accepting[state] = true
+		}
+	}
+	completed.acceptingStates = accepting
+
+	return completed, nil
+}
+
+// productStep advances both sides of a pair state on a symbol.
+// Callers must hold both mutexes.
+func productStep[Q State, S Symbol](
+	left, right *FiniteAutomaton[Q, S],
+	current ProductState[Q],
+	symbol S,
+) ProductState[Q] {
+	next := ProductState[Q]{LeftDead: true, RightDead: true}
+	if !current.LeftDead {
+		if target, ok := left.step(current.Left, symbol); ok {
+			next.Left, next.LeftDead = target, false
+		}
+	}
+	if !current.RightDead {
+		if target, ok := right.step(current.Right, symbol); ok {
+			next.Right, next.RightDead = target, false
+		}
+	}
+	return next
+}
+
+// productAccepts reports whether each side of a pair state is accepting.
+// Callers must hold both mutexes.
+func productAccepts[Q State, S Symbol](left, right *FiniteAutomaton[Q, S], p ProductState[Q]) (bool, bool) {
+	return !p.LeftDead && left.acceptingStates[p.Left], !p.RightDead && right.acceptingStates[p.Right]
+}
+
+// clone returns a deep copy of the automaton's definition, reset to q0.
+// Callers must hold the mutex.
+func (fa *FiniteAutomaton[Q, S]) clone() *FiniteAutomaton[Q, S] {
+	copied := New[Q, S](fa.initialState)
+	for state := range fa.states {
+		copied.AddState(state)
+	}
+	for symbol := range fa.alphabet {
+		copied.AddSymbol(symbol)
+	}
+	for state := range fa.acceptingStates {
+		copied.AddAcceptingState(state)
+	}
+	for from, transitions := range fa.transitions {
+		for symbol, to := range transitions {
+			copied.AddTransition(from, symbol, to)
+		}
+	}
+	return copied
+}
+
+// Regular operations build an ε-NFA from their operands and determinize it with
+// the subset construction. The results number their states 0, 1, 2, ... in
+// breadth-first order from the initial state 0; use Minimize to shrink them.
+
+// Concatenate returns an automaton accepting every input xy where x is accepted
+// by a and y is accepted by b ("a then b").
+func Concatenate[Q State, S Symbol](a, b *FiniteAutomaton[Q, S]) (*FiniteAutomaton[int, S], error) {
+	nfa := NewNFA[int, S](0)
+	first, err := embedAutomaton(nfa, a, false)
+	if err != nil {
+		return nil, err
+	}
+	second, err := embedAutomaton(nfa, b, false)
+	if err != nil {
+		return nil, err
+	}
+
+	nfa.initialState = first.start
+	for _, state := range first.accepting {
+		nfa.AddEpsilonTransition(state, second.start)
+	}
+	nfa.AddAcceptingStates(second.accepting...)
+
+	return NewNumberedSubsetConverter[int, S]().Determinize(nfa)
+}
+
+// KleeneStar returns an automaton accepting zero or more repetitions of inputs
+// accepted by a, including the empty input.
+func KleeneStar[Q State, S Symbol](a *FiniteAutomaton[Q, S]) (*FiniteAutomaton[int, S], error) {
+	nfa := NewNFA[int, S](0)
+	inner, err := embedAutomaton(nfa, a, false)
+	if err != nil {
+		return nil, err
+	}
+
+	start := inner.next
+	nfa.AddState(start).AddAcceptingState(start)
+	nfa.initialState = start
+	nfa.AddEpsilonTransition(start, inner.start)
+	for _, state := range inner.accepting {
+		nfa.AddEpsilonTransition(state, start)
+	}
+
+	return NewNumberedSubsetConverter[int, S]().Determinize(nfa)
+}
+
+// KleenePlus returns an automaton accepting one or more repetitions of inputs
+// accepted by a.
+func KleenePlus[Q State, S Symbol](a *FiniteAutomaton[Q, S]) (*FiniteAutomaton[int, S], error) {
+	nfa := NewNFA[int, S](0)
+	inner, err := embedAutomaton(nfa, a, false)
+	if err != nil {
+		return nil, err
+	}
+
+	nfa.initialState = inner.start
+	nfa.AddAcceptingStates(inner.accepting...)
+	for _, state := range inner.accepting {
+		nfa.AddEpsilonTransition(state, inner.start)
+	}
+
+	return NewNumberedSubsetConverter[int, S]().Determinize(nfa)
+}
+
+// Optional returns an automaton accepting the empty input and every input
+// accepted by a.
+func Optional[Q State, S Symbol](a *FiniteAutomaton[Q, S]) (*FiniteAutomaton[int, S], error) {
+	nfa := NewNFA[int, S](0)
+	inner, err := embedAutomaton(nfa, a, false)
+	if err != nil {
+		return nil, err
+	}
+
+	start := inner.next
+	nfa.AddState(start).AddAcceptingState(start)
+	nfa.initialState = start
+	nfa.AddEpsilonTransition(start, inner.start)
+	nfa.AddAcceptingStates(inner.accepting...)
+
+	return NewNumberedSubsetConverter[int, S]().Determinize(nfa)
+}
+
+// Reverse returns an automaton accepting the reversal of every input accepted by a.
+func Reverse[Q State, S Symbol](a *FiniteAutomaton[Q, S]) (*FiniteAutomaton[int, S], error) {
+	nfa := NewNFA[int, S](0)
+	inner, err := embedAutomaton(nfa, a, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start from every original accepting state at once and accept in q0
+	start := inner.next
+	nfa.AddState(start)
+	nfa.initialState = start
+	for _, state := range inner.accepting {
+		nfa.AddEpsilonTransition(start, state)
+	}
+	nfa.AddAcceptingState(inner.start)
+
+	return NewNumberedSubsetConverter[int, S]().Determinize(nfa)
+}
+
+// embedding records where an operand was copied into an ε-NFA.
+type embedding struct {
+	start     int
+	accepting []int
+	// next is the first state number not used by the embedding
+	next int
+}
+
+// embedAutomaton copies the operand into the NFA with fresh integer states
+// numbered after those already present, optionally reversing every transition.
+// Accepting states are reported but not marked as accepting in the NFA.
+func embedAutomaton[Q State, S Symbol](nfa *NFA[int, S], fa *FiniteAutomaton[Q, S], reversed bool) (embedding, error) {
+	if err := fa.Validate(); err != nil {
+		return embedding{}, NewErrorWithCause(ErrorTypeValidation, "invalid operand", err)
+	}
+
+	fa.mutex.RLock()
+	defer fa.mutex.RUnlock()
+
+	offset := len(nfa.states)
+	number := make(map[Q]int, len(fa.states))
+	for i, state := range sortValues(fa.getStatesList()) {
+		number[state] = offset + i
+		nfa.AddState(offset + i)
+	}
+	nfa.AddSymbols(fa.getAlphabetList()...)
+
+	for from, transitions := range fa.transitions {
+		for symbol, to := range transitions {
+			if reversed {
+				nfa.AddTransition(number[to], symbol, number[from])
+			} else {
+				nfa.AddTransition(number[from], symbol, number[to])
+			}
+		}
+	}
+
+	result := embedding{start: number[fa.initialState], next: offset + len(fa.states)}
+	for _, state := range sortValues(fa.getAcceptingStatesList()) {
+		result.accepting = append(result.accepting, number[state])
+	}
+	return result, nil
+}
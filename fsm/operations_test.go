@@ -0,0 +1,253 @@
+package fsm
+
+import (
+	"testing"
+)
+
+// newEndsWithZeroAutomaton accepts binary strings ending in '0'
+func newEndsWithZeroAutomaton() *FiniteAutomaton[string, rune] {
+	return New[string, rune]("other").
+		AddStates("other", "zero").
+		AddSymbols('0', '1').
+		AddAcceptingState("zero").
+		AddTransition("other", '0', "zero").
+		AddTransition("other", '1', "other").
+		AddTransition("zero", '0', "zero").
+		AddTransition("zero", '1', "other")
+}
+
+// binaryWords returns every binary string up to the given length
+func binaryWords(maxLength int) []string {
+	words := []string{""}
+	for start := 0; start < len(words); start++ {
+		if len(words[start]) < maxLength {
+			words = append(words, words[start]+"0", words[start]+"1")
+		}
+	}
+	return words
+}
+
+// accepts reports whether the automaton accepts the input, treating errors as rejection
+func accepts[Q State](t *testing.T, automaton Automaton[Q, rune], input string) bool {
+	t.Helper()
+	accepted, err := automaton.ProcessInput([]rune(input))
+	return err == nil && accepted
+}
+
+// TestBooleanOperations tests the product constructions against the operands
+func TestBooleanOperations(t *testing.T) {
+	parity := newParityAutomaton()
+	endsWithZero := newEndsWithZeroAutomaton()
+
+	operations := []struct {
+		name    string
+		build   func(a, b Automaton[string, rune]) (*FiniteAutomaton[ProductState[string], rune], error)
+		combine func(x, y bool) bool
+	}{
+		{"Intersection", Intersection[string, rune], func(x, y bool) bool { return x && y }},
+		{"Union", Union[string, rune], func(x, y bool) bool { return x || y }},
+		{"Difference", Difference[string, rune], func(x, y bool) bool { return x && !y }},
+		{"SymmetricDifference", SymmetricDifference[string, rune], func(x, y bool) bool { return x != y }},
+	}
+
+	for _, op := range operations {
+		t.Run(op.name, func(t *testing.T) {
+			product, err := op.build(parity, endsWithZero)
+			if err != nil {
+				t.Fatalf("%s returned error: %v", op.name, err)
+			}
+			for _, word := range binaryWords(6) {
+				want := op.combine(accepts(t, parity, word), accepts(t, endsWithZero, word))
+				if got := accepts(t, product, word); got != want {
+					t.Errorf("%s accepts %q = %v, want %v", op.name, word, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestProduct_PartialOperands tests products where one side dies
+func TestProduct_PartialOperands(t *testing.T) {
+	// Accepts only "1"
+	single := New[string, rune]("s").
+		AddStates("s", "f").
+		AddSymbols('1').
+		AddAcceptingState("f").
+		AddTransition("s", '1', "f")
+
+	union, err := Union[string, rune](single, newEndsWithZeroAutomaton())
+	if err != nil {
+		t.Fatalf("Union returned error: %v", err)
+	}
+	for word, want := range map[string]bool{"1": true, "10": true, "11": false, "0": true, "": false} {
+		if got := accepts(t, union, word); got != want {
+			t.Errorf("Union accepts %q = %v, want %v", word, got, want)
+		}
+	}
+
+	initial := union.GetInitialState()
+	if initial.String() != "(s, other)" {
+		t.Errorf("Initial product state = %v, want (s, other)", initial)
+	}
+}
+
+// TestComplement tests complementation of a partial automaton
+func TestComplement(t *testing.T) {
+	// Accepts exactly "01"; everything else is undefined or rejected
+	partial := New[string, rune]("q0").
+		AddStates("q0", "q1", "q2").
+		AddSymbols('0', '1').
+		AddAcceptingState("q2").
+		AddTransition("q0", '0', "q1").
+		AddTransition("q1", '1', "q2")
+
+	complement, err := Complement[string, rune](partial, "sink")
+	if err != nil {
+		t.Fatalf("Complement returned error: %v", err)
+	}
+
+	for _, word := range binaryWords(5) {
+		want := !accepts(t, partial, word)
+		accepted, err := complement.ProcessInput([]rune(word))
+		if err != nil {
+			t.Fatalf("Complement ProcessInput(%q) returned error: %v", word, err)
+		}
+		if accepted != want {
+			t.Errorf("Complement accepts %q = %v, want %v", word, accepted, want)
+		}
+	}
+
+	config := DefaultValidatorConfig()
+	config.RequireCompleteTransitions = true
+	if err := NewInputValidator[string, rune](config).Validate(complement); err != nil {
+		t.Errorf("Complement should have a complete transition function: %v", err)
+	}
+
+	if _, err := Complement[string, rune](partial, "q1"); err == nil {
+		t.Error("Complement should reject a sink that is already a state")
+	}
+}
+
+// TestComplete_IntegerStates tests completeness with the zero value as a real state
+func TestComplete_IntegerStates(t *testing.T) {
+	fa := New[int, rune](0).
+		AddStates(0, 1).
+		AddSymbols('a').
+		AddTransition(1, 'a', 0)
+
+	completed, err := Complete[int, rune](fa, -1)
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	config := DefaultValidatorConfig()
+	config.RequireCompleteTransitions = true
+	if err := NewInputValidator[int, rune](config).Validate(completed); err != nil {
+		t.Errorf("Completed automaton should pass completeness validation: %v", err)
+	}
+	if completed.transitions[0]['a'] != -1 {
+		t.Errorf("δ(0, 'a') = %v, want sink -1", completed.transitions[0]['a'])
+	}
+	if completed.transitions[1]['a'] != 0 {
+		t.Errorf("Existing transition δ(1, 'a') = %v, want 0", completed.transitions[1]['a'])
+	}
+}
+
+// newLiteralAutomaton accepts exactly the given binary string
+func newLiteralAutomaton(literal string) *FiniteAutomaton[int, rune] {
+	fa := New[int, rune](0).AddStates(0).AddSymbols('0', '1').AddAcceptingState(len(literal))
+	for i, symbol := range literal {
+		fa.AddState(i+1).AddTransition(i, symbol, i+1)
+	}
+	return fa
+}
+
+// TestRegularOperations tests concatenation, star, plus, optional and reversal
+func TestRegularOperations(t *testing.T) {
+	one := newLiteralAutomaton("1")
+	zeroOne := newLiteralAutomaton("01")
+
+	concatenated, err := Concatenate(one, zeroOne)
+	if err != nil {
+		t.Fatalf("Concatenate returned error: %v", err)
+	}
+	star, err := KleeneStar(zeroOne)
+	if err != nil {
+		t.Fatalf("KleeneStar returned error: %v", err)
+	}
+	plus, err := KleenePlus(zeroOne)
+	if err != nil {
+		t.Fatalf("KleenePlus returned error: %v", err)
+	}
+	optional, err := Optional(one)
+	if err != nil {
+		t.Fatalf("Optional returned error: %v", err)
+	}
+	reversed, err := Reverse(newLiteralAutomaton("110"))
+	if err != nil {
+		t.Fatalf("Reverse returned error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		automaton *FiniteAutomaton[int, rune]
+		accepted  []string
+	}{
+		{"Concatenate", concatenated, []string{"101"}},
+		{"KleeneStar", star, []string{"", "01", "0101", "010101"}},
+		{"KleenePlus", plus, []string{"01", "0101", "010101"}},
+		{"Optional", optional, []string{"", "1"}},
+		{"Reverse", reversed, []string{"011"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := make(map[string]bool)
+			for _, word := range tt.accepted {
+				want[word] = true
+			}
+			for _, word := range binaryWords(6) {
+				if got := accepts(t, tt.automaton, word); got != want[word] {
+					t.Errorf("%s accepts %q = %v, want %v", tt.name, word, got, want[word])
+				}
+			}
+			if tt.automaton.GetInitialState() != 0 {
+				t.Errorf("Initial state = %d, want 0", tt.automaton.GetInitialState())
+			}
+		})
+	}
+}
+
+// TestRegularOperations_Closure tests operations on a language closed under them
+func TestRegularOperations_Closure(t *testing.T) {
+	parity := newParityAutomaton()
+
+	// Even parity is closed under concatenation and star
+	concatenated, err := Concatenate(parity, parity)
+	if err != nil {
+		t.Fatalf("Concatenate returned error: %v", err)
+	}
+	star, err := KleeneStar(parity)
+	if err != nil {
+		t.Fatalf("KleeneStar returned error: %v", err)
+	}
+
+	for name, automaton := range map[string]*FiniteAutomaton[int, rune]{"concatenated": concatenated, "star": star} {
+		for _, word := range binaryWords(6) {
+			if got, want := accepts(t, automaton, word), accepts(t, parity, word); got != want {
+				t.Errorf("%s accepts %q = %v, want %v", name, word, got, want)
+			}
+		}
+	}
+
+	reversed, err := Reverse(parity)
+	if err != nil {
+		t.Fatalf("Reverse returned error: %v", err)
+	}
+	if _, err := Reverse(New[string, rune]("q0")); err == nil {
+		t.Error("Reverse should fail for invalid operand")
+	}
+	if accepts(t, reversed, "1") || !accepts(t, reversed, "1001") {
+		t.Error("Reversed parity automaton should accept exactly the even-parity inputs")
+	}
+}
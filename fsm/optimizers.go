@@ -0,0 +1,256 @@
+package fsm
+
+// MinimizingOptimizer implements the Optimizer interface by computing the minimal
+// FiniteAutomaton with Hopcroft's partition refinement algorithm.
+//
+// States unreachable from q0 are removed and equivalent states are merged into a
+// single representative. The result behaves exactly like the original under
+// ProcessInput: it accepts the same language, and inputs that hit an undefined
+// transition in the original still do so in the minimized automaton.
+type MinimizingOptimizer[Q State, S Symbol] struct{}
+
+// NewMinimizingOptimizer creates a new minimizing optimizer.
+func NewMinimizingOptimizer[Q State, S Symbol]() *MinimizingOptimizer[Q, S] {
+	return &MinimizingOptimizer[Q, S]{}
+}
+
+// MinimizationResult holds a minimized automaton along with the state mapping
+// from the original automaton.
+type MinimizationResult[Q State, S Symbol] struct {
+	// Automaton is the minimized automaton
+	Automaton *FiniteAutomaton[Q, S]
+	// StateMapping maps every reachable original state to the state representing
+	// it in Automaton. Unreachable states have no entry.
+	StateMapping map[Q]Q
+}
+
+// Optimize returns the minimal equivalent automaton (implements Optimizer interface).
+func (o *MinimizingOptimizer[Q, S]) Optimize(automaton Automaton[Q, S]) (Automaton[Q, S], error) {
+	fa, err := finiteAutomatonOf(automaton)
+	if err != nil {
+		return nil, err
+	}
+	result, err := o.Minimize(fa)
+	if err != nil {
+		return nil, err
+	}
+	return result.Automaton, nil
+}
+
+// Minimize computes the minimal automaton and the old-state → new-state mapping.
+// Each equivalence class is represented by its member closest to q0 in
+// breadth-first order, so q0 is always kept as the initial state.
+func (o *MinimizingOptimizer[Q, S]) Minimize(fa *FiniteAutomaton[Q, S]) (*MinimizationResult[Q, S], error) {
+	if err := fa.Validate(); err != nil {
+		return nil, NewErrorWithCause(ErrorTypeValidation, "cannot minimize invalid automaton", err)
+	}
+
+	fa.mutex.RLock()
+	defer fa.mutex.RUnlock()
+
+	symbols := sortValues(fa.getAlphabetList())
+	states := fa.reachableStates(symbols)
+	blockOf := hopcroftPartition(fa, states, symbols)
+
+	// The first state of each block in BFS order represents the block
+	representative := make(map[int]Q)
+	mapping := make(map[Q]Q, len(states))
+	for _, state := range states {
+		block := blockOf[state]
+		if _, exists := representative[block]; !exists {
+			representative[block] = state
+		}
+		mapping[state] = representative[block]
+	}
+
+	minimized := New[Q, S](fa.initialState)
+	minimized.AddSymbols(symbols...)
+	for _, state := range states {
+		rep := mapping[state]
+		if rep != state {
+			continue
+		}
+		minimized.AddState(rep)
+		if fa.acceptingStates[rep] {
+			minimized.AddAcceptingState(rep)
+		}
+		for _, symbol := range symbols {
+			if target, exists := fa.transitions[rep][symbol]; exists {
+				minimized.AddTransition(rep, symbol, mapping[target])
+			}
+		}
+	}
+
+	return &MinimizationResult[Q, S]{
+		Automaton:    minimized,
+		StateMapping: mapping,
+	}, nil
+}
+
+// Minimize returns the minimal automaton equivalent to fa and the mapping from
+// each reachable original state to its representative.
+func Minimize[Q State, S Symbol](fa *FiniteAutomaton[Q, S]) (*FiniteAutomaton[Q, S], map[Q]Q, error) {
+	result, err := NewMinimizingOptimizer[Q, S]().Minimize(fa)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.Automaton, result.StateMapping, nil
+}
+
+// reachableStates returns the states reachable from q0 in breadth-first order,
+// exploring symbols in the given order. Callers must hold the mutex.
+func (fa *FiniteAutomaton[Q, S]) reachableStates(symbols []S) []Q {
+	seen := map[Q]bool{fa.initialState: true}
+	order := []Q{fa.initialState}
+	for i := 0; i < len(order); i++ {
+		for _, symbol := range symbols {
+			if next, exists := fa.transitions[order[i]][symbol]; exists && !seen[next] {
+				seen[next] = true
+				order = append(order, next)
+			}
+		}
+	}
+	return order
+}
+
+// renumberStates returns a copy of fa whose reachable states are numbered 0, 1,
+// 2, ... in breadth-first order from the initial state.
+func renumberStates[Q State, S Symbol](fa *FiniteAutomaton[Q, S]) *FiniteAutomaton[int, S] {
+	fa.mutex.RLock()
+	defer fa.mutex.RUnlock()
+
+	symbols := sortValues(fa.getAlphabetList())
+	states := fa.reachableStates(symbols)
+	number := make(map[Q]int, len(states))
+	for i, state := range states {
+		number[state] = i
+	}
+
+	renumbered := New[int, S](0)
+	renumbered.AddSymbols(symbols...)
+	for i, state := range states {
+		renumbered.AddState(i)
+		if fa.acceptingStates[state] {
+			renumbered.AddAcceptingState(i)
+		}
+		for _, symbol := range symbols {
+			if target, exists := fa.transitions[state][symbol]; exists {
+				renumbered.AddTransition(i, symbol, number[target])
+			}
+		}
+	}
+	return renumbered
+}
+
+// hopcroftPartition splits the given states into blocks of equivalent states and
+// returns the block number of each state.
+//
+// Missing transitions lead to an implicit sink that starts in a block of its
+// own, so a state is never merged with one whose run would fail differently.
+func hopcroftPartition[Q State, S Symbol](fa *FiniteAutomaton[Q, S], states []Q, symbols []S) map[Q]int {
+	n := len(states)
+	sink := n
+	index := make(map[Q]int, n)
+	for i, state := range states {
+		index[state] = i
+	}
+
+	// inverse[c][t] lists the states with a c-transition into t
+	inverse := make([][][]int, len(symbols))
+	for c, symbol := range symbols {
+		inverse[c] = make([][]int, n+1)
+		for i, state := range states {
+			target := sink
+			if next, exists := fa.transitions[state][symbol]; exists {
+				target = index[next]
+			}
+			inverse[c][target] = append(inverse[c][target], i)
+		}
+		// The sink loops on itself
+		inverse[c][sink] = append(inverse[c][sink], sink)
+	}
+
+	// Initial partition: accepting, rejecting, sink
+	blockOf := make([]int, n+1)
+	var accepting, rejecting []int
+	for i, state := range states {
+		if fa.acceptingStates[state] {
+			accepting = append(accepting, i)
+		} else {
+			rejecting = append(rejecting, i)
+		}
+	}
+	blocks := make([][]int, 0, 3)
+	for _, members := range [][]int{accepting, rejecting, {sink}} {
+		if len(members) == 0 {
+			continue
+		}
+		for _, member := range members {
+			blockOf[member] = len(blocks)
+		}
+		blocks = append(blocks, members)
+	}
+
+	inWorklist := make([]bool, len(blocks))
+	worklist := make([]int, 0, len(blocks))
+	for block := range blocks {
+		worklist = append(worklist, block)
+		inWorklist[block] = true
+	}
+
+	for len(worklist) > 0 {
+		splitter := worklist[len(worklist)-1]
+		worklist = worklist[:len(worklist)-1]
+		inWorklist[splitter] = false
+
+		for c := range symbols {
+			// X = states with a c-transition into the splitter
+			hit := make(map[int][]int)
+			for _, target := range blocks[splitter] {
+				for _, source := range inverse[c][target] {
+					hit[blockOf[source]] = append(hit[blockOf[source]], source)
+				}
+			}
+
+			for block, inside := range hit {
+				if len(inside) == len(blocks[block]) {
+					continue
+				}
+				isInside := make(map[int]bool, len(inside))
+				for _, member := range inside {
+					isInside[member] = true
+				}
+				outside := make([]int, 0, len(blocks[block])-len(inside))
+				for _, member := range blocks[block] {
+					if !isInside[member] {
+						outside = append(outside, member)
+					}
+				}
+
+				// Keep the larger half in place and give the smaller half a new block
+				kept, moved := inside, outside
+				if len(moved) > len(kept) {
+					kept, moved = moved, kept
+				}
+				blocks[block] = kept
+				newBlock := len(blocks)
+				blocks = append(blocks, moved)
+				inWorklist = append(inWorklist, false)
+				for _, member := range moved {
+					blockOf[member] = newBlock
+				}
+
+				// Hopcroft: if the block is still pending both halves now are,
+				// otherwise refining by the smaller half suffices
+				worklist = append(worklist, newBlock)
+				inWorklist[newBlock] = true
+			}
+		}
+	}
+
+	result := make(map[Q]int, n)
+	for i, state := range states {
+		result[state] = blockOf[i]
+	}
+	return result
+}
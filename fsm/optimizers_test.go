@@ -0,0 +1,134 @@
+package fsm
+
+import (
+	"testing"
+)
+
+// newRedundantParityAutomaton accepts binary strings with an even number of 1s
+// using four states where two would do, plus an unreachable state
+func newRedundantParityAutomaton() *FiniteAutomaton[string, rune] {
+	return New[string, rune]("even").
+		AddStates("even", "odd", "even2", "odd2", "orphan").
+		AddSymbols('0', '1').
+		AddAcceptingStates("even", "even2").
+		AddTransition("even", '0', "even2").
+		AddTransition("even", '1', "odd").
+		AddTransition("even2", '0', "even").
+		AddTransition("even2", '1', "odd2").
+		AddTransition("odd", '0', "odd2").
+		AddTransition("odd", '1', "even").
+		AddTransition("odd2", '0', "odd").
+		AddTransition("odd2", '1', "even2").
+		AddTransition("orphan", '0', "even")
+}
+
+// TestMinimize_MergesEquivalentStates tests merging and unreachable state removal
+func TestMinimize_MergesEquivalentStates(t *testing.T) {
+	fa := newRedundantParityAutomaton()
+
+	minimized, mapping, err := Minimize(fa)
+	if err != nil {
+		t.Fatalf("Minimize returned error: %v", err)
+	}
+
+	if len(minimized.states) != 2 {
+		t.Errorf("Minimized automaton has %d states, want 2: %v", len(minimized.states), minimized.getStatesList())
+	}
+	if minimized.GetInitialState() != "even" {
+		t.Errorf("Initial state = %v, want even", minimized.GetInitialState())
+	}
+
+	expected := map[string]string{"even": "even", "even2": "even", "odd": "odd", "odd2": "odd"}
+	for old, want := range expected {
+		if mapping[old] != want {
+			t.Errorf("mapping[%s] = %s, want %s", old, mapping[old], want)
+		}
+	}
+	if _, exists := mapping["orphan"]; exists {
+		t.Error("Unreachable state should not appear in the mapping")
+	}
+
+	for _, input := range []string{"", "0", "1", "11", "101", "0110", "111", "100100"} {
+		want, _ := fa.ProcessInput([]rune(input))
+		got, err := minimized.ProcessInput([]rune(input))
+		if err != nil {
+			t.Fatalf("ProcessInput(%q) returned error: %v", input, err)
+		}
+		if got != want {
+			t.Errorf("ProcessInput(%q) = %v, original = %v", input, got, want)
+		}
+	}
+}
+
+// TestMinimize_PreservesUndefinedTransitions tests that dead states are not merged with missing transitions
+func TestMinimize_PreservesUndefinedTransitions(t *testing.T) {
+	// "trap" rejects everything but is defined; q1 has no transitions at all
+	fa := New[string, rune]("q0").
+		AddStates("q0", "q1", "trap").
+		AddSymbols('a', 'b').
+		AddAcceptingStates("q1").
+		AddTransition("q0", 'a', "q1").
+		AddTransition("q0", 'b', "trap").
+		AddTransition("trap", 'a', "trap").
+		AddTransition("trap", 'b', "trap")
+
+	minimized, _, err := Minimize(fa)
+	if err != nil {
+		t.Fatalf("Minimize returned error: %v", err)
+	}
+	if len(minimized.states) != 3 {
+		t.Errorf("Minimized automaton has %d states, want 3", len(minimized.states))
+	}
+
+	accepted, err := minimized.ProcessInput([]rune("ba"))
+	if err != nil || accepted {
+		t.Errorf("ProcessInput(\"ba\") = %v, %v; want rejection without error", accepted, err)
+	}
+	if _, err := minimized.ProcessInput([]rune("aa")); err == nil {
+		t.Error("ProcessInput(\"aa\") should still fail with undefined transition")
+	}
+}
+
+// TestMinimizingOptimizer_Factory tests the optimizer through OptimizedBuilder
+func TestMinimizingOptimizer_Factory(t *testing.T) {
+	factory := NewOptimizedFactory[string, rune](NewMinimizingOptimizer[string, rune]())
+
+	automaton := factory.CreateBuilder("even").
+		WithStates("even", "odd", "even2", "odd2").
+		WithAlphabet('0', '1').
+		WithAcceptingStates("even", "even2").
+		WithTransitions(
+			T("even", '0', "even2"), T("even", '1', "odd"),
+			T("even2", '0', "even"), T("even2", '1', "odd2"),
+			T("odd", '0', "odd2"), T("odd", '1', "even"),
+			T("odd2", '0', "odd"), T("odd2", '1', "even2"),
+		).
+		MustBuild()
+
+	fa, ok := automaton.(*FiniteAutomaton[string, rune])
+	if !ok {
+		t.Fatalf("Optimized builder returned %T", automaton)
+	}
+	if len(fa.states) != 2 {
+		t.Errorf("Optimized automaton has %d states, want 2", len(fa.states))
+	}
+
+	// Empty automata cannot be minimized; the factory falls back to the original
+	if factory.CreateAutomaton("q0") == nil {
+		t.Error("CreateAutomaton should fall back to the unoptimized automaton")
+	}
+}
+
+// TestMinimizingOptimizer_InvalidInput tests error handling
+func TestMinimizingOptimizer_InvalidInput(t *testing.T) {
+	optimizer := NewMinimizingOptimizer[string, rune]()
+
+	if _, err := optimizer.Optimize(New[string, rune]("q0")); !IsValidationError(err) {
+		t.Errorf("Optimize on invalid automaton should return validation error, got %v", err)
+	}
+
+	observable := NewObservableAutomaton[string, rune](newRedundantParityAutomaton())
+	if _, err := optimizer.Optimize(observable); err != nil {
+		t.Errorf("Optimize should see through ObservableAutomaton: %v", err)
+	}
+}
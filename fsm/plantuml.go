@@ -0,0 +1,80 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// plantUMLKeywords cannot be used as PlantUML state identifiers.
+var plantUMLKeywords = []string{"state", "as", "end", "note", "hide", "show", "skinparam", "left", "right", "top", "bottom"}
+
+// ToPlantUML renders an automaton as a PlantUML state diagram using DefaultDiagramOptions.
+func ToPlantUML[Q State, S Symbol](automaton Automaton[Q, S]) (string, error) {
+	return ToPlantUMLWithOptions(automaton, DefaultDiagramOptions[Q, S]())
+}
+
+// ToPlantUMLWithOptions renders an automaton as a PlantUML state diagram
+// between @startuml and @enduml.
+//
+// The initial state is entered from [*] and accepting states have a
+// transition to [*]. Transitions between the same pair of states are merged
+// into one edge whose label lists their symbols. States whose name is not a
+// plain identifier are declared as `state "name" as sN`; double quotes in
+// names and labels are written as &#34;, backslashes are doubled and line
+// breaks become \n. The output is deterministic.
+//
+// The automaton must be a *FiniteAutomaton or an ObservableAutomaton wrapping
+// one, as for ToDOTWithOptions.
+func ToPlantUMLWithOptions[Q State, S Symbol](automaton Automaton[Q, S], options DiagramOptions[Q, S]) (string, error) {
+	d, err := newDiagram(automaton)
+	if err != nil {
+		return "", err
+	}
+	names, edgeLabel, err := options.labels(d)
+	if err != nil {
+		return "", err
+	}
+	ids := d.stateIDs(names, func(name string) bool {
+		return isDiagramIdentifier(name, plantUMLKeywords...)
+	})
+
+	var sb strings.Builder
+	sb.WriteString("@startuml\n")
+	if options.LeftToRight {
+		sb.WriteString("left to right direction\n")
+	}
+	sb.WriteString("hide empty description\n")
+	for _, state := range d.states {
+		if ids[state] == names[state] {
+			fmt.Fprintf(&sb, "state %s\n", ids[state])
+		} else {
+			fmt.Fprintf(&sb, "state \"%s\" as %s\n", escapePlantUML(names[state]), ids[state])
+		}
+	}
+	fmt.Fprintf(&sb, "[*] --> %s\n", ids[d.initial])
+	for _, edge := range d.edges {
+		fmt.Fprintf(&sb, "%s --> %s", ids[edge.from], ids[edge.to])
+		if label := edgeLabel(edge); label != "" {
+			fmt.Fprintf(&sb, " : %s", escapePlantUML(label))
+		}
+		sb.WriteString("\n")
+	}
+	for _, state := range d.states {
+		if d.accepting[state] {
+			fmt.Fprintf(&sb, "%s --> [*]\n", ids[state])
+		}
+	}
+	sb.WriteString("@enduml\n")
+	return sb.String(), nil
+}
+
+// escapePlantUML writes text so that it stays on one line and inside quotes.
+func escapePlantUML(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`"`, "&#34;",
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(text)
+}
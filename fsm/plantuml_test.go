@@ -0,0 +1,48 @@
+package fsm
+
+import (
+	"testing"
+)
+
+// TestToPlantUML tests the exact output including escaping and aliasing
+func TestToPlantUML(t *testing.T) {
+	fa := newDiagramAutomaton().AddSymbol(`a\b`).AddTransition("done", `a\b`, "idle")
+
+	got, err := ToPlantUML[string, string](fa)
+	if err != nil {
+		t.Fatalf("ToPlantUML returned error: %v", err)
+	}
+
+	want := `@startuml
+left to right direction
+hide empty description
+state done
+state "end" as s1
+state idle
+state "in review" as s2
+state s0
+[*] --> idle
+done --> idle : a\\b
+idle --> s2 : submit
+s2 --> done : approve
+s2 --> s1 : reject, say &#34;no&#34;; #1
+done --> [*]
+@enduml
+`
+	if got != want {
+		t.Errorf("ToPlantUML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestToPlantUML_Errors tests rejected automata and options
+func TestToPlantUML_Errors(t *testing.T) {
+	options := DefaultDiagramOptions[string, rune]()
+	options.StateLabel = func(string) string { return "same" }
+	if _, err := ToPlantUMLWithOptions[string, rune](newParityAutomaton(), options); err == nil {
+		t.Error("Expected error for states sharing a label")
+	}
+
+	if _, err := ToMermaid[StateSet[string], rune](newEndsWithABNFA()); err == nil {
+		t.Error("Expected error for an NFA")
+	}
+}
@@ -0,0 +1,306 @@
+package fsm
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// prefixTrie holds the states reached after the prefixes processed by one
+// automaton.
+type prefixTrie[Q State, S Symbol] struct {
+	identity any
+	version  uint64
+	root     *prefixNode[Q, S]
+}
+
+// prefixNode is the state reached after the prefix spelled by the symbols on
+// the path from the root.
+type prefixNode[Q State, S Symbol] struct {
+	state    Q
+	symbol   S
+	parent   *prefixNode[Q, S]
+	children map[S]*prefixNode[Q, S]
+	trie     *prefixTrie[Q, S]
+	// element is the node in the LRU list, or nil once the node is removed
+	element *list.Element
+}
+
+// PrefixProcessor memoizes the state reached after every prefix of the inputs
+// it processes, in a trie keyed by symbol. Processing an input resumes from
+// the state of its longest cached prefix instead of replaying it from the
+// initial state, which pays off when many inputs share long prefixes, such as
+// event logs of the same session.
+//
+// Resuming requires an automaton implementing StateTransitioner, such as a
+// FiniteAutomaton, NFA or Session; other automata, including
+// ObservableAutomaton so that its observers are notified, are processed by a
+// StandardProcessor without caching. The automaton's current state is not
+// changed.
+//
+// The trie of a Versioned automaton is dropped as soon as its definition
+// changes. MaxEntries of the CacheConfig bounds the number of trie nodes,
+// evicting the least recently used prefixes first, and MaxInputLength bounds
+// the length of the prefixes cached. In the CacheStats, hits and misses count
+// symbols resumed from the trie and symbols stepped. The processor is safe for
+// concurrent use; it locks only to look up and insert prefixes, so automata
+// are stepped concurrently.
+type PrefixProcessor[Q State, S Symbol] struct {
+	config CacheConfig
+	tries  map[any]*prefixTrie[Q, S]
+	// lru lists the nodes of every trie from most to least recently used. A
+	// node is always more recent than its descendants, so the least recently
+	// used node is a leaf.
+	lru   *list.List
+	stats CacheStats
+	mutex sync.Mutex
+}
+
+// NewPrefixProcessor creates a new prefix processor with the default cache
+// configuration.
+func NewPrefixProcessor[Q State, S Symbol]() *PrefixProcessor[Q, S] {
+	return NewPrefixProcessorWithConfig[Q, S](DefaultCacheConfig())
+}
+
+// NewPrefixProcessorWithConfig creates a new prefix processor with the given
+// cache configuration.
+func NewPrefixProcessorWithConfig[Q State, S Symbol](config CacheConfig) *PrefixProcessor[Q, S] {
+	return &PrefixProcessor[Q, S]{
+		config: config,
+		tries:  make(map[any]*prefixTrie[Q, S]),
+		lru:    list.New(),
+	}
+}
+
+// Process processes input, resuming from the longest cached prefix.
+func (p *PrefixProcessor[Q, S]) Process(automaton Automaton[Q, S], input []S) (ProcessResult[Q], error) {
+	return p.ProcessContext(context.Background(), automaton, input)
+}
+
+// ProcessContext processes input with cancellation, resuming from the longest
+// cached prefix (implements ContextProcessor interface). The context is
+// checked before every symbol; on interruption or error the result holds the
+// partial trace and the prefixes processed so far stay cached.
+func (p *PrefixProcessor[Q, S]) ProcessContext(
+	ctx context.Context,
+	automaton Automaton[Q, S],
+	input []S,
+) (ProcessResult[Q], error) {
+	transitioner, ok := automaton.(StateTransitioner[Q, S])
+	identity, version, cacheable := cacheIdentity(automaton)
+	if !ok || !cacheable {
+		return NewStandardProcessor[Q, S]().ProcessContext(ctx, automaton, input)
+	}
+
+	initial := automaton.GetInitialState()
+	done := ctx.Done()
+	interrupted := func(position int, trace []Q) error {
+		select {
+		case <-done:
+			return &InterruptedError[Q]{Position: position, Trace: trace, Cause: ctx.Err()}
+		default:
+			return nil
+		}
+	}
+
+	// Follow the longest cached prefix under the lock.
+	p.mutex.Lock()
+	trie := p.trie(identity, version, initial)
+	node := trie.root
+	path := []*prefixNode[Q, S]{node}
+	trace := make([]Q, 1, len(input)+1)
+	trace[0] = node.state
+	var err error
+	for i, symbol := range input {
+		if err = interrupted(i, trace); err != nil {
+			break
+		}
+		child, exists := node.children[symbol]
+		if !exists {
+			break
+		}
+		node = child
+		path = append(path, node)
+		trace = append(trace, node.state)
+	}
+	resumed := len(path) - 1
+	p.stats.Hits += uint64(resumed)
+	p.mutex.Unlock()
+
+	// Step the rest of the input without the lock.
+	state := node.state
+	stepped := 0
+	for i := resumed; err == nil && i < len(input); i++ {
+		if err = interrupted(i, trace); err != nil {
+			break
+		}
+		stepped++
+		var next Q
+		if next, err = transitioner.Transition(state, input[i]); err != nil {
+			break
+		}
+		state = next
+		trace = append(trace, next)
+	}
+
+	p.store(automaton, trie, path, input, trace[resumed+1:], stepped)
+	if err != nil {
+		return ProcessResult[Q]{Accepted: false, Trace: trace, FinalState: state}, err
+	}
+	return ProcessResult[Q]{
+		Accepted:   automaton.IsAcceptingState(state),
+		Trace:      trace,
+		FinalState: state,
+	}, nil
+}
+
+// store caches the states stepped after the path of resumed nodes, up to
+// MaxInputLength, touches the path and evicts beyond MaxEntries. Nothing is
+// cached when the trie was dropped or the automaton changed while it was
+// stepped; nodes of the path evicted meanwhile are skipped.
+func (p *PrefixProcessor[Q, S]) store(automaton Automaton[Q, S], trie *prefixTrie[Q, S], path []*prefixNode[Q, S], input []S, states []Q, stepped int) {
+	_, version, _ := cacheIdentity(automaton)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.stats.Misses += uint64(stepped)
+	if p.tries[trie.identity] != trie || trie.version != version {
+		return
+	}
+
+	// Leaves are evicted first, so the nodes still attached are a prefix of
+	// the path.
+	attached := 0
+	for attached < len(path) && path[attached].element != nil {
+		attached++
+	}
+	if attached == len(path) {
+		resumed := len(path) - 1
+		parent := path[resumed]
+		for k, state := range states {
+			position := resumed + k
+			if p.config.MaxInputLength > 0 && position >= p.config.MaxInputLength {
+				break
+			}
+			// Another goroutine may have cached the same prefix meanwhile.
+			child, exists := parent.children[input[position]]
+			if !exists {
+				child = p.insert(parent, input[position], state)
+			}
+			path = append(path, child)
+			parent = child
+		}
+	} else {
+		path = path[:attached]
+	}
+
+	// Touch the path from the deepest node up, so that every node stays more
+	// recent than its descendants, then evict beyond the limit.
+	for i := len(path) - 1; i >= 0; i-- {
+		p.lru.MoveToFront(path[i].element)
+	}
+	for p.config.MaxEntries > 0 && p.lru.Len() > p.config.MaxEntries {
+		p.remove(p.lru.Back().Value.(*prefixNode[Q, S]))
+		p.stats.Evictions++
+	}
+}
+
+// trie returns the trie of the identified automaton, replacing a trie built
+// against another version. The caller must hold the mutex.
+func (p *PrefixProcessor[Q, S]) trie(identity any, version uint64, initial Q) *prefixTrie[Q, S] {
+	trie, exists := p.tries[identity]
+	if exists && trie.version == version {
+		return trie
+	}
+	if exists {
+		p.stats.Invalidations += uint64(p.removeTrie(trie))
+	}
+
+	trie = &prefixTrie[Q, S]{identity: identity, version: version}
+	trie.root = &prefixNode[Q, S]{state: initial, trie: trie}
+	trie.root.element = p.lru.PushFront(trie.root)
+	p.tries[identity] = trie
+	return trie
+}
+
+// insert adds the child reached from parent on symbol. The caller must hold
+// the mutex.
+func (p *PrefixProcessor[Q, S]) insert(parent *prefixNode[Q, S], symbol S, state Q) *prefixNode[Q, S] {
+	child := &prefixNode[Q, S]{state: state, symbol: symbol, parent: parent, trie: parent.trie}
+	child.element = p.lru.PushFront(child)
+	if parent.children == nil {
+		parent.children = make(map[S]*prefixNode[Q, S])
+	}
+	parent.children[symbol] = child
+	return child
+}
+
+// remove drops a leaf, and its trie with it when the leaf is the root. The
+// caller must hold the mutex.
+func (p *PrefixProcessor[Q, S]) remove(node *prefixNode[Q, S]) {
+	p.lru.Remove(node.element)
+	node.element = nil
+	if node.parent != nil {
+		delete(node.parent.children, node.symbol)
+	} else {
+		delete(p.tries, node.trie.identity)
+	}
+}
+
+// removeTrie drops every node of a trie and returns their number. The caller
+// must hold the mutex.
+func (p *PrefixProcessor[Q, S]) removeTrie(trie *prefixTrie[Q, S]) int {
+	removed := 0
+	stack := []*prefixNode[Q, S]{trie.root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, child := range node.children {
+			stack = append(stack, child)
+		}
+		p.lru.Remove(node.element)
+		node.element = nil
+		removed++
+	}
+	delete(p.tries, trie.identity)
+	return removed
+}
+
+// Invalidate drops the cached prefixes of an automaton. It is needed only for
+// automata that change without being Versioned.
+func (p *PrefixProcessor[Q, S]) Invalidate(automaton Automaton[Q, S]) {
+	identity, _, ok := cacheIdentity(automaton)
+	if !ok {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if trie, exists := p.tries[identity]; exists {
+		p.stats.Invalidations += uint64(p.removeTrie(trie))
+	}
+}
+
+// ClearCache drops every cached prefix. Statistics other than the number of
+// entries are kept.
+func (p *PrefixProcessor[Q, S]) ClearCache() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.tries = make(map[any]*prefixTrie[Q, S])
+	p.lru.Init()
+}
+
+// GetCacheSize returns the number of trie nodes, roots included.
+func (p *PrefixProcessor[Q, S]) GetCacheSize() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.lru.Len()
+}
+
+// Stats returns the cache statistics.
+func (p *PrefixProcessor[Q, S]) Stats() CacheStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	stats := p.stats
+	stats.Entries = p.lru.Len()
+	return stats
+}
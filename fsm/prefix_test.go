@@ -0,0 +1,273 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingAutomaton counts the transitions looked up through it
+type countingAutomaton struct {
+	*FiniteAutomaton[string, rune]
+	transitions int
+}
+
+func (a *countingAutomaton) Transition(state string, symbol rune) (string, error) {
+	a.transitions++
+	return a.FiniteAutomaton.Transition(state, symbol)
+}
+
+// TestPrefixProcessor tests that results agree with the standard processor
+func TestPrefixProcessor(t *testing.T) {
+	fa := newEndsWithZeroAutomaton()
+	machine, err := Compile[string, rune](fa)
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+	processor := NewPrefixProcessorWithConfig[string, rune](CacheConfig{MaxEntries: 64, MaxInputLength: 10})
+	standard := NewStandardProcessor[string, rune]()
+
+	for _, input := range randomInputs([]rune("01"), 300, 14) {
+		want, wantErr := standard.Process(fa, input)
+		for _, automaton := range []Automaton[string, rune]{fa, machine.NewSession()} {
+			result, err := processor.Process(automaton, input)
+			if !reflect.DeepEqual(result, want) || (err == nil) != (wantErr == nil) {
+				t.Fatalf("Process(%q) = %+v, %v; want %+v, %v", string(input), result, err, want, wantErr)
+			}
+		}
+	}
+	if size := processor.GetCacheSize(); size > 64 {
+		t.Errorf("Cache grew to %d nodes", size)
+	}
+
+	nfa := newEndsWithABNFA()
+	nfaProcessor := NewPrefixProcessor[StateSet[string], rune]()
+	for _, input := range randomInputs([]rune("ab"), 100, 8) {
+		want, _, _ := nfa.ProcessInputWithTrace(input)
+		result, _ := nfaProcessor.Process(nfa, input)
+		accepted, _ := nfa.ProcessInput(input)
+		if len(result.Trace) != len(want) || result.Accepted != accepted || !result.FinalState.Equal(want[len(want)-1]) {
+			t.Fatalf("Process(%q) = %+v, want trace %v", string(input), result, want)
+		}
+	}
+}
+
+// TestPrefixProcessor_Resume tests that shared prefixes are not replayed
+func TestPrefixProcessor_Resume(t *testing.T) {
+	automaton := &countingAutomaton{FiniteAutomaton: newParityAutomaton()}
+	processor := NewPrefixProcessor[string, rune]()
+
+	processor.Process(automaton, []rune("0110"))
+	result, err := processor.Process(automaton, []rune("01101"))
+	if err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if automaton.transitions != 5 {
+		t.Errorf("Expected 5 transitions looked up, got %d", automaton.transitions)
+	}
+	want := []string{"even", "even", "odd", "even", "even", "odd"}
+	if result.Accepted || result.FinalState != "odd" || !reflect.DeepEqual(result.Trace, want) {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if automaton.GetCurrentState() != "even" {
+		t.Errorf("The automaton should not be stepped, got %s", automaton.GetCurrentState())
+	}
+
+	stats := processor.Stats()
+	if stats.Hits != 4 || stats.Misses != 5 || stats.Entries != 6 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+// TestPrefixProcessor_Limits tests eviction of the least recently used prefixes and MaxInputLength
+func TestPrefixProcessor_Limits(t *testing.T) {
+	automaton := &countingAutomaton{FiniteAutomaton: newParityAutomaton()}
+	processor := NewPrefixProcessorWithConfig[string, rune](CacheConfig{MaxEntries: 4, MaxInputLength: 3})
+
+	processor.Process(automaton, []rune("000"))
+	processor.Process(automaton, []rune("1"))
+	// The least recently used leaf "000" was evicted.
+	stats := processor.Stats()
+	if stats.Entries != 4 || stats.Evictions != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	automaton.transitions = 0
+	processor.Process(automaton, []rune("01"))
+	if automaton.transitions != 1 {
+		t.Errorf("Expected the prefix 0 to be resumed, got %d transitions", automaton.transitions)
+	}
+
+	// Prefixes longer than MaxInputLength are stepped every time.
+	processor.ClearCache()
+	processor.Process(automaton, []rune("11111"))
+	automaton.transitions = 0
+	if result, _ := processor.Process(automaton, []rune("11111")); result.FinalState != "odd" || automaton.transitions != 2 {
+		t.Errorf("Got %+v with %d transitions, want odd with 2", result, automaton.transitions)
+	}
+	if size := processor.GetCacheSize(); size != 4 {
+		t.Errorf("Expected 4 nodes, got %d", size)
+	}
+}
+
+// TestPrefixProcessor_Invalidation tests that changing an automaton drops its prefixes
+func TestPrefixProcessor_Invalidation(t *testing.T) {
+	fa := New[string, rune]("a").
+		AddStates("a", "b").
+		AddSymbols('x').
+		AddTransition("a", 'x', "b")
+	processor := NewPrefixProcessor[string, rune]()
+
+	if _, err := processor.Process(fa, []rune("xx")); !IsTransitionError(err) {
+		t.Fatalf("Expected transition error, got %v", err)
+	}
+	fa.AddTransition("b", 'x', "a").AddAcceptingState("a")
+	result, err := processor.Process(fa, []rune("xx"))
+	if err != nil || !result.Accepted {
+		t.Errorf("Process = %+v, %v; want accepted", result, err)
+	}
+	if stats := processor.Stats(); stats.Invalidations != 2 || stats.Hits != 0 {
+		t.Errorf("Expected the old prefixes to be dropped, got %+v", stats)
+	}
+
+	processor.Invalidate(fa)
+	if stats := processor.Stats(); stats.Invalidations != 5 || stats.Entries != 0 {
+		t.Errorf("Expected Invalidate to drop the trie, got %+v", stats)
+	}
+}
+
+// TestPrefixProcessor_Errors tests partial results on errors and interruptions
+func TestPrefixProcessor_Errors(t *testing.T) {
+	fa := newParityAutomaton()
+	processor := NewPrefixProcessor[string, rune]()
+
+	result, err := processor.Process(fa, []rune("12"))
+	if !IsInvalidInputError(err) || result.Accepted || result.FinalState != "odd" ||
+		!reflect.DeepEqual(result.Trace, []string{"even", "odd"}) {
+		t.Errorf("Unexpected result %+v, %v", result, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = processor.ProcessContext(ctx, fa, []rune("1"))
+	var interrupted *InterruptedError[string]
+	if !errors.As(err, &interrupted) || interrupted.Position != 0 || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected InterruptedError at 0, got %v", err)
+	}
+
+	// Observable automata are processed without caching so observers are notified.
+	debug := NewDebugObserver[string, rune]()
+	observable := NewObservableAutomaton[string, rune](fa)
+	observable.AddObserver(debug)
+	processor.Process(observable, []rune("11"))
+	if len(debug.GetTransitions()) != 2 || processor.Stats().Entries != 2 {
+		t.Errorf("Expected 2 observed transitions, got %d", len(debug.GetTransitions()))
+	}
+}
+
+// blockingAutomaton waits in Transition until released
+type blockingAutomaton struct {
+	*FiniteAutomaton[string, rune]
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (a *blockingAutomaton) Transition(state string, symbol rune) (string, error) {
+	a.entered <- struct{}{}
+	<-a.release
+	return a.FiniteAutomaton.Transition(state, symbol)
+}
+
+// TestPrefixProcessor_Concurrent tests that automata are stepped concurrently and results stay exact
+func TestPrefixProcessor_Concurrent(t *testing.T) {
+	blocking := &blockingAutomaton{
+		FiniteAutomaton: newParityAutomaton(),
+		entered:         make(chan struct{}),
+		release:         make(chan struct{}),
+	}
+	processor := NewPrefixProcessor[string, rune]()
+	var wg sync.WaitGroup
+	for _, input := range []string{"0", "1"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			processor.Process(blocking, []rune(input))
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-blocking.entered:
+		case <-time.After(5 * time.Second):
+			t.Error("Transitions of concurrent inputs were serialized")
+		}
+	}
+	close(blocking.release)
+	wg.Wait()
+
+	machine, err := Compile[string, rune](newEndsWithZeroAutomaton())
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+	processor = NewPrefixProcessorWithConfig[string, rune](CacheConfig{MaxEntries: 32, MaxInputLength: 6})
+	inputs := randomInputs([]rune("01"), 200, 10)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			session := machine.NewSession()
+			for i, input := range inputs {
+				if g == 0 && i%50 == 0 {
+					processor.ClearCache()
+				}
+				want, _ := machine.ProcessInput(input)
+				if result, err := processor.Process(session, input); err != nil || result.Accepted != want || len(result.Trace) != len(input)+1 {
+					t.Errorf("Input %q: got %+v, %v; want %v", string(input), result, err, want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if size := processor.GetCacheSize(); size > 32 {
+		t.Errorf("Cache grew to %d nodes", size)
+	}
+}
+
+// TestStateTransitioner tests Transition on every automaton implementing it
+func TestStateTransitioner(t *testing.T) {
+	fa := newParityAutomaton()
+	machine, err := Compile[string, rune](fa)
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+	for name, transitioner := range map[string]StateTransitioner[string, rune]{
+		"automaton": fa,
+		"session":   machine.NewSession(),
+	} {
+		if next, err := transitioner.Transition("odd", '1'); err != nil || next != "even" {
+			t.Errorf("%s: Transition(odd, 1) = %s, %v; want even", name, next, err)
+		}
+		if _, err := transitioner.Transition("odd", '2'); !IsInvalidInputError(err) {
+			t.Errorf("%s: expected invalid input error, got %v", name, err)
+		}
+		if _, err := transitioner.Transition("missing", '1'); !IsTransitionError(err) {
+			t.Errorf("%s: expected transition error, got %v", name, err)
+		}
+	}
+	if fa.GetCurrentState() != "even" {
+		t.Errorf("Transition should not change the current state, got %s", fa.GetCurrentState())
+	}
+
+	nfa := newEndsWithABNFA()
+	var transitioner StateTransitioner[StateSet[string], rune] = nfa
+	afterA, err := transitioner.Transition(nfa.GetCurrentState(), 'a')
+	if err != nil || afterA.Len() != 2 {
+		t.Fatalf("Transition({q0}, a) = %v, %v; want {q0, q1}", afterA, err)
+	}
+	afterB, err := transitioner.Transition(afterA, 'b')
+	if err != nil || !nfa.IsAcceptingState(afterB) {
+		t.Errorf("Transition(%v, b) = %v, %v; want an accepting set", afterA, afterB, err)
+	}
+}
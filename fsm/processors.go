@@ -1,6 +1,7 @@
 package fsm
 
 import (
+	"context"
 	"sync"
 )
 
@@ -14,19 +15,23 @@ func NewStandardProcessor[Q State, S Symbol]() *StandardProcessor[Q, S] {
 
 // Process processes input through the automaton sequentially.
 func (p *StandardProcessor[Q, S]) Process(automaton Automaton[Q, S], input []S) (ProcessResult[Q], error) {
-	automaton.Reset()
-	trace := []Q{automaton.GetCurrentState()}
+	return p.ProcessContext(context.Background(), automaton, input)
+}
 
-	for _, symbol := range input {
-		newState, err := automaton.Step(symbol)
-		if err != nil {
-			return ProcessResult[Q]{
-				Accepted:   false,
-				Trace:      trace,
-				FinalState: automaton.GetCurrentState(),
-			}, err
-		}
-		trace = append(trace, newState)
+// ProcessContext processes input sequentially, checking the context before
+// every symbol. On interruption the result holds the partial trace.
+func (p *StandardProcessor[Q, S]) ProcessContext(
+	ctx context.Context,
+	automaton Automaton[Q, S],
+	input []S,
+) (ProcessResult[Q], error) {
+	trace, err := stepContext(ctx, automaton, input)
+	if err != nil {
+		return ProcessResult[Q]{
+			Accepted:   false,
+			Trace:      trace,
+			FinalState: automaton.GetCurrentState(),
+		}, err
 	}
 
 	finalState := automaton.GetCurrentState()
@@ -39,6 +44,23 @@ func (p *StandardProcessor[Q, S]) Process(automaton Automaton[Q, S], input []S)
 	}, nil
 }
 
+// processContext runs a processor with the context when it supports
+// cancellation, and otherwise only checks the context before starting.
+func processContext[Q State, S Symbol](
+	ctx context.Context,
+	processor Processor[Q, S],
+	automaton Automaton[Q, S],
+	input []S,
+) (ProcessResult[Q], error) {
+	if contextProcessor, ok := processor.(ContextProcessor[Q, S]); ok {
+		return contextProcessor.ProcessContext(ctx, automaton, input)
+	}
+	if err := ctx.Err(); err != nil {
+		return ProcessResult[Q]{}, &InterruptedError[Q]{Cause: err}
+	}
+	return processor.Process(automaton, input)
+}
+
 // ParallelProcessor implements parallel processing for multiple inputs.
 type ParallelProcessor[Q State, S Symbol] struct {
 	maxWorkers int
@@ -56,6 +78,22 @@ func NewParallelProcessor[Q State, S Symbol](maxWorkers int) *ParallelProcessor[
 
 // ProcessBatch processes multiple inputs in parallel.
 func (p *ParallelProcessor[Q, S]) ProcessBatch(automaton Automaton[Q, S], inputs [][]S) ([]ProcessResult[Q], error) {
+	return p.ProcessBatchContext(context.Background(), automaton, inputs)
+}
+
+// ProcessBatchContext processes multiple inputs in parallel until the context
+// is done. Inputs being processed stop between symbols; inputs not started yet
+// are skipped. Both report an InterruptedError.
+//
+// A *FiniteAutomaton or *Session is compiled once and every worker runs its
+// own Session, so workers never share a current state. Other automata keep
+// their state in the instance passed in, so their inputs are processed one at
+// a time.
+func (p *ParallelProcessor[Q, S]) ProcessBatchContext(
+	ctx context.Context,
+	automaton Automaton[Q, S],
+	inputs [][]S,
+) ([]ProcessResult[Q], error) {
 	if len(inputs) == 0 {
 		return []ProcessResult[Q]{}, nil
 	}
@@ -74,6 +112,8 @@ func (p *ParallelProcessor[Q, S]) ProcessBatch(automaton Automaton[Q, S], inputs
 		input []S
 	}, len(inputs))
 
+	newWorkerAutomaton, shared := workerAutomata(automaton)
+
 	var wg sync.WaitGroup
 
 	// Start workers
@@ -82,9 +122,20 @@ func (p *ParallelProcessor[Q, S]) ProcessBatch(automaton Automaton[Q, S], inputs
 		go func() {
 			defer wg.Done()
 			processor := NewStandardProcessor[Q, S]()
+			workerAutomaton := newWorkerAutomaton()
 
 			for job := range inputChan {
-				result, err := processor.Process(automaton, job.input)
+				if err := ctx.Err(); err != nil {
+					errors[job.index] = &InterruptedError[Q]{Cause: err}
+					continue
+				}
+				if shared != nil {
+					shared.Lock()
+				}
+				result, err := processor.ProcessContext(ctx, workerAutomaton, job.input)
+				if shared != nil {
+					shared.Unlock()
+				}
 				results[job.index] = result
 				errors[job.index] = err
 			}
@@ -112,73 +163,38 @@ func (p *ParallelProcessor[Q, S]) ProcessBatch(automaton Automaton[Q, S], inputs
 	return results, collector.ToError()
 }
 
-// Process processes a single input (implements Processor interface).
-func (p *ParallelProcessor[Q, S]) Process(automaton Automaton[Q, S], input []S) (ProcessResult[Q], error) {
-	// For single input, just use standard processing
-	processor := NewStandardProcessor[Q, S]()
-	return processor.Process(automaton, input)
-}
-
-// OptimizedProcessor implements optimized processing with caching.
-type OptimizedProcessor[Q State, S Symbol] struct {
-	cache map[string]ProcessResult[Q]
-	mutex sync.RWMutex
-}
-
-// NewOptimizedProcessor creates a new optimized processor with caching.
-func NewOptimizedProcessor[Q State, S Symbol]() *OptimizedProcessor[Q, S] {
-	return &OptimizedProcessor[Q, S]{
-		cache: make(map[string]ProcessResult[Q]),
+// workerAutomata returns the function creating the automaton of each worker.
+// When the workers have to share the automaton, it also returns the mutex
+// serializing their runs.
+func workerAutomata[Q State, S Symbol](automaton Automaton[Q, S]) (func() Automaton[Q, S], *sync.Mutex) {
+	var machine *Machine[Q, S]
+	switch a := automaton.(type) {
+	case *FiniteAutomaton[Q, S]:
+		machine, _ = Compile[Q, S](a)
+	case *Session[Q, S]:
+		machine = a.Machine()
 	}
-}
-
-// Process processes input with caching for repeated inputs.
-func (p *OptimizedProcessor[Q, S]) Process(automaton Automaton[Q, S], input []S) (ProcessResult[Q], error) {
-	// Create cache key
-	key := p.createCacheKey(input)
-
-	// Check cache first
-	p.mutex.RLock()
-	if result, exists := p.cache[key]; exists {
-		p.mutex.RUnlock()
-		return result, nil
+	if machine != nil {
+		return func() Automaton[Q, S] { return machine.NewSession() }, nil
 	}
-	p.mutex.RUnlock()
-
-	// Process normally
-	processor := NewStandardProcessor[Q, S]()
-	result, err := processor.Process(automaton, input)
-	if err != nil {
-		return result, err
-	}
-
-	// Cache the result
-	p.mutex.Lock()
-	p.cache[key] = result
-	p.mutex.Unlock()
-
-	return result, nil
-}
-
-// createCacheKey creates a string key for caching.
-func (p *OptimizedProcessor[Q, S]) createCacheKey(input []S) string {
-	// Simple string representation for caching
-	// In a real implementation, you might want a more sophisticated key
-	return string(rune(len(input))) // Simplified for demo
+	return func() Automaton[Q, S] { return automaton }, &sync.Mutex{}
 }
 
-// ClearCache clears the processor's cache.
-func (p *OptimizedProcessor[Q, S]) ClearCache() {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-	p.cache = make(map[string]ProcessResult[Q])
+// Process processes a single input (implements Processor interface).
+func (p *ParallelProcessor[Q, S]) Process(automaton Automaton[Q, S], input []S) (ProcessResult[Q], error) {
+	return p.ProcessContext(context.Background(), automaton, input)
 }
 
-// GetCacheSize returns the number of cached results.
-func (p *OptimizedProcessor[Q, S]) GetCacheSize() int {
-	p.mutex.RLock()
-	defer p.mutex.RUnlock()
-	return len(p.cache)
+// ProcessContext processes a single input with cancellation (implements
+// ContextProcessor interface).
+func (p *ParallelProcessor[Q, S]) ProcessContext(
+	ctx context.Context,
+	automaton Automaton[Q, S],
+	input []S,
+) (ProcessResult[Q], error) {
+	// For single input, just use standard processing
+	processor := NewStandardProcessor[Q, S]()
+	return processor.ProcessContext(ctx, automaton, input)
 }
 
 // TracingProcessor wraps another processor and adds detailed tracing.
@@ -197,12 +213,22 @@ func NewTracingProcessor[Q State, S Symbol](wrapped Processor[Q, S], tracer func
 
 // Process processes input with detailed tracing.
 func (p *TracingProcessor[Q, S]) Process(automaton Automaton[Q, S], input []S) (ProcessResult[Q], error) {
+	return p.ProcessContext(context.Background(), automaton, input)
+}
+
+// ProcessContext processes input with detailed tracing, passing the context
+// to the wrapped processor.
+func (p *TracingProcessor[Q, S]) ProcessContext(
+	ctx context.Context,
+	automaton Automaton[Q, S],
+	input []S,
+) (ProcessResult[Q], error) {
 	if p.tracer != nil {
 		p.tracer("Starting input processing")
 		defer p.tracer("Finished input processing")
 	}
 
-	result, err := p.wrapped.Process(automaton, input)
+	result, err := processContext(ctx, p.wrapped, automaton, input)
 
 	if p.tracer != nil {
 		if err != nil {
@@ -234,6 +260,16 @@ func NewValidatingProcessor[Q State, S Symbol](
 
 // Process processes input with validation.
 func (p *ValidatingProcessor[Q, S]) Process(automaton Automaton[Q, S], input []S) (ProcessResult[Q], error) {
+	return p.ProcessContext(context.Background(), automaton, input)
+}
+
+// ProcessContext processes input with validation, passing the context to the
+// wrapped processor.
+func (p *ValidatingProcessor[Q, S]) ProcessContext(
+	ctx context.Context,
+	automaton Automaton[Q, S],
+	input []S,
+) (ProcessResult[Q], error) {
 	// Validate input first
 	if p.validator != nil {
 		if err := p.validator(input); err != nil {
@@ -241,7 +277,7 @@ func (p *ValidatingProcessor[Q, S]) Process(automaton Automaton[Q, S], input []S
 		}
 	}
 
-	return p.wrapped.Process(automaton, input)
+	return processContext(ctx, p.wrapped, automaton, input)
 }
 
 // ProcessorChain allows chaining multiple processors.
@@ -258,13 +294,24 @@ func NewProcessorChain[Q State, S Symbol](processors ...Processor[Q, S]) *Proces
 
 // Process processes input through all processors in the chain.
 func (p *ProcessorChain[Q, S]) Process(automaton Automaton[Q, S], input []S) (ProcessResult[Q], error) {
+	return p.ProcessContext(context.Background(), automaton, input)
+}
+
+// ProcessContext processes input through the chain with cancellation.
+// Processors that do not implement ContextProcessor are only interrupted
+// before they start.
+func (p *ProcessorChain[Q, S]) ProcessContext(
+	ctx context.Context,
+	automaton Automaton[Q, S],
+	input []S,
+) (ProcessResult[Q], error) {
 	if len(p.processors) == 0 {
 		return ProcessResult[Q]{}, NewError(ErrorTypeInvalidConfiguration, "no processors in chain")
 	}
 
 	// Use the first processor for actual processing
 	// Others could be used for validation, logging, etc.
-	return p.processors[0].Process(automaton, input)
+	return processContext(ctx, p.processors[0], automaton, input)
 }
 
 // Add adds a processor to the chain.
@@ -0,0 +1,477 @@
+package fsm
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Regular expression syntax accepted by CompileRegex:
+//
+//	ab        concatenation
+//	a|b       alternation (an empty alternative matches the empty input)
+//	a* a+ a?  zero or more, one or more, zero or one
+//	(ab)      grouping; () matches the empty input
+//	[abc]     character class
+//	[a-z0-9]  character ranges
+//	[^abc]    negated class: any alphabet symbol not listed
+//	.         any alphabet symbol
+//	\d \w \s  digits, word characters and whitespace (\D \W \S negated)
+//	\n \t \r  newline, tab, carriage return (also \f and \v)
+//	\*        any other punctuation character escaped as a literal
+//
+// Patterns always match the whole input; a leading ^ and a trailing $ are
+// accepted and ignored. Counted repetition ({m,n}) is not supported.
+
+// regexKind identifies the type of a regular expression syntax tree node.
+type regexKind int
+
+const (
+	regexSet regexKind = iota
+	regexEmpty
+	regexConcat
+	regexAlternate
+	regexStar
+	regexPlus
+	regexOptional
+)
+
+// regexNode is a node of a parsed regular expression.
+type regexNode struct {
+	kind     regexKind
+	runes    []rune
+	negated  bool
+	anyRune  bool
+	children []*regexNode
+}
+
+// regexParser is a recursive descent parser over the runes of a pattern.
+type regexParser struct {
+	pattern []rune
+	source  string
+	pos     int
+	// literals collects every rune mentioned by the pattern
+	literals map[rune]bool
+}
+
+// CompileRegex compiles a regular expression into a deterministic automaton
+// over runes. The alphabet of the result is the set of runes the pattern
+// mentions, so '.' and negated classes match only among those; use
+// CompileRegexWithAlphabet to match against a larger alphabet.
+//
+// Parse errors are returned as an AutomatonError of type
+// ErrorTypeInvalidConfiguration whose Context holds the pattern and the
+// offending position (a rune offset into the pattern).
+func CompileRegex(pattern string) (*FiniteAutomaton[int, rune], error) {
+	return CompileRegexWithAlphabet(pattern, nil)
+}
+
+// CompileRegexWithAlphabet compiles a regular expression like CompileRegex, with
+// the given runes added to the alphabet. '.' and negated classes match any
+// symbol of the resulting alphabet.
+//
+// The result is the minimal deterministic automaton for the pattern, with
+// states numbered 0, 1, 2, ... in breadth-first order from the initial state 0.
+func CompileRegexWithAlphabet(pattern string, alphabet []rune) (*FiniteAutomaton[int, rune], error) {
+	parser := &regexParser{
+		pattern:  []rune(pattern),
+		source:   pattern,
+		literals: make(map[rune]bool),
+	}
+	tree, err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	sigma := make(map[rune]bool, len(parser.literals)+len(alphabet))
+	for symbol := range parser.literals {
+		sigma[symbol] = true
+	}
+	for _, symbol := range alphabet {
+		sigma[symbol] = true
+	}
+	symbols := sortValues(mapKeys(sigma))
+
+	nfa := NewNFA[int, rune](0)
+	nfa.AddSymbols(symbols...)
+	fragment := buildThompson(nfa, tree, symbols)
+	nfa.initialState = fragment.start
+	nfa.AddAcceptingState(fragment.end)
+
+	dfa, err := NewNumberedSubsetConverter[int, rune]().Determinize(nfa)
+	if err != nil {
+		return nil, err
+	}
+	minimized, _, err := Minimize(dfa)
+	if err != nil {
+		return nil, err
+	}
+	return renumberStates(minimized), nil
+}
+
+func (p *regexParser) parse() (*regexNode, error) {
+	if p.peekIs('^') {
+		p.pos++
+	}
+	end := len(p.pattern)
+	if end > p.pos && p.pattern[end-1] == '$' && !p.escapedAt(end-1) {
+		p.pattern = p.pattern[:end-1]
+	}
+
+	node, err := p.parseAlternation()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.pattern) {
+		// The only way to stop early is an unmatched closing parenthesis
+		return nil, p.errorAt(p.pos, "unmatched ')'")
+	}
+	return node, nil
+}
+
+func (p *regexParser) parseAlternation() (*regexNode, error) {
+	branches := make([]*regexNode, 0, 1)
+	for {
+		branch, err := p.parseConcatenation()
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, branch)
+		if !p.peekIs('|') {
+			break
+		}
+		p.pos++
+	}
+	if len(branches) == 1 {
+		return branches[0], nil
+	}
+	return &regexNode{kind: regexAlternate, children: branches}, nil
+}
+
+func (p *regexParser) parseConcatenation() (*regexNode, error) {
+	parts := make([]*regexNode, 0)
+	for p.pos < len(p.pattern) && !p.peekIs('|') && !p.peekIs(')') {
+		part, err := p.parseRepetition()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	switch len(parts) {
+	case 0:
+		return &regexNode{kind: regexEmpty}, nil
+	case 1:
+		return parts[0], nil
+	default:
+		return &regexNode{kind: regexConcat, children: parts}, nil
+	}
+}
+
+func (p *regexParser) parseRepetition() (*regexNode, error) {
+	atom, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	for p.pos < len(p.pattern) {
+		var kind regexKind
+		switch p.pattern[p.pos] {
+		case '*':
+			kind = regexStar
+		case '+':
+			kind = regexPlus
+		case '?':
+			kind = regexOptional
+		default:
+			return atom, nil
+		}
+		p.pos++
+		atom = &regexNode{kind: kind, children: []*regexNode{atom}}
+	}
+	return atom, nil
+}
+
+func (p *regexParser) parseAtom() (*regexNode, error) {
+	start := p.pos
+	r := p.pattern[p.pos]
+	p.pos++
+
+	switch r {
+	case '(':
+		inner, err := p.parseAlternation()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekIs(')') {
+			return nil, p.errorAt(start, "missing closing ')'")
+		}
+		p.pos++
+		return inner, nil
+	case '[':
+		return p.parseClass(start)
+	case '.':
+		return &regexNode{kind: regexSet, anyRune: true}, nil
+	case '\\':
+		return p.parseEscape(start)
+	case '*', '+', '?':
+		return nil, p.errorAt(start, fmt.Sprintf("missing expression before '%c'", r))
+	case '{', '}':
+		return nil, p.errorAt(start, "counted repetition is not supported; escape braces to match them literally")
+	case '^', '$':
+		return nil, p.errorAt(start, fmt.Sprintf("anchor '%c' is only allowed at the start or end of the pattern", r))
+	case ']':
+		return nil, p.errorAt(start, "unmatched ']'")
+	default:
+		return p.literal(r), nil
+	}
+}
+
+func (p *regexParser) parseEscape(start int) (*regexNode, error) {
+	if p.pos >= len(p.pattern) {
+		return nil, p.errorAt(start, "trailing backslash")
+	}
+	r := p.pattern[p.pos]
+	p.pos++
+
+	if class, negated, ok := shorthandClass(r); ok {
+		for _, symbol := range class {
+			p.literals[symbol] = true
+		}
+		return &regexNode{kind: regexSet, runes: class, negated: negated}, nil
+	}
+	if control, ok := controlEscape(r); ok {
+		return p.literal(control), nil
+	}
+	if isRegexLetterOrDigit(r) {
+		return nil, p.errorAt(start, fmt.Sprintf("unknown escape sequence '\\%c'", r))
+	}
+	return p.literal(r), nil
+}
+
+func (p *regexParser) parseClass(start int) (*regexNode, error) {
+	node := &regexNode{kind: regexSet}
+	if p.peekIs('^') {
+		node.negated = true
+		p.pos++
+	}
+
+	for {
+		if p.pos >= len(p.pattern) {
+			return nil, p.errorAt(start, "missing closing ']'")
+		}
+		if p.peekIs(']') {
+			p.pos++
+			break
+		}
+
+		lowPos := p.pos
+		low, class, err := p.classAtom()
+		if err != nil {
+			return nil, err
+		}
+		if class != nil {
+			if p.peekIs('-') && p.pos+1 < len(p.pattern) && p.pattern[p.pos+1] != ']' {
+				return nil, p.errorAt(lowPos, "character class shorthand cannot start a range")
+			}
+			node.runes = append(node.runes, class...)
+			continue
+		}
+
+		// A '-' between two single characters forms a range; elsewhere it is literal
+		if p.peekIs('-') && p.pos+1 < len(p.pattern) && p.pattern[p.pos+1] != ']' {
+			p.pos++
+			highPos := p.pos
+			high, highClass, err := p.classAtom()
+			if err != nil {
+				return nil, err
+			}
+			if highClass != nil {
+				return nil, p.errorAt(highPos, "character class shorthand cannot end a range")
+			}
+			if high < low {
+				return nil, p.errorAt(lowPos, fmt.Sprintf("invalid range %c-%c", low, high))
+			}
+			for symbol := low; symbol <= high; symbol++ {
+				node.runes = append(node.runes, symbol)
+			}
+			continue
+		}
+		node.runes = append(node.runes, low)
+	}
+
+	if len(node.runes) == 0 {
+		return nil, p.errorAt(start, "empty character class")
+	}
+	for _, symbol := range node.runes {
+		p.literals[symbol] = true
+	}
+	return node, nil
+}
+
+// classAtom reads a single character or shorthand class inside brackets.
+func (p *regexParser) classAtom() (rune, []rune, error) {
+	r := p.pattern[p.pos]
+	p.pos++
+	if r != '\\' {
+		return r, nil, nil
+	}
+	if p.pos >= len(p.pattern) {
+		return 0, nil, p.errorAt(p.pos-1, "trailing backslash")
+	}
+	escaped := p.pattern[p.pos]
+	p.pos++
+	if class, negated, ok := shorthandClass(escaped); ok {
+		if negated {
+			return 0, nil, p.errorAt(p.pos-2, fmt.Sprintf("negated shorthand '\\%c' is not supported in a class", escaped))
+		}
+		return 0, class, nil
+	}
+	if control, ok := controlEscape(escaped); ok {
+		return control, nil, nil
+	}
+	if isRegexLetterOrDigit(escaped) {
+		return 0, nil, p.errorAt(p.pos-2, fmt.Sprintf("unknown escape sequence '\\%c'", escaped))
+	}
+	return escaped, nil, nil
+}
+
+func (p *regexParser) literal(r rune) *regexNode {
+	p.literals[r] = true
+	return &regexNode{kind: regexSet, runes: []rune{r}}
+}
+
+func (p *regexParser) peekIs(r rune) bool {
+	return p.pos < len(p.pattern) && p.pattern[p.pos] == r
+}
+
+// escapedAt reports whether the rune at index i is preceded by an odd number of backslashes.
+func (p *regexParser) escapedAt(i int) bool {
+	backslashes := 0
+	for j := i - 1; j >= 0 && p.pattern[j] == '\\'; j-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+func (p *regexParser) errorAt(position int, message string) *AutomatonError {
+	return NewErrorWithContext(ErrorTypeInvalidConfiguration, "invalid regular expression: "+message,
+		map[string]interface{}{
+			"pattern":  p.source,
+			"position": position,
+		})
+}
+
+// shorthandClass expands \d, \w and \s (and their negated upper-case forms).
+func shorthandClass(r rune) ([]rune, bool, bool) {
+	var class []rune
+	switch r {
+	case 'd', 'D':
+		class = runeRange('0', '9')
+	case 'w', 'W':
+		class = slices.Concat(runeRange('0', '9'), runeRange('A', 'Z'), []rune{'_'}, runeRange('a', 'z'))
+	case 's', 'S':
+		class = []rune{'\t', '\n', '\v', '\f', '\r', ' '}
+	default:
+		return nil, false, false
+	}
+	return class, r == 'D' || r == 'W' || r == 'S', true
+}
+
+func controlEscape(r rune) (rune, bool) {
+	switch r {
+	case 'n':
+		return '\n', true
+	case 't':
+		return '\t', true
+	case 'r':
+		return '\r', true
+	case 'f':
+		return '\f', true
+	case 'v':
+		return '\v', true
+	default:
+		return 0, false
+	}
+}
+
+func isRegexLetterOrDigit(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+func runeRange(low, high rune) []rune {
+	runes := make([]rune, 0, high-low+1)
+	for r := low; r <= high; r++ {
+		runes = append(runes, r)
+	}
+	return runes
+}
+
+// thompsonFragment is a partial ε-NFA with a single entry and a single exit state.
+type thompsonFragment struct {
+	start, end int
+}
+
+// buildThompson adds the Thompson construction of the node to the NFA.
+// Sets are resolved against the alphabet, which must be sorted.
+func buildThompson(nfa *NFA[int, rune], node *regexNode, alphabet []rune) thompsonFragment {
+	newState := func() int {
+		state := len(nfa.states)
+		nfa.AddState(state)
+		return state
+	}
+	start, end := newState(), newState()
+
+	switch node.kind {
+	case regexSet:
+		for _, symbol := range resolveSet(node, alphabet) {
+			nfa.AddTransition(start, symbol, end)
+		}
+	case regexEmpty:
+		nfa.AddEpsilonTransition(start, end)
+	case regexConcat:
+		previous := start
+		for _, child := range node.children {
+			fragment := buildThompson(nfa, child, alphabet)
+			nfa.AddEpsilonTransition(previous, fragment.start)
+			previous = fragment.end
+		}
+		nfa.AddEpsilonTransition(previous, end)
+	case regexAlternate:
+		for _, child := range node.children {
+			fragment := buildThompson(nfa, child, alphabet)
+			nfa.AddEpsilonTransition(start, fragment.start)
+			nfa.AddEpsilonTransition(fragment.end, end)
+		}
+	case regexStar, regexPlus, regexOptional:
+		fragment := buildThompson(nfa, node.children[0], alphabet)
+		nfa.AddEpsilonTransition(start, fragment.start)
+		nfa.AddEpsilonTransition(fragment.end, end)
+		if node.kind != regexPlus {
+			nfa.AddEpsilonTransition(start, end)
+		}
+		if node.kind != regexOptional {
+			nfa.AddEpsilonTransition(fragment.end, fragment.start)
+		}
+	}
+
+	return thompsonFragment{start: start, end: end}
+}
+
+// resolveSet returns the symbols a set node matches within the alphabet.
+func resolveSet(node *regexNode, alphabet []rune) []rune {
+	if node.anyRune {
+		return alphabet
+	}
+	if !node.negated {
+		return node.runes
+	}
+	excluded := make(map[rune]bool, len(node.runes))
+	for _, symbol := range node.runes {
+		excluded[symbol] = true
+	}
+	matched := make([]rune, 0, len(alphabet))
+	for _, symbol := range alphabet {
+		if !excluded[symbol] {
+			matched = append(matched, symbol)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,142 @@
+package fsm
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+// wordsOver returns every string over the alphabet up to the given length
+func wordsOver(alphabet string, maxLength int) []string {
+	words := []string{""}
+	for start := 0; start < len(words); start++ {
+		if len([]rune(words[start])) < maxLength {
+			for _, r := range alphabet {
+				words = append(words, words[start]+string(r))
+			}
+		}
+	}
+	return words
+}
+
+// TestCompileRegex compares compiled patterns against the standard library matcher
+func TestCompileRegex(t *testing.T) {
+	patterns := []string{
+		"abc",
+		"a|b",
+		"a*",
+		"(ab)+",
+		"a?b",
+		"(a|b)*abb",
+		"[a-c]+",
+		"[^a]b",
+		"a.c",
+		"(a|)b",
+		"()",
+		"(a*b*)*c?",
+		"^ab|ba$",
+		`\d+`,
+		`a\*b`,
+		"[-a]",
+		"[ab-]c",
+		"((a|b)(c|a))*",
+	}
+
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			fa, err := CompileRegexWithAlphabet(pattern, []rune("abc*1-"))
+			if err != nil {
+				t.Fatalf("CompileRegex(%q) returned error: %v", pattern, err)
+			}
+			reference := regexp.MustCompile(`^(?:` + pattern + `)$`)
+			for _, word := range wordsOver("abc*1-", 4) {
+				if got, want := accepts(t, fa, word), reference.MatchString(word); got != want {
+					t.Errorf("input %q: got %v, want %v", word, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestCompileRegex_Minimal tests that the result is minimal and numbered from 0
+func TestCompileRegex_Minimal(t *testing.T) {
+	fa, err := CompileRegex("(a|b)*abb")
+	if err != nil {
+		t.Fatalf("CompileRegex returned error: %v", err)
+	}
+
+	if fa.GetInitialState() != 0 {
+		t.Errorf("Expected initial state 0, got %d", fa.GetInitialState())
+	}
+	states := sortValues(fa.getStatesList())
+	for i, state := range states {
+		if state != i {
+			t.Fatalf("Expected states numbered 0..n-1, got %v", states)
+		}
+	}
+	if len(states) != 4 {
+		t.Errorf("Expected the minimal 4-state automaton, got %d states", len(states))
+	}
+}
+
+// TestCompileRegex_AlphabetFromPattern tests the default alphabet
+func TestCompileRegex_AlphabetFromPattern(t *testing.T) {
+	fa, err := CompileRegex("[^a]x")
+	if err != nil {
+		t.Fatalf("CompileRegex returned error: %v", err)
+	}
+
+	alphabet := sortValues(fa.getAlphabetList())
+	if string(alphabet) != "ax" {
+		t.Errorf("Expected alphabet \"ax\", got %q", string(alphabet))
+	}
+	if !accepts(t, fa, "xx") || accepts(t, fa, "ax") || accepts(t, fa, "bx") {
+		t.Error("Negated class should match only alphabet symbols outside the class")
+	}
+}
+
+// TestCompileRegex_Errors tests that parse errors report the offending position
+func TestCompileRegex_Errors(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		position int
+	}{
+		{"(ab", 0},
+		{"ab)", 2},
+		{"*a", 0},
+		{"a|+", 2},
+		{"a[bc", 1},
+		{"[]", 0},
+		{"[z-a]", 1},
+		{`ab\`, 2},
+		{`\q`, 0},
+		{"a{2}", 1},
+		{"a^b", 1},
+		{"a]", 1},
+		{`[\d-z]`, 1},
+		{`[a-\d]`, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			_, err := CompileRegex(tt.pattern)
+			if err == nil {
+				t.Fatalf("Expected error for pattern %q", tt.pattern)
+			}
+
+			var automatonErr *AutomatonError
+			if !errors.As(err, &automatonErr) {
+				t.Fatalf("Expected AutomatonError, got %T", err)
+			}
+			if automatonErr.Type != ErrorTypeInvalidConfiguration {
+				t.Errorf("Expected ErrorTypeInvalidConfiguration, got %v", automatonErr.Type)
+			}
+			if automatonErr.Context["position"] != tt.position {
+				t.Errorf("Expected position %d, got %v (%v)", tt.position, automatonErr.Context["position"], err)
+			}
+			if automatonErr.Context["pattern"] != tt.pattern {
+				t.Errorf("Expected pattern %q in context, got %v", tt.pattern, automatonErr.Context["pattern"])
+			}
+		})
+	}
+}
@@ -0,0 +1,404 @@
+package fsm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// TokenKind names a kind of token recognized by a Scanner.
+type TokenKind string
+
+// TokenDefinition describes one kind of token.
+type TokenDefinition[Q State] struct {
+	// Kind is reported in the tokens matched by the automaton
+	Kind TokenKind
+	// Automaton accepts the text of the tokens, such as the result of
+	// CompileRegex. It is compiled when the Scanner is created.
+	Automaton Automaton[Q, rune]
+	// Priority decides between kinds matching the same longest text; the
+	// higher priority wins, then the earlier definition
+	Priority int
+	// Skip drops the tokens, as for whitespace and comments
+	Skip bool
+}
+
+// Position is a location in scanned input.
+type Position struct {
+	// Offset is the byte offset from the start of the input
+	Offset int
+	// Line is the line number, starting at 1
+	Line int
+	// Column is the rune offset from the start of the line, starting at 1
+	Column int
+}
+
+// String returns the position as "line:column".
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Token is a piece of scanned input.
+type Token struct {
+	// Kind is the kind of the token, or empty for an error token
+	Kind TokenKind
+	Text string
+	// Start is the position of the first rune of the token
+	Start Position
+	// End is the position just after the token
+	End Position
+	// Err describes an error token
+	Err error
+}
+
+// IsError reports whether the token is an error token.
+func (t Token) IsError() bool {
+	return t.Err != nil
+}
+
+// String returns the kind, text and position of the token.
+func (t Token) String() string {
+	if t.IsError() {
+		return fmt.Sprintf("%s: error %q", t.Start, t.Text)
+	}
+	return fmt.Sprintf("%s: %s %q", t.Start, t.Kind, t.Text)
+}
+
+// Scanner splits rune streams into tokens by maximal munch: at every position
+// it runs the compiled automaton of every token definition and emits the
+// longest text accepted by any of them, breaking ties by priority. Runs of
+// input that no definition accepts are emitted as error tokens, and scanning
+// resumes after them. A Scanner is immutable and can be shared by any number
+// of Lexers.
+//
+// Example usage:
+//
+//	identifier, _ := CompileRegex(`[a-z]\w*`)
+//	keyword, _ := CompileRegex(`allow|deny`)
+//	space, _ := CompileRegex(`\s+`)
+//	scanner, _ := NewScanner(
+//		TokenDefinition[int]{Kind: "identifier", Automaton: identifier},
+//		TokenDefinition[int]{Kind: "keyword", Automaton: keyword, Priority: 1},
+//		TokenDefinition[int]{Kind: "space", Automaton: space, Skip: true},
+//	)
+//	tokens := scanner.Tokenize("allow admin")
+type Scanner[Q State] struct {
+	definitions []TokenDefinition[Q]
+	machines    []*Machine[Q, rune]
+	// live[i][state] reports whether an accepting state of machine i can be
+	// reached from the state, so that runs entering trap states stop early
+	live [][]bool
+}
+
+// NewScanner compiles the token definitions into a scanner. It fails when a
+// definition has no kind, its automaton cannot be compiled or it accepts the
+// empty input, which would produce empty tokens.
+func NewScanner[Q State](definitions ...TokenDefinition[Q]) (*Scanner[Q], error) {
+	if len(definitions) == 0 {
+		return nil, NewInvalidConfigurationError("scanner", "at least one token definition is required")
+	}
+
+	s := &Scanner[Q]{
+		definitions: append([]TokenDefinition[Q](nil), definitions...),
+		machines:    make([]*Machine[Q, rune], len(definitions)),
+		live:        make([][]bool, len(definitions)),
+	}
+	for i, definition := range definitions {
+		if definition.Kind == "" {
+			return nil, NewInvalidConfigurationError("scanner",
+				fmt.Sprintf("token definition %d has no kind", i))
+		}
+		machine, err := Compile[Q, rune](definition.Automaton)
+		if err != nil {
+			return nil, NewErrorWithCause(ErrorTypeInvalidConfiguration,
+				fmt.Sprintf("cannot compile the automaton of token %s", definition.Kind), err).
+				WithContext("kind", definition.Kind)
+		}
+		if machine.IsAcceptingState(machine.InitialState()) {
+			return nil, NewInvalidConfigurationError("scanner",
+				fmt.Sprintf("token %s matches the empty input", definition.Kind))
+		}
+		s.machines[i] = machine
+		s.live[i] = liveStates(machine)
+	}
+	return s, nil
+}
+
+// liveStates marks the states of a machine from which an accepting state can
+// be reached.
+func liveStates[Q State, S Symbol](m *Machine[Q, S]) []bool {
+	width := len(m.symbols)
+	predecessors := make([][]int, len(m.states))
+	for from := range m.states {
+		for _, to := range m.next[from*width : (from+1)*width] {
+			if to >= 0 {
+				predecessors[to] = append(predecessors[to], from)
+			}
+		}
+	}
+
+	live := make([]bool, len(m.states))
+	var queue []int
+	for state, accepting := range m.accepting {
+		if accepting {
+			live[state] = true
+			queue = append(queue, state)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, from := range predecessors[state] {
+			if !live[from] {
+				live[from] = true
+				queue = append(queue, from)
+			}
+		}
+	}
+	return live
+}
+
+// NewLexer returns a lexer scanning the runes read from r.
+func (s *Scanner[Q]) NewLexer(r io.Reader) *Lexer[Q] {
+	reader, ok := r.(io.RuneReader)
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+	cursors := make([]Cursor[Q, rune], len(s.machines))
+	for i, machine := range s.machines {
+		cursors[i] = machine.NewCursor()
+	}
+	return &Lexer[Q]{
+		scanner:  s,
+		reader:   reader,
+		cursors:  cursors,
+		trails:   make([][]int, len(cursors)),
+		position: Position{Line: 1, Column: 1},
+	}
+}
+
+// Tokenize scans a whole string, skipping the tokens of Skip definitions.
+func (s *Scanner[Q]) Tokenize(input string) []Token {
+	var tokens []Token
+	// Reading from a string never fails.
+	for token := range s.NewLexer(strings.NewReader(input)).All() {
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// lookahead is a rune read but not yet consumed.
+type lookahead struct {
+	r    rune
+	size int
+	// failed holds the runs known not to accept any text ending after this
+	// rune, so that later match attempts reaching them stop at once
+	failed map[run]bool
+}
+
+// run is a token machine in one of its states.
+type run struct {
+	definition int
+	state      int
+}
+
+// Lexer is one scan of a rune stream by a Scanner. Invalid UTF-8 is read as
+// utf8.RuneError, which normally ends up in an error token. A Lexer must not
+// be used by several goroutines at once.
+type Lexer[Q State] struct {
+	scanner *Scanner[Q]
+	reader  io.RuneReader
+	cursors []Cursor[Q, rune]
+	// trails[i] holds the states of cursor i since it last accepted, one per
+	// pending rune
+	trails [][]int
+
+	// pending holds the runes read ahead of the position
+	pending  []lookahead
+	position Position
+	// err is the error that ended reading, io.EOF at the end of the input
+	err error
+}
+
+// Next returns the next token that is not skipped, or io.EOF at the end of the
+// input. Unrecognized input is returned as an error token, not as an error;
+// errors are those of the reader.
+func (l *Lexer[Q]) Next() (Token, error) {
+	for {
+		token, definition, err := l.scan()
+		if err != nil {
+			return Token{}, err
+		}
+		if definition < 0 || !l.scanner.definitions[definition].Skip {
+			return token, nil
+		}
+	}
+}
+
+// All returns an iterator over the remaining tokens, as returned by Next. It
+// stops at the end of the input or after yielding a read error.
+func (l *Lexer[Q]) All() iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		for {
+			token, err := l.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(token, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Position returns the position of the first rune not scanned yet.
+func (l *Lexer[Q]) Position() Position {
+	return l.position
+}
+
+// scan returns the next token, skipped or not, and the index of its
+// definition, or -1 for an error token.
+func (l *Lexer[Q]) scan() (Token, int, error) {
+	length, definition := l.match()
+	if definition >= 0 {
+		start := l.position
+		text := l.consume(length)
+		return Token{
+			Kind:  l.scanner.definitions[definition].Kind,
+			Text:  text,
+			Start: start,
+			End:   l.position,
+		}, definition, nil
+	}
+	if !l.fill(1) {
+		return Token{}, -1, l.err
+	}
+
+	// Extend the error token up to the next position where a token matches.
+	start := l.position
+	var text strings.Builder
+	for {
+		text.WriteString(l.consume(1))
+		if !l.fill(1) {
+			break
+		}
+		if _, definition := l.match(); definition >= 0 {
+			break
+		}
+	}
+	return Token{
+		Text:  text.String(),
+		Start: start,
+		End:   l.position,
+		Err: NewErrorWithContext(ErrorTypeInvalidInput,
+			fmt.Sprintf("unrecognized input %q at %s", text.String(), start),
+			map[string]interface{}{"line": start.Line, "column": start.Column, "offset": start.Offset}),
+	}, -1, nil
+}
+
+// match returns the length of the longest token starting at the position and
+// the index of its definition, or -1 when no token matches.
+//
+// The runs that end without accepting again are remembered on the pending
+// runes, so later attempts stop as soon as they reach one. Without this, input
+// that only matches a token prefix, such as "aaa..." for the token a+b, would
+// be read to its end from every position of an error token.
+func (l *Lexer[Q]) match() (int, int) {
+	for i := range l.cursors {
+		l.cursors[i].Reset()
+		l.trails[i] = l.trails[i][:0]
+	}
+	alive := len(l.cursors)
+	dead := make([]bool, len(l.cursors))
+	// accepted[i] is the number of pending runes read when cursor i last accepted
+	accepted := make([]int, len(l.cursors))
+
+	bestLength, best := 0, -1
+	for n := 0; alive > 0 && l.fill(n+1); n++ {
+		ahead := &l.pending[n]
+		for i := range l.cursors {
+			if dead[i] {
+				continue
+			}
+			_, err := l.cursors[i].Step(ahead.r)
+			state := l.cursors[i].state
+			if err != nil || !l.scanner.live[i][state] || ahead.failed[run{i, state}] {
+				dead[i] = true
+				alive--
+				l.fail(i, accepted[i])
+				continue
+			}
+			if !l.cursors[i].Accepting() {
+				l.trails[i] = append(l.trails[i], state)
+				continue
+			}
+			accepted[i] = n + 1
+			l.trails[i] = l.trails[i][:0]
+			if n+1 > bestLength || l.preferred(i, best) {
+				bestLength, best = n+1, i
+			}
+		}
+	}
+	// The input ended, so the runs still alive cannot accept again either.
+	for i := range l.cursors {
+		if !dead[i] {
+			l.fail(i, accepted[i])
+		}
+	}
+	return bestLength, best
+}
+
+// fail records the states of cursor i after it last accepted, at pending
+// rune from onwards, as runs that cannot accept.
+func (l *Lexer[Q]) fail(i, from int) {
+	for k, state := range l.trails[i] {
+		ahead := &l.pending[from+k]
+		if ahead.failed == nil {
+			ahead.failed = make(map[run]bool)
+		}
+		ahead.failed[run{i, state}] = true
+	}
+}
+
+// preferred reports whether definition i wins over definition j for a text
+// both accept.
+func (l *Lexer[Q]) preferred(i, j int) bool {
+	definitions := l.scanner.definitions
+	return definitions[i].Priority > definitions[j].Priority
+}
+
+// fill reads ahead until n runes are pending, and reports whether they are.
+func (l *Lexer[Q]) fill(n int) bool {
+	for len(l.pending) < n {
+		if l.err != nil {
+			return false
+		}
+		r, size, err := l.reader.ReadRune()
+		if err != nil {
+			// The error is reported once the runes read before it are consumed.
+			l.err = err
+			return false
+		}
+		l.pending = append(l.pending, lookahead{r: r, size: size})
+	}
+	return true
+}
+
+// consume advances the position over n pending runes and returns them.
+func (l *Lexer[Q]) consume(n int) string {
+	var text strings.Builder
+	for _, ahead := range l.pending[:n] {
+		text.WriteRune(ahead.r)
+		l.position.Offset += ahead.size
+		if ahead.r == '\n' {
+			l.position.Line++
+			l.position.Column = 1
+		} else {
+			l.position.Column++
+		}
+	}
+	l.pending = l.pending[n:]
+	return text.String()
+}
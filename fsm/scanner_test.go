@@ -0,0 +1,271 @@
+package fsm
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// newPolicyScanner returns a scanner for a small policy expression language
+func newPolicyScanner(t *testing.T) *Scanner[int] {
+	t.Helper()
+	patterns := []struct {
+		kind     TokenKind
+		pattern  string
+		priority int
+		skip     bool
+	}{
+		{"identifier", `[a-zé_]\w*`, 0, false},
+		{"keyword", `allow|deny|and|or`, 1, false},
+		{"number", `\d+`, 0, false},
+		{"operator", `<|<=|==|!=|>|>=`, 0, false},
+		{"string", `"[^"\n]*"`, 0, false},
+		{"space", `\s+`, 0, true},
+	}
+	definitions := make([]TokenDefinition[int], len(patterns))
+	for i, p := range patterns {
+		automaton, err := CompileRegexWithAlphabet(p.pattern, []rune(" \t\n\"abcdefghijklmnopqrstuvwxyz_0123456789<=>!é"))
+		if err != nil {
+			t.Fatalf("CompileRegex(%s) returned error: %v", p.pattern, err)
+		}
+		definitions[i] = TokenDefinition[int]{Kind: p.kind, Automaton: automaton, Priority: p.priority, Skip: p.skip}
+	}
+	scanner, err := NewScanner(definitions...)
+	if err != nil {
+		t.Fatalf("NewScanner returned error: %v", err)
+	}
+	return scanner
+}
+
+// describe returns the tokens as "kind:text" separated by spaces
+func describe(tokens []Token) string {
+	parts := make([]string, len(tokens))
+	for i, token := range tokens {
+		kind := string(token.Kind)
+		if token.IsError() {
+			kind = "error"
+		}
+		parts[i] = kind + ":" + token.Text
+	}
+	return strings.Join(parts, " ")
+}
+
+// TestScanner_Tokenize tests maximal munch and priorities
+func TestScanner_Tokenize(t *testing.T) {
+	scanner := newPolicyScanner(t)
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`allow level>=3`, "keyword:allow identifier:level operator:>= number:3"},
+		{`allowed or denied`, "identifier:allowed keyword:or identifier:denied"},
+		{`a<b <= c`, "identifier:a operator:< identifier:b operator:<= identifier:c"},
+		{`name == "x y"`, `identifier:name operator:== string:"x y"`},
+		{`12ab`, "number:12 identifier:ab"},
+		{``, ""},
+	}
+	for _, tt := range tests {
+		if got := describe(scanner.Tokenize(tt.input)); got != tt.want {
+			t.Errorf("Tokenize(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestScanner_ErrorTokens tests that unrecognized input is reported and skipped
+func TestScanner_ErrorTokens(t *testing.T) {
+	scanner := newPolicyScanner(t)
+	tokens := scanner.Tokenize("allow @#admin\n\"open !")
+	if got := describe(tokens); got != `keyword:allow error:@# identifier:admin error:" identifier:open error:!` {
+		t.Fatalf("Unexpected tokens %s", got)
+	}
+
+	errorToken := tokens[1]
+	var automatonErr *AutomatonError
+	if !errors.As(errorToken.Err, &automatonErr) || automatonErr.Type != ErrorTypeInvalidInput || automatonErr.Context["column"] != 7 {
+		t.Errorf("Unexpected error %v", errorToken.Err)
+	}
+	if errorToken.Kind != "" || errorToken.Start.String() != "1:7" || errorToken.End.String() != "1:9" {
+		t.Errorf("Unexpected error token %s ending at %s", errorToken, errorToken.End)
+	}
+}
+
+// TestScanner_Positions tests line, column and byte offset tracking
+func TestScanner_Positions(t *testing.T) {
+	scanner := newPolicyScanner(t)
+	tokens := scanner.Tokenize("allow\n  é_1 or\n\tx")
+	want := []struct {
+		text         string
+		line, column int
+		offset       int
+	}{
+		{"allow", 1, 1, 0},
+		{"é_1", 2, 3, 8},
+		{"or", 2, 7, 13},
+		{"x", 3, 2, 17},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("Got tokens %s", describe(tokens))
+	}
+	for i, w := range want {
+		token := tokens[i]
+		if token.Text != w.text || token.Start.Line != w.line || token.Start.Column != w.column || token.Start.Offset != w.offset {
+			t.Errorf("Token %d = %s at offset %d, want %q at %d:%d offset %d",
+				i, token, token.Start.Offset, w.text, w.line, w.column, w.offset)
+		}
+	}
+	if end := tokens[1].End; end.Column != 6 || end.Offset != 12 {
+		t.Errorf("Unexpected end %s at offset %d", end, end.Offset)
+	}
+}
+
+// TestLexer tests streaming tokens from a reader, including read errors
+func TestLexer(t *testing.T) {
+	scanner := newPolicyScanner(t)
+	reader := io.MultiReader(iotest.OneByteReader(strings.NewReader("deny x1 ")), iotest.ErrReader(io.ErrUnexpectedEOF))
+	lexer := scanner.NewLexer(reader)
+
+	var kinds []TokenKind
+	var err error
+	for token, tokenErr := range lexer.All() {
+		if tokenErr != nil {
+			err = tokenErr
+			break
+		}
+		kinds = append(kinds, token.Kind)
+	}
+	if len(kinds) != 2 || kinds[0] != "keyword" || kinds[1] != "identifier" {
+		t.Errorf("Unexpected kinds %v", kinds)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected the read error, got %v", err)
+	}
+	if lexer.Position().Column != 9 {
+		t.Errorf("Unexpected position %s", lexer.Position())
+	}
+
+	lexer = scanner.NewLexer(strings.NewReader("or"))
+	if token, err := lexer.Next(); err != nil || token.Kind != "keyword" {
+		t.Errorf("Next = %s, %v; want keyword", token, err)
+	}
+	if _, err := lexer.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+// TestScanner_Ties tests that the earlier definition wins between equal priorities
+func TestScanner_Ties(t *testing.T) {
+	first, _ := CompileRegex(`ab`)
+	second, _ := CompileRegex(`a\w`)
+	scanner, err := NewScanner(
+		TokenDefinition[int]{Kind: "first", Automaton: first},
+		TokenDefinition[int]{Kind: "second", Automaton: second},
+	)
+	if err != nil {
+		t.Fatalf("NewScanner returned error: %v", err)
+	}
+	if got := describe(scanner.Tokenize("abab")); got != "first:ab first:ab" {
+		t.Errorf("Tokenize = %s", got)
+	}
+}
+
+// TestNewScanner_Errors tests rejected definitions
+func TestNewScanner_Errors(t *testing.T) {
+	word, _ := CompileRegex(`a+`)
+	optional, _ := CompileRegex(`a*`)
+
+	tests := map[string][]TokenDefinition[int]{
+		"no definitions": nil,
+		"no kind":        {{Automaton: word}},
+		"empty match":    {{Kind: "word", Automaton: word}, {Kind: "optional", Automaton: optional}},
+		"no automaton":   {{Kind: "word"}},
+	}
+	for name, definitions := range tests {
+		if _, err := NewScanner(definitions...); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// countingRuneReader counts the runes read through it
+type countingRuneReader struct {
+	*strings.Reader
+	runes int
+}
+
+func (r *countingRuneReader) ReadRune() (rune, int, error) {
+	r.runes++
+	return r.Reader.ReadRune()
+}
+
+// TestScanner_TrapStates tests that runs stop as soon as they enter a trap state
+func TestScanner_TrapStates(t *testing.T) {
+	pair, err := CompileRegexWithAlphabet(`ab`, []rune("x"))
+	if err != nil {
+		t.Fatalf("CompileRegex returned error: %v", err)
+	}
+	completed, err := Complete[int, rune](pair, -1)
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+	scanner, err := NewScanner(TokenDefinition[int]{Kind: "pair", Automaton: completed})
+	if err != nil {
+		t.Fatalf("NewScanner returned error: %v", err)
+	}
+
+	reader := &countingRuneReader{Reader: strings.NewReader("ab" + strings.Repeat("ab", 5000) + strings.Repeat("x", 5000))}
+	lexer := scanner.NewLexer(reader)
+	if token, err := lexer.Next(); err != nil || token.Text != "ab" {
+		t.Fatalf("Next = %s, %v; want pair \"ab\"", token, err)
+	}
+	if reader.runes > 3 {
+		t.Errorf("Read %d runes ahead for a two-rune token", reader.runes)
+	}
+
+	tokens := 1
+	for token, err := range lexer.All() {
+		if err != nil {
+			t.Fatalf("All returned error: %v", err)
+		}
+		tokens++
+		if token.IsError() && len(token.Text) != 5000 {
+			t.Errorf("Unexpected error token of %d runes", len(token.Text))
+		}
+	}
+	if tokens != 5002 || reader.runes > 20002 {
+		t.Errorf("Got %d tokens reading %d runes", tokens, reader.runes)
+	}
+}
+
+// TestScanner_LinearErrorTokens tests that input matching only token prefixes
+// is scanned in linear time
+func TestScanner_LinearErrorTokens(t *testing.T) {
+	prefix, _ := CompileRegex(`a+b`)
+	word, _ := CompileRegex(`[a-c]+c`)
+	scanner, err := NewScanner(
+		TokenDefinition[int]{Kind: "ab", Automaton: prefix},
+		TokenDefinition[int]{Kind: "word", Automaton: word},
+	)
+	if err != nil {
+		t.Fatalf("NewScanner returned error: %v", err)
+	}
+
+	// Scanning quadratically would take minutes.
+	input := strings.Repeat("a", 200000) + "b" + strings.Repeat("a", 200000)
+	done := make(chan []Token, 1)
+	go func() { done <- scanner.Tokenize(input) }()
+	select {
+	case tokens := <-done:
+		if len(tokens) != 2 || tokens[0].Kind != "ab" || len(tokens[1].Text) != 200000 || !tokens[1].IsError() {
+			t.Errorf("Unexpected tokens %s", describe(tokens[:min(len(tokens), 3)]))
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Tokenize did not finish in 10s")
+	}
+
+	if got := describe(scanner.Tokenize("aacaab")); got != "word:aac ab:aab" {
+		t.Errorf("Tokenize = %s", got)
+	}
+}
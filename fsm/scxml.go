@@ -0,0 +1,437 @@
+package fsm
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SCXML namespaces. Attributes in the extension namespace carry the parts of
+// a FiniteAutomaton that SCXML has no notion of.
+const (
+	SCXMLNamespace          = "http://www.w3.org/2005/07/scxml"
+	SCXMLExtensionNamespace = "https://github.com/dsonic0912/PolicyReporter-FSM/scxml"
+)
+
+// SCXMLSerializer reads and writes W3C SCXML documents for automata with
+// string states and string events (implements Serializer interface).
+//
+// The mapping covers the flat, event-driven subset of SCXML:
+//
+//   - <state id> and <final id> become states; <final> states are accepting
+//   - <transition event target> becomes a transition on each listed event
+//   - the initial attribute of <scxml> (or the first state) is the initial state
+//
+// Accepting states with outgoing transitions are written as <state> with
+// fsm:accepting="true", since SCXML final states cannot have transitions.
+// Symbols used by no transition are listed in the fsm:alphabet attribute of
+// the root element. Events are matched exactly; SCXML's prefix matching of
+// event descriptors is not modelled.
+//
+// Features that cannot be represented are reported as errors rather than
+// dropped: executable content (<onentry>, <onexit>, <script>, <raise>, ...),
+// data models, <parallel>, <history>, <invoke>, compound states, guarded
+// (cond), targetless, eventless or wildcard transitions, and conflicting
+// transitions on the same event. Errors are of type ErrorTypeValidation and
+// carry the "line" and "column" of the offending element in their Context.
+type SCXMLSerializer struct {
+	definitionCodecs[string, string]
+}
+
+// NewSCXMLSerializer creates an SCXML serializer using the default validator configuration.
+func NewSCXMLSerializer() *SCXMLSerializer {
+	return &SCXMLSerializer{
+		definitionCodecs: newDefinitionCodecs(DefaultCodec[string](), DefaultCodec[string]()),
+	}
+}
+
+// WithValidator replaces the validator run on deserialized automata.
+func (s *SCXMLSerializer) WithValidator(validator *InputValidator[string, string]) *SCXMLSerializer {
+	s.validator = validator
+	return s
+}
+
+// Serialize encodes the automaton as an SCXML document (implements Serializer interface).
+func (s *SCXMLSerializer) Serialize(automaton Automaton[string, string]) ([]byte, error) {
+	definition, err := s.encode(automaton)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, state := range append([]string{definition.InitialState}, definition.States...) {
+		if state == "" || strings.ContainsAny(state, " \t\r\n") {
+			return nil, NewInvalidConfigurationError("scxml",
+				fmt.Sprintf("state %q is not a valid SCXML id", state))
+		}
+	}
+	used := make(map[string]bool)
+	outgoing := make(map[string][]TransitionDefinition)
+	for _, transition := range definition.Transitions {
+		outgoing[transition.From] = append(outgoing[transition.From], transition)
+		used[transition.Symbol] = true
+	}
+	var unused []string
+	for _, symbol := range definition.Alphabet {
+		if symbol == "" || strings.ContainsAny(symbol, " \t\r\n*") {
+			return nil, NewInvalidConfigurationError("scxml",
+				fmt.Sprintf("symbol %q is not a valid SCXML event name", symbol))
+		}
+		if !used[symbol] {
+			unused = append(unused, symbol)
+		}
+	}
+	accepting := make(map[string]bool)
+	for _, state := range definition.AcceptingStates {
+		accepting[state] = true
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString(xml.Header)
+	fmt.Fprintf(&buffer, `<scxml xmlns="%s" xmlns:fsm="%s" version="1.0" initial="%s"`,
+		SCXMLNamespace, SCXMLExtensionNamespace, escapeXML(definition.InitialState))
+	if len(unused) > 0 {
+		fmt.Fprintf(&buffer, ` fsm:alphabet="%s"`, escapeXML(strings.Join(unused, " ")))
+	}
+	buffer.WriteString(">\n")
+
+	for _, state := range definition.States {
+		transitions := outgoing[state]
+		id := escapeXML(state)
+		switch {
+		case accepting[state] && len(transitions) == 0:
+			fmt.Fprintf(&buffer, "  <final id=\"%s\"/>\n", id)
+			continue
+		case accepting[state]:
+			fmt.Fprintf(&buffer, "  <state id=\"%s\" fsm:accepting=\"true\"", id)
+		default:
+			fmt.Fprintf(&buffer, "  <state id=\"%s\"", id)
+		}
+		if len(transitions) == 0 {
+			buffer.WriteString("/>\n")
+			continue
+		}
+		buffer.WriteString(">\n")
+		for _, transition := range transitions {
+			fmt.Fprintf(&buffer, "    <transition event=\"%s\" target=\"%s\"/>\n",
+				escapeXML(transition.Symbol), escapeXML(transition.To))
+		}
+		buffer.WriteString("  </state>\n")
+	}
+	buffer.WriteString("</scxml>\n")
+	return buffer.Bytes(), nil
+}
+
+func escapeXML(text string) string {
+	var builder strings.Builder
+	_ = xml.EscapeText(&builder, []byte(text))
+	return builder.String()
+}
+
+// Deserialize decodes an SCXML document (implements Serializer interface).
+func (s *SCXMLSerializer) Deserialize(data []byte) (Automaton[string, string], error) {
+	return s.DeserializeAutomaton(data)
+}
+
+// DeserializeAutomaton decodes an SCXML document into a FiniteAutomaton.
+func (s *SCXMLSerializer) DeserializeAutomaton(data []byte) (*FiniteAutomaton[string, string], error) {
+	reader := &scxmlReader{
+		decoder:    xml.NewDecoder(bytes.NewReader(data)),
+		definition: &AutomatonDefinition{Version: DefinitionFormatVersion},
+		positions:  newSourcePositions(),
+		ids:        make(map[string]bool),
+		events:     make(map[string]bool),
+		targets:    make(map[[2]string]string),
+	}
+	if err := reader.readDocument(); err != nil {
+		return nil, err
+	}
+
+	fa, err := s.decode(reader.definition)
+	if err != nil {
+		return nil, s.locate(reader.positions, err)
+	}
+	return fa, nil
+}
+
+// scxmlReader converts an SCXML token stream into an AutomatonDefinition.
+type scxmlReader struct {
+	decoder    *xml.Decoder
+	definition *AutomatonDefinition
+	positions  *sourcePositions
+	ids        map[string]bool
+	events     map[string]bool
+	// targets maps (state, event) to the target of the transition already read
+	targets map[[2]string]string
+}
+
+// scxmlElement is a start element along with the position of its '<'.
+type scxmlElement struct {
+	xml.StartElement
+	position sourcePosition
+}
+
+func (e scxmlElement) attr(space, local string) (string, bool) {
+	for _, attr := range e.Attr {
+		inSpace := attr.Name.Space == space || (space == "" && attr.Name.Space == SCXMLNamespace)
+		if attr.Name.Local == local && inSpace {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+func (e scxmlElement) errorf(format string, args ...interface{}) *AutomatonError {
+	return NewErrorWithContext(ErrorTypeValidation, "invalid SCXML document: "+fmt.Sprintf(format, args...),
+		map[string]interface{}{
+			"element": e.Name.Local,
+			"line":    e.position.line,
+			"column":  e.position.column,
+		})
+}
+
+// next returns the next child element of the current element, or false at its end.
+// Comments and processing instructions are skipped, as are elements from
+// foreign namespaces; any other text is an error.
+func (r *scxmlReader) next(parent scxmlElement) (scxmlElement, bool, error) {
+	for {
+		line, column := r.decoder.InputPos()
+		token, err := r.decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return scxmlElement{}, false, nil
+			}
+			return scxmlElement{}, false, scxmlSyntaxError(err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := scxmlElement{StartElement: t.Copy(), position: sourcePosition{line, column}}
+			if t.Name.Space != "" && t.Name.Space != SCXMLNamespace {
+				if err := r.decoder.Skip(); err != nil {
+					return scxmlElement{}, false, scxmlSyntaxError(err)
+				}
+				continue
+			}
+			return element, true, nil
+		case xml.EndElement:
+			return scxmlElement{}, false, nil
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				text := scxmlElement{StartElement: parent.StartElement, position: sourcePosition{line, column}}
+				return scxmlElement{}, false, text.errorf("unexpected text %q in <%s>",
+					strings.TrimSpace(string(t)), parent.Name.Local)
+			}
+		}
+	}
+}
+
+func scxmlSyntaxError(err error) *AutomatonError {
+	automatonErr := NewErrorWithCause(ErrorTypeValidation, "malformed SCXML document", err)
+	var syntaxErr *xml.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		automatonErr.WithContext("line", syntaxErr.Line)
+	}
+	return automatonErr
+}
+
+func (r *scxmlReader) readDocument() error {
+	root, found, err := r.next(scxmlElement{})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return NewErrorWithContext(ErrorTypeValidation, "invalid SCXML document: missing <scxml> element",
+			map[string]interface{}{"line": 1, "column": 1})
+	}
+	if root.Name.Local != "scxml" {
+		return root.errorf("root element must be <scxml>, found <%s>", root.Name.Local)
+	}
+	if version, ok := root.attr("", "version"); ok && version != "1.0" {
+		return root.errorf("unsupported SCXML version %q", version)
+	}
+
+	initial, hasInitial := root.attr("", "initial")
+	if hasInitial {
+		if len(strings.Fields(initial)) != 1 {
+			return root.errorf("initial must name exactly one state")
+		}
+		r.definition.InitialState = initial
+		r.positions.fields["initialState"] = root.position
+		r.positions.declareState(initial, root.position)
+	}
+	if alphabet, ok := root.attr(SCXMLExtensionNamespace, "alphabet"); ok {
+		for _, symbol := range strings.Fields(alphabet) {
+			r.addSymbol(symbol, root.position)
+		}
+	}
+
+	for {
+		child, found, err := r.next(root)
+		if err != nil {
+			return err
+		}
+		if !found {
+			break
+		}
+		switch child.Name.Local {
+		case "state", "final":
+			if err := r.readState(child); err != nil {
+				return err
+			}
+			if !hasInitial {
+				r.definition.InitialState = r.definition.States[0]
+				r.positions.fields["initialState"] = child.position
+				hasInitial = true
+			}
+		default:
+			return unsupportedSCXML(child)
+		}
+	}
+
+	// Anything after the root element must be whitespace, comments or processing instructions
+	if extra, found, err := r.next(root); err != nil {
+		return err
+	} else if found {
+		return extra.errorf("unexpected element <%s> after <scxml>", extra.Name.Local)
+	}
+	return nil
+}
+
+func (r *scxmlReader) readState(element scxmlElement) error {
+	isFinal := element.Name.Local == "final"
+	id, ok := element.attr("", "id")
+	if !ok || id == "" {
+		return element.errorf("<%s> must have an id", element.Name.Local)
+	}
+	if r.ids[id] {
+		return element.errorf("duplicate state id %q", id)
+	}
+	if _, ok := element.attr("", "initial"); ok {
+		return element.errorf("compound states are not supported")
+	}
+	r.ids[id] = true
+
+	index := len(r.definition.States)
+	r.definition.States = append(r.definition.States, id)
+	r.positions.states[id] = element.position
+	r.positions.fields[fmt.Sprintf("states[%d]", index)] = element.position
+
+	accepting := isFinal
+	if value, ok := element.attr(SCXMLExtensionNamespace, "accepting"); ok {
+		switch value {
+		case "true":
+			accepting = true
+		case "false":
+			if isFinal {
+				return element.errorf("<final> states are always accepting")
+			}
+		default:
+			return element.errorf("fsm:accepting must be true or false, got %q", value)
+		}
+	}
+	if accepting {
+		field := fmt.Sprintf("acceptingStates[%d]", len(r.definition.AcceptingStates))
+		r.positions.fields[field] = element.position
+		r.definition.AcceptingStates = append(r.definition.AcceptingStates, id)
+	}
+
+	for {
+		child, found, err := r.next(element)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return nil
+		}
+		switch child.Name.Local {
+		case "transition":
+			if isFinal {
+				return child.errorf("<final> states cannot have transitions")
+			}
+			if err := r.readTransition(id, element, child); err != nil {
+				return err
+			}
+		case "state", "final", "parallel", "initial", "history":
+			return child.errorf("compound states are not supported (<%s> inside <%s>)",
+				child.Name.Local, element.Name.Local)
+		default:
+			return unsupportedSCXML(child)
+		}
+	}
+}
+
+func (r *scxmlReader) readTransition(from string, state, element scxmlElement) error {
+	if _, ok := element.attr("", "cond"); ok {
+		return element.errorf("guarded transitions (cond) are not supported")
+	}
+	event, ok := element.attr("", "event")
+	events := strings.Fields(event)
+	if !ok || len(events) == 0 {
+		return element.errorf("eventless transitions are not supported")
+	}
+	target, ok := element.attr("", "target")
+	targets := strings.Fields(target)
+	if !ok || len(targets) == 0 {
+		return element.errorf("targetless transitions are not supported")
+	}
+	if len(targets) > 1 {
+		return element.errorf("transitions to multiple targets are not supported")
+	}
+	target = targets[0]
+
+	for _, event := range events {
+		if event == "*" || strings.HasSuffix(event, ".*") {
+			return element.errorf("wildcard event %q is not supported", event)
+		}
+		key := [2]string{from, event}
+		if existing, exists := r.targets[key]; exists && existing != target {
+			return element.errorf("conflicting transitions from %q on %q", from, event)
+		}
+		r.targets[key] = target
+
+		field := fmt.Sprintf("transitions[%d]", len(r.definition.Transitions))
+		r.positions.fields[field] = element.position
+		r.positions.fields[field+".from"] = state.position
+		r.positions.fields[field+".symbol"] = element.position
+		r.positions.fields[field+".to"] = element.position
+		r.positions.declareState(target, element.position)
+		r.addSymbol(event, element.position)
+		r.definition.Transitions = append(r.definition.Transitions, TransitionDefinition{
+			From: from, Symbol: event, To: target,
+		})
+	}
+
+	// Transitions may only contain executable content, none of which is supported
+	if child, found, err := r.next(element); err != nil {
+		return err
+	} else if found {
+		return child.errorf("executable content <%s> is not supported", child.Name.Local)
+	}
+	return nil
+}
+
+func (r *scxmlReader) addSymbol(symbol string, position sourcePosition) {
+	if !r.events[symbol] {
+		r.events[symbol] = true
+		r.definition.Alphabet = append(r.definition.Alphabet, symbol)
+	}
+	r.positions.declareSymbol(symbol, position)
+}
+
+func unsupportedSCXML(element scxmlElement) *AutomatonError {
+	switch element.Name.Local {
+	case "parallel":
+		return element.errorf("parallel states are not supported")
+	case "datamodel", "data":
+		return element.errorf("data models are not supported")
+	case "onentry", "onexit", "script", "raise", "send", "assign", "log", "if", "foreach", "cancel":
+		return element.errorf("executable content <%s> is not supported", element.Name.Local)
+	case "invoke", "donedata", "history", "initial":
+		return element.errorf("<%s> is not supported", element.Name.Local)
+	default:
+		return element.errorf("unknown SCXML element <%s>", element.Name.Local)
+	}
+}
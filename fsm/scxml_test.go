@@ -0,0 +1,187 @@
+package fsm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newTurnstileAutomaton returns a turnstile that accepts while unlocked or once broken
+func newTurnstileAutomaton() *FiniteAutomaton[string, string] {
+	return New[string, string]("locked").
+		AddStates("locked", "unlocked", "broken").
+		AddSymbols("coin", "push", "break", "inspect").
+		AddAcceptingStates("unlocked", "broken").
+		AddTransition("locked", "coin", "unlocked").
+		AddTransition("locked", "push", "locked").
+		AddTransition("locked", "break", "broken").
+		AddTransition("unlocked", "coin", "unlocked").
+		AddTransition("unlocked", "push", "locked")
+}
+
+// TestSCXMLSerializer_Serialize tests the exact output format
+func TestSCXMLSerializer_Serialize(t *testing.T) {
+	data, err := NewSCXMLSerializer().Serialize(newTurnstileAutomaton())
+	if err != nil {
+		t.Fatalf("Serialize returned error: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<scxml xmlns="http://www.w3.org/2005/07/scxml" xmlns:fsm="https://github.com/dsonic0912/PolicyReporter-FSM/scxml"` +
+		` version="1.0" initial="locked" fsm:alphabet="inspect">
+  <final id="broken"/>
+  <state id="locked">
+    <transition event="break" target="broken"/>
+    <transition event="coin" target="unlocked"/>
+    <transition event="push" target="locked"/>
+  </state>
+  <state id="unlocked" fsm:accepting="true">
+    <transition event="coin" target="unlocked"/>
+    <transition event="push" target="locked"/>
+  </state>
+</scxml>
+`
+	if string(data) != want {
+		t.Errorf("Serialize() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+// TestSCXMLSerializer_RoundTrip tests that written documents read back unchanged
+func TestSCXMLSerializer_RoundTrip(t *testing.T) {
+	original := newTurnstileAutomaton()
+	serializer := NewSCXMLSerializer()
+
+	data, err := serializer.Serialize(original)
+	if err != nil {
+		t.Fatalf("Serialize returned error: %v", err)
+	}
+	restored, err := serializer.DeserializeAutomaton(data)
+	if err != nil {
+		t.Fatalf("DeserializeAutomaton returned error: %v", err)
+	}
+
+	json := NewJSONSerializer[string, string]()
+	want, _ := json.Serialize(original)
+	got, _ := json.Serialize(restored)
+	if string(got) != string(want) {
+		t.Errorf("Round trip changed the automaton:\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestSCXMLSerializer_Deserialize tests documents written by other tools
+func TestSCXMLSerializer_Deserialize(t *testing.T) {
+	document := `<?xml version="1.0"?>
+<!-- exported by another tool -->
+<scxml xmlns="http://www.w3.org/2005/07/scxml" version="1.0" datamodel="null"
+       xmlns:viz="http://example.com/layout">
+  <viz:layout x="10" y="20"><viz:node/></viz:layout>
+  <state id="idle">
+    <transition event="start resume" target="running"/>
+  </state>
+  <state id="running">
+    <transition event="stop" target="done" type="external"/>
+  </state>
+  <final id="done"/>
+</scxml>`
+
+	fa, err := NewSCXMLSerializer().DeserializeAutomaton([]byte(document))
+	if err != nil {
+		t.Fatalf("DeserializeAutomaton returned error: %v", err)
+	}
+
+	if fa.GetInitialState() != "idle" {
+		t.Errorf("Expected the first state to be initial, got %q", fa.GetInitialState())
+	}
+	for _, input := range [][]string{{"start", "stop"}, {"resume", "stop"}} {
+		if accepted, err := fa.ProcessInput(input); err != nil || !accepted {
+			t.Errorf("Expected %v to be accepted, got %v (%v)", input, accepted, err)
+		}
+	}
+}
+
+// TestSCXMLSerializer_Unsupported tests that unsupported features are reported with positions
+func TestSCXMLSerializer_Unsupported(t *testing.T) {
+	wrap := func(body string) string {
+		return `<scxml xmlns="http://www.w3.org/2005/07/scxml" version="1.0">` + "\n" + body + "\n</scxml>"
+	}
+
+	tests := []struct {
+		name     string
+		document string
+		line     int
+		column   int
+		message  string
+	}{
+		{"parallel", wrap(`  <parallel id="p"/>`), 2, 3, "parallel"},
+		{"datamodel", wrap(`  <datamodel/>`), 2, 3, "data model"},
+		{"onentry", wrap(`  <state id="a">
+    <onentry><log expr="'hi'"/></onentry>
+  </state>`), 3, 5, "executable content"},
+		{"transition content", wrap(`  <state id="a">
+    <transition event="e" target="a"><raise event="f"/></transition>
+  </state>`), 3, 38, "executable content"},
+		{"cond", wrap(`  <state id="a"><transition event="e" cond="x" target="a"/></state>`), 2, 17, "cond"},
+		{"eventless", wrap(`  <state id="a"><transition target="a"/></state>`), 2, 17, "eventless"},
+		{"targetless", wrap(`  <state id="a"><transition event="e"/></state>`), 2, 17, "targetless"},
+		{"wildcard", wrap(`  <state id="a"><transition event="error.*" target="a"/></state>`), 2, 17, "wildcard"},
+		{"compound", wrap(`  <state id="a">
+    <state id="b"/>
+  </state>`), 3, 5, "compound"},
+		{"final transition", wrap(`  <final id="a"><transition event="e" target="a"/></final>`), 2, 17, "final"},
+		{"conflict", wrap(`  <state id="a">
+    <transition event="e" target="a"/>
+    <transition event="e" target="b"/>
+  </state>
+  <state id="b"/>`), 4, 5, "conflicting"},
+		{"duplicate id", wrap(`  <state id="a"/>
+  <final id="a"/>`), 3, 3, "duplicate"},
+		{"text", wrap(`  <state id="a">hello</state>`), 2, 17, "unexpected text"},
+		{"undeclared target", wrap(`  <state id="a">
+    <transition event="e" target="nowhere"/>
+  </state>`), 3, 5, "not in the set of states"},
+		{"wrong root", `<statechart/>`, 1, 1, "root element"},
+	}
+
+	serializer := NewSCXMLSerializer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := serializer.Deserialize([]byte(tt.document))
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !IsValidationError(err) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error mentioning %q, got %v", tt.message, err)
+			}
+			var automatonErr *AutomatonError
+			errors.As(err, &automatonErr)
+			if automatonErr.Context["line"] != tt.line || automatonErr.Context["column"] != tt.column {
+				t.Errorf("Expected position %d:%d, got %v:%v", tt.line, tt.column,
+					automatonErr.Context["line"], automatonErr.Context["column"])
+			}
+		})
+	}
+}
+
+// TestSCXMLSerializer_Malformed tests XML syntax errors
+func TestSCXMLSerializer_Malformed(t *testing.T) {
+	_, err := NewSCXMLSerializer().Deserialize([]byte("<scxml>\n<state id=\"a\">\n</scxml>"))
+	if !IsValidationError(err) {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+}
+
+// TestSCXMLSerializer_InvalidNames tests names that SCXML cannot represent
+func TestSCXMLSerializer_InvalidNames(t *testing.T) {
+	withSpace := New[string, string]("a b").AddSymbols("e")
+	if _, err := NewSCXMLSerializer().Serialize(withSpace); err == nil {
+		t.Error("Expected error for a state id containing a space")
+	}
+
+	wildcard := New[string, string]("a").AddSymbols("*")
+	if _, err := NewSCXMLSerializer().Serialize(wildcard); err == nil {
+		t.Error("Expected error for a wildcard event name")
+	}
+}
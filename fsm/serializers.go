@@ -0,0 +1,500 @@
+package fsm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+)
+
+// DefinitionFormatVersion is the version of the serialized definition format.
+const DefinitionFormatVersion = 1
+
+// Codec converts state or symbol values to and from their textual form in
+// serialized definitions. Encode must be injective: distinct values must
+// produce distinct strings.
+type Codec[T comparable] struct {
+	Encode func(T) (string, error)
+	Decode func(string) (T, error)
+}
+
+// DefaultCodec returns the codec used when none is configured. It supports
+// string kinds (written as-is), int32 kinds (runes, written as a single
+// character, or in decimal when the value is not a valid rune) and all other
+// integer kinds (written in decimal). Values of other types fail to encode and
+// decode with an error asking for an explicit codec.
+func DefaultCodec[T comparable]() Codec[T] {
+	typ := reflect.TypeFor[T]()
+	value := func() reflect.Value { return reflect.New(typ).Elem() }
+
+	switch typ.Kind() {
+	case reflect.String:
+		return Codec[T]{
+			Encode: func(v T) (string, error) { return reflect.ValueOf(v).String(), nil },
+			Decode: func(text string) (T, error) {
+				decoded := value()
+				decoded.SetString(text)
+				return decoded.Interface().(T), nil
+			},
+		}
+	case reflect.Int32:
+		return Codec[T]{
+			Encode: func(v T) (string, error) {
+				r := rune(reflect.ValueOf(v).Int())
+				if !utf8.ValidRune(r) {
+					// Every valid rune is written as one character, so the
+					// decimal forms of the others cannot collide with them.
+					return strconv.FormatInt(int64(r), 10), nil
+				}
+				return string(r), nil
+			},
+			Decode: func(text string) (T, error) {
+				r, size := utf8.DecodeRuneInString(text)
+				if size == 0 || size != len(text) || (r == utf8.RuneError && size == 1) {
+					n, err := strconv.ParseInt(text, 10, 32)
+					if err != nil || utf8.ValidRune(rune(n)) {
+						var zero T
+						return zero, fmt.Errorf("expected a single character or an invalid rune in decimal, got %q", text)
+					}
+					r = rune(n)
+				}
+				decoded := value()
+				decoded.SetInt(int64(r))
+				return decoded.Interface().(T), nil
+			},
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int64:
+		return Codec[T]{
+			Encode: func(v T) (string, error) { return strconv.FormatInt(reflect.ValueOf(v).Int(), 10), nil },
+			Decode: func(text string) (T, error) {
+				n, err := strconv.ParseInt(text, 10, typ.Bits())
+				if err != nil {
+					var zero T
+					return zero, err
+				}
+				decoded := value()
+				decoded.SetInt(n)
+				return decoded.Interface().(T), nil
+			},
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Codec[T]{
+			Encode: func(v T) (string, error) { return strconv.FormatUint(reflect.ValueOf(v).Uint(), 10), nil },
+			Decode: func(text string) (T, error) {
+				n, err := strconv.ParseUint(text, 10, typ.Bits())
+				if err != nil {
+					var zero T
+					return zero, err
+				}
+				decoded := value()
+				decoded.SetUint(n)
+				return decoded.Interface().(T), nil
+			},
+		}
+	default:
+		unsupported := fmt.Errorf("no default codec for type %s; configure one explicitly", typ)
+		return Codec[T]{
+			Encode: func(T) (string, error) { return "", unsupported },
+			Decode: func(string) (T, error) {
+				var zero T
+				return zero, unsupported
+			},
+		}
+	}
+}
+
+// AutomatonDefinition is the format-independent serialized form of a
+// FiniteAutomaton, with states and symbols encoded as strings.
+type AutomatonDefinition struct {
+	Version         int                    `json:"version"`
+	States          []string               `json:"states"`
+	Alphabet        []string               `json:"alphabet"`
+	InitialState    string                 `json:"initialState"`
+	AcceptingStates []string               `json:"acceptingStates"`
+	Transitions     []TransitionDefinition `json:"transitions"`
+}
+
+// TransitionDefinition is a single serialized transition.
+type TransitionDefinition struct {
+	From   string `json:"from"`
+	Symbol string `json:"symbol"`
+	To     string `json:"to"`
+}
+
+// definitionCodecs holds the codecs and validator shared by the serializers.
+type definitionCodecs[Q State, S Symbol] struct {
+	states    Codec[Q]
+	symbols   Codec[S]
+	validator *InputValidator[Q, S]
+}
+
+func newDefinitionCodecs[Q State, S Symbol](states Codec[Q], symbols Codec[S]) definitionCodecs[Q, S] {
+	return definitionCodecs[Q, S]{
+		states:    states,
+		symbols:   symbols,
+		validator: NewInputValidator[Q, S](DefaultValidatorConfig()),
+	}
+}
+
+// encode builds the definition of an automaton. States, symbols and
+// transitions are listed in sorted order so equal automata serialize to
+// identical bytes.
+func (c definitionCodecs[Q, S]) encode(automaton Automaton[Q, S]) (*AutomatonDefinition, error) {
+	fa, err := finiteAutomatonOf(automaton)
+	if err != nil {
+		return nil, err
+	}
+
+	fa.mutex.RLock()
+	defer fa.mutex.RUnlock()
+
+	states := sortValues(fa.getStatesList())
+	symbols := sortValues(fa.getAlphabetList())
+	stateNames, err := encodeAll(c.states, states, "state")
+	if err != nil {
+		return nil, err
+	}
+	symbolNames, err := encodeAll(c.symbols, symbols, "symbol")
+	if err != nil {
+		return nil, err
+	}
+	nameOf := make(map[Q]string, len(states))
+	for i, state := range states {
+		nameOf[state] = stateNames[i]
+	}
+
+	definition := &AutomatonDefinition{
+		Version:         DefinitionFormatVersion,
+		States:          stateNames,
+		Alphabet:        symbolNames,
+		AcceptingStates: make([]string, 0, len(fa.acceptingStates)),
+		Transitions:     make([]TransitionDefinition, 0),
+	}
+	if definition.InitialState, err = c.states.Encode(fa.initialState); err != nil {
+		return nil, NewErrorWithCause(ErrorTypeInvalidConfiguration, "cannot encode initial state", err)
+	}
+	for _, state := range states {
+		if fa.acceptingStates[state] {
+			definition.AcceptingStates = append(definition.AcceptingStates, nameOf[state])
+		}
+	}
+	for _, state := range sortValues(mapKeys(fa.transitions)) {
+		for i, symbol := range symbols {
+			target, exists := fa.transitions[state][symbol]
+			if !exists {
+				continue
+			}
+			from, err := c.encodeState(nameOf, state)
+			if err != nil {
+				return nil, err
+			}
+			to, err := c.encodeState(nameOf, target)
+			if err != nil {
+				return nil, err
+			}
+			definition.Transitions = append(definition.Transitions, TransitionDefinition{
+				From: from, Symbol: symbolNames[i], To: to,
+			})
+		}
+	}
+	return definition, nil
+}
+
+// encodeState encodes a state, including ones referenced only by transitions.
+func (c definitionCodecs[Q, S]) encodeState(names map[Q]string, state Q) (string, error) {
+	if name, exists := names[state]; exists {
+		return name, nil
+	}
+	name, err := c.states.Encode(state)
+	if err != nil {
+		return "", NewErrorWithCause(ErrorTypeInvalidConfiguration, fmt.Sprintf("cannot encode state %v", state), err)
+	}
+	names[state] = name
+	return name, nil
+}
+
+func encodeAll[T comparable](codec Codec[T], values []T, kind string) ([]string, error) {
+	names := make([]string, len(values))
+	seen := make(map[string]T, len(values))
+	for i, value := range values {
+		name, err := codec.Encode(value)
+		if err != nil {
+			return nil, NewErrorWithCause(ErrorTypeInvalidConfiguration, fmt.Sprintf("cannot encode %s %v", kind, value), err)
+		}
+		if other, taken := seen[name]; taken {
+			return nil, NewInvalidConfigurationError("codec",
+				fmt.Sprintf("%ss %v and %v both encode to %q", kind, other, value, name))
+		}
+		seen[name] = value
+		names[i] = name
+	}
+	return names, nil
+}
+
+// decode builds an automaton from a definition and validates it. Every
+// failure is reported as a validation error; the "field" context entry names
+// the offending part of the definition.
+func (c definitionCodecs[Q, S]) decode(definition *AutomatonDefinition) (*FiniteAutomaton[Q, S], error) {
+	if definition.Version != DefinitionFormatVersion {
+		return nil, NewErrorWithContext(ErrorTypeValidation,
+			fmt.Sprintf("unsupported definition version %d (expected %d)", definition.Version, DefinitionFormatVersion),
+			map[string]interface{}{"field": "version"})
+	}
+
+	decodeState := func(field, text string) (Q, error) {
+		state, err := c.states.Decode(text)
+		if err != nil {
+			return state, definitionError(field, fmt.Sprintf("invalid state %q", text), err)
+		}
+		return state, nil
+	}
+
+	initial, err := decodeState("initialState", definition.InitialState)
+	if err != nil {
+		return nil, err
+	}
+	fa := New[Q, S](initial)
+
+	for i, text := range definition.States {
+		state, err := decodeState(fmt.Sprintf("states[%d]", i), text)
+		if err != nil {
+			return nil, err
+		}
+		fa.AddState(state)
+	}
+	for i, text := range definition.Alphabet {
+		symbol, err := c.symbols.Decode(text)
+		if err != nil {
+			return nil, definitionError(fmt.Sprintf("alphabet[%d]", i), fmt.Sprintf("invalid symbol %q", text), err)
+		}
+		fa.AddSymbol(symbol)
+	}
+	for i, text := range definition.AcceptingStates {
+		state, err := decodeState(fmt.Sprintf("acceptingStates[%d]", i), text)
+		if err != nil {
+			return nil, err
+		}
+		fa.AddAcceptingState(state)
+	}
+	for i, transition := range definition.Transitions {
+		field := fmt.Sprintf("transitions[%d]", i)
+		from, err := decodeState(field+".from", transition.From)
+		if err != nil {
+			return nil, err
+		}
+		symbol, err := c.symbols.Decode(transition.Symbol)
+		if err != nil {
+			return nil, definitionError(field+".symbol", fmt.Sprintf("invalid symbol %q", transition.Symbol), err)
+		}
+		to, err := decodeState(field+".to", transition.To)
+		if err != nil {
+			return nil, err
+		}
+		if existing, exists := fa.transitions[from][symbol]; exists && existing != to {
+			return nil, definitionError(field, fmt.Sprintf(
+				"conflicting transitions from %q on %q", transition.From, transition.Symbol), nil)
+		}
+		fa.AddTransition(from, symbol, to)
+	}
+
+	if err := c.validator.Validate(fa); err != nil {
+		return nil, NewErrorWithCause(ErrorTypeValidation, "invalid automaton definition", err)
+	}
+	return fa, nil
+}
+
+func definitionError(field, message string, cause error) *AutomatonError {
+	err := NewErrorWithContext(ErrorTypeValidation, message, map[string]interface{}{"field": field})
+	if cause != nil {
+		err.WithCause(cause)
+	}
+	return err
+}
+
+// JSONSerializer implements the Serializer interface using JSON.
+//
+// Output is deterministic: states, alphabet and accepting states are sorted and
+// transitions are ordered by source state and symbol. Deserialized automata
+// are checked by an InputValidator, and any problem with the input is
+// reported as an AutomatonError of type ErrorTypeValidation.
+type JSONSerializer[Q State, S Symbol] struct {
+	definitionCodecs[Q, S]
+	indent string
+}
+
+// NewJSONSerializer creates a JSON serializer using DefaultCodec for states and
+// symbols and the default validator configuration.
+func NewJSONSerializer[Q State, S Symbol]() *JSONSerializer[Q, S] {
+	return NewJSONSerializerWithCodecs(DefaultCodec[Q](), DefaultCodec[S]())
+}
+
+// NewJSONSerializerWithCodecs creates a JSON serializer with custom state and symbol codecs.
+func NewJSONSerializerWithCodecs[Q State, S Symbol](states Codec[Q], symbols Codec[S]) *JSONSerializer[Q, S] {
+	return &JSONSerializer[Q, S]{
+		definitionCodecs: newDefinitionCodecs(states, symbols),
+		indent:           "  ",
+	}
+}
+
+// WithValidator replaces the validator run on deserialized automata.
+func (s *JSONSerializer[Q, S]) WithValidator(validator *InputValidator[Q, S]) *JSONSerializer[Q, S] {
+	s.validator = validator
+	return s
+}
+
+// WithIndent sets the indentation of the output; an empty string produces compact JSON.
+func (s *JSONSerializer[Q, S]) WithIndent(indent string) *JSONSerializer[Q, S] {
+	s.indent = indent
+	return s
+}
+
+// Serialize encodes the automaton as JSON (implements Serializer interface).
+func (s *JSONSerializer[Q, S]) Serialize(automaton Automaton[Q, S]) ([]byte, error) {
+	definition, err := s.encode(automaton)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", s.indent)
+	if err := encoder.Encode(definition); err != nil {
+		return nil, NewErrorWithCause(ErrorTypeInternal, "cannot encode definition", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// Deserialize decodes a JSON definition (implements Serializer interface).
+func (s *JSONSerializer[Q, S]) Deserialize(data []byte) (Automaton[Q, S], error) {
+	return s.DeserializeAutomaton(data)
+}
+
+// DeserializeAutomaton decodes a JSON definition into a FiniteAutomaton.
+func (s *JSONSerializer[Q, S]) DeserializeAutomaton(data []byte) (*FiniteAutomaton[Q, S], error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var definition AutomatonDefinition
+	if err := decoder.Decode(&definition); err != nil {
+		automatonErr := NewErrorWithCause(ErrorTypeValidation, "malformed JSON definition", err)
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			automatonErr.WithContext("offset", syntaxErr.Offset)
+		case errors.As(err, &typeErr):
+			automatonErr.WithContext("offset", typeErr.Offset).WithContext("field", typeErr.Field)
+		}
+		return nil, automatonErr
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, NewErrorWithContext(ErrorTypeValidation, "unexpected data after JSON definition",
+			map[string]interface{}{"offset": decoder.InputOffset()})
+	}
+
+	return s.decode(&definition)
+}
+
+// sourcePosition is a 1-based line and column in a serialized document.
+type sourcePosition struct {
+	line, column int
+}
+
+// sourcePositions records where the parts of a definition were written in a
+// document, so that decoding and validation errors can point at them.
+type sourcePositions struct {
+	// fields maps AutomatonDefinition field paths (as used in error contexts) to positions
+	fields map[string]sourcePosition
+	// states and symbols map encoded names to where they are declared
+	states  map[string]sourcePosition
+	symbols map[string]sourcePosition
+}
+
+func newSourcePositions() *sourcePositions {
+	return &sourcePositions{
+		fields:  make(map[string]sourcePosition),
+		states:  make(map[string]sourcePosition),
+		symbols: make(map[string]sourcePosition),
+	}
+}
+
+// declareState records the first place a state name appears.
+func (p *sourcePositions) declareState(name string, position sourcePosition) {
+	if _, exists := p.states[name]; !exists {
+		p.states[name] = position
+	}
+}
+
+// declareSymbol records the first place a symbol appears.
+func (p *sourcePositions) declareSymbol(name string, position sourcePosition) {
+	if _, exists := p.symbols[name]; !exists {
+		p.symbols[name] = position
+	}
+}
+
+// locate adds the "line" and "column" of the first part of the document named
+// by the error's context (a definition field, a state or a symbol).
+func (c definitionCodecs[Q, S]) locate(positions *sourcePositions, err error) error {
+	var outer *AutomatonError
+	if !errors.As(err, &outer) {
+		return err
+	}
+	for _, candidate := range flattenErrors(err) {
+		var automatonErr *AutomatonError
+		if !errors.As(candidate, &automatonErr) {
+			continue
+		}
+		if position, found := c.positionOf(positions, automatonErr.Context); found {
+			outer.WithContext("line", position.line).WithContext("column", position.column)
+			break
+		}
+	}
+	return err
+}
+
+func (c definitionCodecs[Q, S]) positionOf(
+	positions *sourcePositions,
+	context map[string]interface{},
+) (sourcePosition, bool) {
+	if field, ok := context["field"].(string); ok {
+		if position, exists := positions.fields[field]; exists {
+			return position, true
+		}
+	}
+	if state, ok := context["state"].(Q); ok {
+		if name, err := c.states.Encode(state); err == nil {
+			if position, exists := positions.states[name]; exists {
+				return position, true
+			}
+		}
+	}
+	if symbol, ok := context["symbol"].(S); ok {
+		if name, err := c.symbols.Encode(symbol); err == nil {
+			if position, exists := positions.symbols[name]; exists {
+				return position, true
+			}
+		}
+	}
+	return sourcePosition{}, false
+}
+
+// flattenErrors lists an error, the errors it collects and their causes, outermost first.
+func flattenErrors(err error) []error {
+	var result []error
+	for err != nil {
+		if collector, ok := err.(*ErrorCollector); ok {
+			for _, collected := range collector.Errors() {
+				result = append(result, flattenErrors(collected)...)
+			}
+			return result
+		}
+		result = append(result, err)
+		err = errors.Unwrap(err)
+	}
+	return result
+}
@@ -0,0 +1,204 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestJSONSerializer_RoundTrip tests that a serialized automaton deserializes to an equivalent one
+func TestJSONSerializer_RoundTrip(t *testing.T) {
+	original := newParityAutomaton()
+	serializer := NewJSONSerializer[string, rune]()
+
+	data, err := serializer.Serialize(original)
+	if err != nil {
+		t.Fatalf("Serialize returned error: %v", err)
+	}
+	restored, err := serializer.DeserializeAutomaton(data)
+	if err != nil {
+		t.Fatalf("DeserializeAutomaton returned error: %v", err)
+	}
+
+	if restored.GetInitialState() != original.GetInitialState() {
+		t.Errorf("Expected initial state %q, got %q", original.GetInitialState(), restored.GetInitialState())
+	}
+	if equivalent, counterexample, err := Equivalent[string, rune](original, restored); err != nil || !equivalent {
+		t.Errorf("Round trip changed the language (counterexample %q, err %v)", string(counterexample), err)
+	}
+
+	again, err := serializer.Serialize(restored)
+	if err != nil {
+		t.Fatalf("Serialize returned error: %v", err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("Serialization is not stable:\n%s\n%s", data, again)
+	}
+}
+
+// TestJSONSerializer_InvalidRunes tests that int32 values that are not valid runes round-trip in decimal
+func TestJSONSerializer_InvalidRunes(t *testing.T) {
+	fa := New[int32, int32](-7).
+		AddStates(-7, 0xD800, 0x110000, '1').
+		AddSymbols(-5, '5').
+		AddAcceptingState('1').
+		AddTransition(-7, -5, 0xD800).
+		AddTransition(0xD800, '5', 0x110000).
+		AddTransition(0x110000, -5, '1')
+
+	serializer := NewJSONSerializer[int32, int32]()
+	data, err := serializer.Serialize(fa)
+	if err != nil {
+		t.Fatalf("Serialize returned error: %v", err)
+	}
+	if !strings.Contains(string(data), `"initialState": "-7"`) || !strings.Contains(string(data), `"55296"`) {
+		t.Errorf("Expected invalid runes in decimal:\n%s", data)
+	}
+	restored, err := serializer.DeserializeAutomaton(data)
+	if err != nil {
+		t.Fatalf("DeserializeAutomaton returned error: %v", err)
+	}
+	if accepted, err := restored.ProcessInput([]int32{-5, '5', -5}); err != nil || !accepted {
+		t.Errorf("Expected restored automaton to accept [-5 '5' -5], got %v (%v)", accepted, err)
+	}
+
+	codec := DefaultCodec[int32]()
+	for _, text := range []string{"65", "", "x1", "99999999999"} {
+		if _, err := codec.Decode(text); err == nil {
+			t.Errorf("Decode(%q) should fail", text)
+		}
+	}
+}
+
+// TestJSONSerializer_SortedOutput tests the exact output format
+func TestJSONSerializer_SortedOutput(t *testing.T) {
+	fa := New[int, rune](2).
+		AddStates(10, 2, 1).
+		AddSymbols('b', 'a').
+		AddAcceptingState(10).
+		AddTransition(10, 'a', 1).
+		AddTransition(2, 'b', 10).
+		AddTransition(2, 'a', 1)
+
+	data, err := NewJSONSerializer[int, rune]().WithIndent("").Serialize(fa)
+	if err != nil {
+		t.Fatalf("Serialize returned error: %v", err)
+	}
+
+	want := `{"version":1,"states":["1","2","10"],"alphabet":["a","b"],"initialState":"2",` +
+		`"acceptingStates":["10"],"transitions":[{"from":"2","symbol":"a","to":"1"},` +
+		`{"from":"2","symbol":"b","to":"10"},{"from":"10","symbol":"a","to":"1"}]}` + "\n"
+	if string(data) != want {
+		t.Errorf("Serialize() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+type point struct{ X, Y int }
+
+// TestJSONSerializer_CustomCodec tests pluggable codecs for non-string types
+func TestJSONSerializer_CustomCodec(t *testing.T) {
+	pointCodec := Codec[point]{
+		Encode: func(p point) (string, error) { return fmt.Sprintf("%d,%d", p.X, p.Y), nil },
+		Decode: func(text string) (point, error) {
+			var p point
+			_, err := fmt.Sscanf(text, "%d,%d", &p.X, &p.Y)
+			return p, err
+		},
+	}
+
+	fa := New[point, string](point{0, 0}).
+		AddStates(point{0, 0}, point{0, 1}).
+		AddSymbols("up").
+		AddAcceptingState(point{0, 1}).
+		AddTransition(point{0, 0}, "up", point{0, 1})
+
+	if _, err := NewJSONSerializer[point, string]().Serialize(fa); err == nil {
+		t.Error("Expected error serializing a struct state without a codec")
+	}
+
+	serializer := NewJSONSerializerWithCodecs(pointCodec, DefaultCodec[string]())
+	data, err := serializer.Serialize(fa)
+	if err != nil {
+		t.Fatalf("Serialize returned error: %v", err)
+	}
+	if !strings.Contains(string(data), `"initialState": "0,0"`) {
+		t.Errorf("Expected encoded initial state in output:\n%s", data)
+	}
+
+	restored, err := serializer.DeserializeAutomaton(data)
+	if err != nil {
+		t.Fatalf("DeserializeAutomaton returned error: %v", err)
+	}
+	if accepted, err := restored.ProcessInput([]string{"up"}); err != nil || !accepted {
+		t.Errorf("Expected restored automaton to accept [up], got %v (%v)", accepted, err)
+	}
+}
+
+// TestJSONSerializer_InvalidInput tests that bad definitions fail with validation errors
+func TestJSONSerializer_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		field string
+	}{
+		{"malformed", `{"version": 1,`, ""},
+		{"unknown field", `{"version": 1, "colour": "red"}`, ""},
+		{"wrong type", `{"version": 1, "states": "q0"}`, "states"},
+		{"trailing data", `{"version": 1} {}`, ""},
+		{"missing version", `{"states": ["q0"], "alphabet": ["a"], "initialState": "q0"}`, "version"},
+		{"bad symbol", `{"version": 1, "states": ["q0"], "alphabet": ["ab"], "initialState": "q0"}`, "alphabet[0]"},
+		{"conflict", `{"version": 1, "states": ["q0"], "alphabet": ["a"], "initialState": "q0",
+			"transitions": [{"from": "q0", "symbol": "a", "to": "q0"}, {"from": "q0", "symbol": "a", "to": "q1"}]}`,
+			"transitions[1]"},
+		{"undeclared state", `{"version": 1, "states": ["q0"], "alphabet": ["a"], "initialState": "q0",
+			"transitions": [{"from": "q0", "symbol": "a", "to": "q1"}]}`, ""},
+		{"empty alphabet", `{"version": 1, "states": ["q0"], "alphabet": [], "initialState": "q0"}`, ""},
+	}
+
+	serializer := NewJSONSerializer[string, rune]()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := serializer.Deserialize([]byte(tt.data))
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !IsValidationError(err) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+			if tt.field != "" {
+				var automatonErr *AutomatonError
+				errors.As(err, &automatonErr)
+				if automatonErr.Context["field"] != tt.field {
+					t.Errorf("Expected field %q, got %v", tt.field, automatonErr.Context["field"])
+				}
+			}
+		})
+	}
+}
+
+// TestJSONSerializer_StrictValidator tests a custom validator configuration
+func TestJSONSerializer_StrictValidator(t *testing.T) {
+	data, err := NewJSONSerializer[string, rune]().Serialize(newPartialABAutomaton())
+	if err != nil {
+		t.Fatalf("Serialize returned error: %v", err)
+	}
+
+	if _, err := NewJSONSerializer[string, rune]().Deserialize(data); err != nil {
+		t.Errorf("Expected default validation to pass, got %v", err)
+	}
+	strict := NewJSONSerializer[string, rune]().WithValidator(NewInputValidator[string, rune](StrictValidatorConfig()))
+	if _, err := strict.Deserialize(data); !IsValidationError(err) {
+		t.Errorf("Expected strict validation error for incomplete transitions, got %v", err)
+	}
+}
+
+// newPartialABAutomaton returns a partial automaton accepting exactly "ab"
+func newPartialABAutomaton() *FiniteAutomaton[string, rune] {
+	return New[string, rune]("q0").
+		AddStates("q0", "q1", "q2").
+		AddSymbols('a', 'b').
+		AddAcceptingState("q2").
+		AddTransition("q0", 'a', "q1").
+		AddTransition("q1", 'b', "q2")
+}
@@ -0,0 +1,186 @@
+package fsm
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"unicode/utf8"
+)
+
+// StreamResult is the outcome of processing a stream. Unlike ProcessResult it
+// holds no trace, so streams of any length run in constant memory.
+type StreamResult[Q State] struct {
+	Accepted   bool
+	FinalState Q
+	// Position is the number of symbols consumed; on error it is also the
+	// position of the symbol that failed
+	Position int
+}
+
+// Decoder reads the next symbol from a buffered reader, returning io.EOF at
+// the end of the stream. DecodeRunes, DecodeBytes and DecodeLines are the
+// built-in decoders.
+type Decoder[S Symbol] func(r *bufio.Reader) (S, error)
+
+// DecodeRunes decodes UTF-8 encoded runes. Invalid encodings are reported as
+// errors rather than replaced with utf8.RuneError.
+func DecodeRunes(r *bufio.Reader) (rune, error) {
+	symbol, size, err := r.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if symbol == utf8.RuneError && size == 1 {
+		return 0, errors.New("invalid UTF-8 encoding")
+	}
+	return symbol, nil
+}
+
+// DecodeBytes decodes every byte as a symbol.
+func DecodeBytes(r *bufio.Reader) (byte, error) {
+	return r.ReadByte()
+}
+
+// DecodeLines decodes newline-delimited tokens, such as the events of a log
+// with one event per line. Line endings, including "\r\n", are removed and
+// empty lines are skipped.
+func DecodeLines(r *bufio.Reader) (string, error) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line != "" {
+			return line, nil
+		}
+		if err == io.EOF {
+			return "", io.EOF
+		}
+	}
+}
+
+// ProcessStream resets the automaton and steps it through the symbols of the
+// sequence without collecting them, reporting whether the stream is accepted.
+// Errors of the automaton are wrapped in an AutomatonError of the same type
+// with a "position" context entry holding the position of the symbol that
+// failed.
+//
+// Example usage:
+//
+//	result, err := ProcessStream[string, rune](automaton, slices.Values(events))
+//	if err != nil {
+//		log.Printf("rejected at symbol %d: %v", result.Position, err)
+//	}
+func ProcessStream[Q State, S Symbol](automaton Automaton[Q, S], symbols iter.Seq[S]) (StreamResult[Q], error) {
+	return ProcessStreamContext(context.Background(), automaton, symbols)
+}
+
+// ProcessStreamContext is ProcessStream stopping with an InterruptedError as
+// soon as ctx is done. The context is checked before every symbol; the
+// InterruptedError has no trace.
+func ProcessStreamContext[Q State, S Symbol](
+	ctx context.Context,
+	automaton Automaton[Q, S],
+	symbols iter.Seq[S],
+) (StreamResult[Q], error) {
+	return processStream(ctx, automaton, func(yield func(S, error) bool) {
+		for symbol := range symbols {
+			if !yield(symbol, nil) {
+				return
+			}
+		}
+	})
+}
+
+// ProcessReader resets the automaton and steps it through the symbols decoded
+// from the reader, which is read through a buffer. Decoding errors other than
+// io.EOF are returned as invalid input errors caused by the decoder's error.
+//
+// Example usage:
+//
+//	file, _ := os.Open("events.log")
+//	result, err := ProcessReader[string, string](automaton, file, DecodeLines)
+func ProcessReader[Q State, S Symbol](automaton Automaton[Q, S], r io.Reader, decode Decoder[S]) (StreamResult[Q], error) {
+	return ProcessReaderContext(context.Background(), automaton, r, decode)
+}
+
+// ProcessReaderContext is ProcessReader stopping with an InterruptedError as
+// soon as ctx is done. The context is checked before every symbol.
+func ProcessReaderContext[Q State, S Symbol](
+	ctx context.Context,
+	automaton Automaton[Q, S],
+	r io.Reader,
+	decode Decoder[S],
+) (StreamResult[Q], error) {
+	buffered, ok := r.(*bufio.Reader)
+	if !ok {
+		buffered = bufio.NewReader(r)
+	}
+	return processStream(ctx, automaton, func(yield func(S, error) bool) {
+		for {
+			symbol, err := decode(buffered)
+			if err == io.EOF {
+				return
+			}
+			if !yield(symbol, err) {
+				return
+			}
+		}
+	})
+}
+
+// processStream steps the automaton through a sequence of symbols or errors,
+// stopping at the first error.
+func processStream[Q State, S Symbol](
+	ctx context.Context,
+	automaton Automaton[Q, S],
+	symbols iter.Seq2[S, error],
+) (StreamResult[Q], error) {
+	done := ctx.Done()
+	automaton.Reset()
+
+	position := 0
+	var err error
+	for symbol, decodeErr := range symbols {
+		select {
+		case <-done:
+			err = &InterruptedError[Q]{Position: position, Cause: ctx.Err()}
+		default:
+		}
+		if err != nil {
+			break
+		}
+		if decodeErr != nil {
+			err = NewErrorWithCause(ErrorTypeInvalidInput,
+				fmt.Sprintf("cannot decode symbol at position %d", position), decodeErr).
+				WithContext("position", position)
+			break
+		}
+		if _, stepErr := automaton.Step(symbol); stepErr != nil {
+			err = stepErr
+			// The step error may be shared, so it is wrapped rather than changed.
+			var automatonErr *AutomatonError
+			if errors.As(stepErr, &automatonErr) {
+				err = NewErrorWithCause(automatonErr.Type,
+					fmt.Sprintf("cannot process symbol at position %d", position), stepErr).
+					WithContext("position", position)
+			}
+			break
+		}
+		position++
+	}
+
+	result := StreamResult[Q]{
+		FinalState: automaton.GetCurrentState(),
+		Position:   position,
+	}
+	if err != nil {
+		return result, err
+	}
+	result.Accepted = automaton.IsCurrentStateAccepting()
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package fsm
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// TestProcessStream tests processing sequences without collecting them
+func TestProcessStream(t *testing.T) {
+	fa := newParityAutomaton()
+	for _, input := range randomInputs([]rune("01"), 50, 20) {
+		want, _ := fa.ProcessInput(input)
+		result, err := ProcessStream[string, rune](fa, slices.Values(input))
+		if err != nil || result.Accepted != want || result.Position != len(input) || result.FinalState != fa.GetCurrentState() {
+			t.Fatalf("ProcessStream(%q) = %+v, %v; want accepted %v", string(input), result, err, want)
+		}
+	}
+
+	result, err := ProcessStream[string, rune](fa, slices.Values([]rune("112")))
+	var automatonErr *AutomatonError
+	if !errors.As(err, &automatonErr) || automatonErr.Type != ErrorTypeInvalidInput || automatonErr.Context["position"] != 2 {
+		t.Errorf("Expected invalid input error at position 2, got %v", err)
+	}
+	if cause, ok := errors.Unwrap(err).(*AutomatonError); !ok || cause.Context["position"] != nil {
+		t.Errorf("Expected the step error to be wrapped unchanged, got %v", errors.Unwrap(err))
+	}
+	if result.Accepted || result.Position != 2 || result.FinalState != "even" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	// An endless stream stops at the first undefined transition.
+	endless := func(yield func(string) bool) {
+		for yield("coin") && yield("inspect") {
+		}
+	}
+	result, err = ProcessStream[string, string](newTurnstileAutomaton(), endless)
+	if !IsTransitionError(err) || result.Position != 1 || result.FinalState != "unlocked" {
+		t.Errorf("Got %+v, %v; want a transition error at position 1", result, err)
+	}
+}
+
+// TestProcessReader tests the built-in decoders
+func TestProcessReader(t *testing.T) {
+	events := "push\r\ncoin\n\ncoin"
+	result, err := ProcessReader[string, string](newTurnstileAutomaton(), strings.NewReader(events), DecodeLines)
+	if err != nil || !result.Accepted || result.Position != 3 || result.FinalState != "unlocked" {
+		t.Errorf("ProcessReader(lines) = %+v, %v; want accepted after 3 symbols", result, err)
+	}
+
+	// Reading a byte at a time exercises symbols split across reads.
+	reader := iotest.OneByteReader(strings.NewReader("0110é"))
+	result, err = ProcessReader[string, rune](newParityAutomaton(), reader, DecodeRunes)
+	if !IsInvalidInputError(err) || result.Position != 4 || result.FinalState != "even" {
+		t.Errorf("ProcessReader(runes) = %+v, %v; want invalid input at position 4", result, err)
+	}
+
+	bytes := New[int, byte](0).
+		AddStates(0, 1).
+		AddSymbols('a', 'b').
+		AddAcceptingState(1).
+		AddTransition(0, 'a', 1).
+		AddTransition(1, 'b', 0)
+	if result, err := ProcessReader[int, byte](bytes, strings.NewReader("aba"), DecodeBytes); err != nil || !result.Accepted {
+		t.Errorf("ProcessReader(bytes) = %+v, %v; want accepted", result, err)
+	}
+}
+
+// TestProcessReader_DecodeErrors tests decoding and read errors
+func TestProcessReader_DecodeErrors(t *testing.T) {
+	result, err := ProcessReader[string, rune](newParityAutomaton(), strings.NewReader("1\xff"), DecodeRunes)
+	var automatonErr *AutomatonError
+	if !errors.As(err, &automatonErr) || automatonErr.Type != ErrorTypeInvalidInput || result.Position != 1 {
+		t.Errorf("Expected a decoding error at position 1, got %+v, %v", result, err)
+	}
+
+	failing := io.MultiReader(strings.NewReader("11"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	result, err = ProcessReader[string, rune](newParityAutomaton(), failing, DecodeRunes)
+	if !errors.Is(err, io.ErrUnexpectedEOF) || result.Position != 2 || result.Accepted {
+		t.Errorf("Expected the read error at position 2, got %+v, %v", result, err)
+	}
+
+	// Readers that are already buffered are used as they are.
+	buffered := bufio.NewReader(strings.NewReader("coin"))
+	if result, err := ProcessReader[string, string](newTurnstileAutomaton(), buffered, DecodeLines); err != nil || !result.Accepted {
+		t.Errorf("ProcessReader(buffered) = %+v, %v; want accepted", result, err)
+	}
+}
+
+// TestProcessStreamContext tests interruption of a stream
+func TestProcessStreamContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	symbols := func(yield func(rune) bool) {
+		for i := 0; ; i++ {
+			if i == 3 {
+				cancel()
+			}
+			if !yield('1') {
+				return
+			}
+		}
+	}
+
+	result, err := ProcessStreamContext[string, rune](ctx, newParityAutomaton(), symbols)
+	var interrupted *InterruptedError[string]
+	if !errors.As(err, &interrupted) || interrupted.Position != 3 || result.Position != 3 || result.FinalState != "odd" {
+		t.Errorf("Expected interruption at position 3, got %+v, %v", result, err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	_, err = ProcessReaderContext[string, rune](ctx, newParityAutomaton(), strings.NewReader("1"), DecodeRunes)
+	if !IsInterruptedError(err) {
+		t.Errorf("Expected InterruptedError, got %v", err)
+	}
+}
@@ -124,21 +124,22 @@ func (v *InputValidator[Q, S]) Validate(automaton *FiniteAutomaton[Q, S]) error
 
 func validateNonEmptyStates[Q State, S Symbol](automaton *FiniteAutomaton[Q, S]) error {
 	if len(automaton.states) == 0 {
-		return NewValidationError("automaton must have at least one state")
+		return NewValidationError("automaton must have at least one state").WithContext("field", "states")
 	}
 	return nil
 }
 
 func validateNonEmptyAlphabet[Q State, S Symbol](automaton *FiniteAutomaton[Q, S]) error {
 	if len(automaton.alphabet) == 0 {
-		return NewValidationError("automaton must have at least one symbol in alphabet")
+		return NewValidationError("automaton must have at least one symbol in alphabet").WithContext("field", "alphabet")
 	}
 	return nil
 }
 
 func validateInitialStateInStates[Q State, S Symbol](automaton *FiniteAutomaton[Q, S]) error {
 	if !automaton.states[automaton.initialState] {
-		return NewValidationError(fmt.Sprintf("initial state %v is not in the set of states", automaton.initialState))
+		return NewValidationError(fmt.Sprintf("initial state %v is not in the set of states", automaton.initialState)).
+			WithContext("field", "initialState")
 	}
 	return nil
 }
@@ -146,7 +147,8 @@ func validateInitialStateInStates[Q State, S Symbol](automaton *FiniteAutomaton[
 func validateAcceptingStatesInStates[Q State, S Symbol](automaton *FiniteAutomaton[Q, S]) error {
 	for state := range automaton.acceptingStates {
 		if !automaton.states[state] {
-			return NewValidationError(fmt.Sprintf("accepting state %v is not in the set of states", state))
+			return NewValidationError(fmt.Sprintf("accepting state %v is not in the set of states", state)).
+				WithContext("state", state)
 		}
 	}
 	return nil
@@ -155,11 +157,13 @@ func validateAcceptingStatesInStates[Q State, S Symbol](automaton *FiniteAutomat
 func validateTransitionStatesInStates[Q State, S Symbol](automaton *FiniteAutomaton[Q, S]) error {
 	for fromState, transitions := range automaton.transitions {
 		if !automaton.states[fromState] {
-			return NewValidationError(fmt.Sprintf("transition from state %v is not in the set of states", fromState))
+			return NewValidationError(fmt.Sprintf("transition from state %v is not in the set of states", fromState)).
+				WithContext("state", fromState)
 		}
 		for _, toState := range transitions {
 			if !automaton.states[toState] {
-				return NewValidationError(fmt.Sprintf("transition to state %v is not in the set of states", toState))
+				return NewValidationError(fmt.Sprintf("transition to state %v is not in the set of states", toState)).
+					WithContext("state", toState)
 			}
 		}
 	}
@@ -170,7 +174,8 @@ func validateTransitionSymbolsInAlphabet[Q State, S Symbol](automaton *FiniteAut
 	for _, transitions := range automaton.transitions {
 		for symbol := range transitions {
 			if !automaton.alphabet[symbol] {
-				return NewValidationError(fmt.Sprintf("transition symbol %v is not in the alphabet", symbol))
+				return NewValidationError(fmt.Sprintf("transition symbol %v is not in the alphabet", symbol)).
+					WithContext("symbol", symbol)
 			}
 		}
 	}
@@ -182,7 +187,7 @@ func validateMaxStates[Q State, S Symbol](maxStates int) ValidationRule[Q, S] {
 		if len(automaton.states) > maxStates {
 			return NewValidationError(fmt.Sprintf(
 				"number of states (%d) exceeds maximum allowed (%d)",
-				len(automaton.states), maxStates))
+				len(automaton.states), maxStates)).WithContext("field", "states")
 		}
 		return nil
 	}
@@ -193,7 +198,7 @@ func validateMaxAlphabetSize[Q State, S Symbol](maxSize int) ValidationRule[Q, S
 		if len(automaton.alphabet) > maxSize {
 			return NewValidationError(fmt.Sprintf(
 				"alphabet size (%d) exceeds maximum allowed (%d)",
-				len(automaton.alphabet), maxSize))
+				len(automaton.alphabet), maxSize)).WithContext("field", "alphabet")
 		}
 		return nil
 	}
@@ -208,7 +213,7 @@ func validateMaxTransitions[Q State, S Symbol](maxTransitions int) ValidationRul
 		if totalTransitions > maxTransitions {
 			return NewValidationError(fmt.Sprintf(
 				"number of transitions (%d) exceeds maximum allowed (%d)",
-				totalTransitions, maxTransitions))
+				totalTransitions, maxTransitions)).WithContext("field", "transitions")
 		}
 		return nil
 	}
@@ -217,8 +222,9 @@ func validateMaxTransitions[Q State, S Symbol](maxTransitions int) ValidationRul
 func validateCompleteTransitions[Q State, S Symbol](automaton *FiniteAutomaton[Q, S]) error {
 	for state := range automaton.states {
 		for symbol := range automaton.alphabet {
-			if transitions, exists := automaton.transitions[state]; !exists || transitions[symbol] == *new(Q) {
-				return NewValidationError(fmt.Sprintf("missing transition from state %v with symbol %v", state, symbol))
+			if _, exists := automaton.transitions[state][symbol]; !exists {
+				return NewValidationError(fmt.Sprintf("missing transition from state %v with symbol %v", state, symbol)).
+					WithContext("state", state).WithContext("symbol", symbol)
 			}
 		}
 	}
@@ -248,7 +254,8 @@ func validateNoUnreachableStates[Q State, S Symbol](automaton *FiniteAutomaton[Q
 	// Check for unreachable states
 	for state := range automaton.states {
 		if !reachable[state] {
-			return NewValidationError(fmt.Sprintf("state %v is unreachable from initial state", state))
+			return NewValidationError(fmt.Sprintf("state %v is unreachable from initial state", state)).
+				WithContext("state", state)
 		}
 	}
 
@@ -261,7 +268,8 @@ func validateNoDuplicateTransitions[Q State, S Symbol](automaton *FiniteAutomato
 		seen := make(map[S]Q)
 		for symbol, toState := range transitions {
 			if existing, exists := seen[symbol]; exists && existing != toState {
-				return NewValidationError(fmt.Sprintf("duplicate transition from state %v with symbol %v", fromState, symbol))
+				return NewValidationError(fmt.Sprintf("duplicate transition from state %v with symbol %v", fromState, symbol)).
+					WithContext("state", fromState).WithContext("symbol", symbol)
 			}
 			seen[symbol] = toState
 		}
@@ -270,30 +278,232 @@ func validateNoDuplicateTransitions[Q State, S Symbol](automaton *FiniteAutomato
 }
 
 func validateStateNaming[Q State, S Symbol](automaton *FiniteAutomaton[Q, S]) error {
+	return validateStateNames(automaton.states)
+}
+
+func validateStateNames[Q State](states map[Q]bool) error {
 	// Check for reasonable state naming conventions (string states only)
-	for state := range automaton.states {
+	for state := range states {
 		stateStr := fmt.Sprintf("%v", state)
 
 		// Check if state is a string type
 		if reflect.TypeOf(state).Kind() == reflect.String {
 			// Validate string state naming
 			if strings.TrimSpace(stateStr) == "" {
-				return NewValidationError("state names cannot be empty or whitespace-only")
+				return NewValidationError("state names cannot be empty or whitespace-only").WithContext("state", state)
 			}
 
 			if strings.Contains(stateStr, " ") && len(strings.Fields(stateStr)) > 1 {
 				return NewValidationError(fmt.Sprintf(
 					"state name '%s' contains multiple words (consider using underscores)",
-					stateStr))
+					stateStr)).WithContext("state", state)
 			}
 
 			if len(stateStr) > MaxStateNameLength {
 				return NewValidationError(fmt.Sprintf(
 					"state name '%s' is too long (max %d characters)",
-					stateStr, MaxStateNameLength))
+					stateStr, MaxStateNameLength)).WithContext("state", state)
+			}
+		}
+	}
+	return nil
+}
+
+// NFAValidationRule represents a validation rule for NFA construction.
+type NFAValidationRule[Q State, S Symbol] func(*NFA[Q, S]) error
+
+// NFAValidator provides input validation for nondeterministic automata.
+// It applies the same limits as InputValidator, counting ε-transitions as
+// transitions; RequireCompleteTransitions has no meaning for an NFA and is ignored.
+type NFAValidator[Q State, S Symbol] struct {
+	config ValidatorConfig
+	rules  []NFAValidationRule[Q, S]
+}
+
+// NewNFAValidator creates a new NFA validator with the given configuration.
+func NewNFAValidator[Q State, S Symbol](config ValidatorConfig) *NFAValidator[Q, S] {
+	validator := &NFAValidator[Q, S]{
+		config: config,
+		rules:  make([]NFAValidationRule[Q, S], 0),
+	}
+
+	validator.AddRule(validateNFANonEmptyStates[Q, S])
+	validator.AddRule(validateNFANonEmptyAlphabet[Q, S])
+	validator.AddRule(validateNFAStateReferences[Q, S])
+	validator.AddRule(validateNFATransitionSymbolsInAlphabet[Q, S])
+
+	if config.MaxStates > 0 {
+		validator.AddRule(func(nfa *NFA[Q, S]) error {
+			if len(nfa.states) > config.MaxStates {
+				return NewValidationError(fmt.Sprintf(
+					"number of states (%d) exceeds maximum allowed (%d)",
+					len(nfa.states), config.MaxStates)).WithContext("field", "states")
+			}
+			return nil
+		})
+	}
+
+	if config.MaxAlphabetSize > 0 {
+		validator.AddRule(func(nfa *NFA[Q, S]) error {
+			if len(nfa.alphabet) > config.MaxAlphabetSize {
+				return NewValidationError(fmt.Sprintf(
+					"alphabet size (%d) exceeds maximum allowed (%d)",
+					len(nfa.alphabet), config.MaxAlphabetSize)).WithContext("field", "alphabet")
+			}
+			return nil
+		})
+	}
+
+	if config.MaxTransitions > 0 {
+		validator.AddRule(validateNFAMaxTransitions[Q, S](config.MaxTransitions))
+	}
+
+	if config.StrictMode {
+		validator.AddRule(validateNFANoUnreachableStates[Q, S])
+		validator.AddRule(func(nfa *NFA[Q, S]) error {
+			return validateStateNames(nfa.states)
+		})
+	}
+
+	return validator
+}
+
+// AddRule adds a custom validation rule.
+func (v *NFAValidator[Q, S]) AddRule(rule NFAValidationRule[Q, S]) {
+	v.rules = append(v.rules, rule)
+}
+
+// Validate runs all validation rules against the NFA.
+func (v *NFAValidator[Q, S]) Validate(automaton *NFA[Q, S]) error {
+	collector := NewErrorCollector()
+
+	for _, rule := range v.rules {
+		if err := rule(automaton); err != nil {
+			collector.Add(err)
+		}
+	}
+
+	return collector.ToError()
+}
+
+func validateNFANonEmptyStates[Q State, S Symbol](nfa *NFA[Q, S]) error {
+	if len(nfa.states) == 0 {
+		return NewValidationError("automaton must have at least one state").WithContext("field", "states")
+	}
+	return nil
+}
+
+func validateNFANonEmptyAlphabet[Q State, S Symbol](nfa *NFA[Q, S]) error {
+	if len(nfa.alphabet) == 0 {
+		return NewValidationError("automaton must have at least one symbol in alphabet").WithContext("field", "alphabet")
+	}
+	return nil
+}
+
+func validateNFAStateReferences[Q State, S Symbol](nfa *NFA[Q, S]) error {
+	if !nfa.states[nfa.initialState] {
+		return NewValidationError(fmt.Sprintf("initial state %v is not in the set of states", nfa.initialState)).
+			WithContext("field", "initialState")
+	}
+	for state := range nfa.acceptingStates {
+		if !nfa.states[state] {
+			return NewValidationError(fmt.Sprintf("accepting state %v is not in the set of states", state)).
+				WithContext("state", state)
+		}
+	}
+	for fromState, transitions := range nfa.transitions {
+		if !nfa.states[fromState] {
+			return NewValidationError(fmt.Sprintf("transition from state %v is not in the set of states", fromState)).
+				WithContext("state", fromState)
+		}
+		for _, targets := range transitions {
+			for toState := range targets {
+				if !nfa.states[toState] {
+					return NewValidationError(fmt.Sprintf("transition to state %v is not in the set of states", toState)).
+						WithContext("state", toState)
+				}
 			}
 		}
 	}
+	for fromState, targets := range nfa.epsilons {
+		if !nfa.states[fromState] {
+			return NewValidationError(fmt.Sprintf("ε-transition from state %v is not in the set of states", fromState)).
+				WithContext("state", fromState)
+		}
+		for toState := range targets {
+			if !nfa.states[toState] {
+				return NewValidationError(fmt.Sprintf("ε-transition to state %v is not in the set of states", toState)).
+					WithContext("state", toState)
+			}
+		}
+	}
+	return nil
+}
+
+func validateNFATransitionSymbolsInAlphabet[Q State, S Symbol](nfa *NFA[Q, S]) error {
+	for _, transitions := range nfa.transitions {
+		for symbol := range transitions {
+			if !nfa.alphabet[symbol] {
+				return NewValidationError(fmt.Sprintf("transition symbol %v is not in the alphabet", symbol)).
+					WithContext("symbol", symbol)
+			}
+		}
+	}
+	return nil
+}
+
+func validateNFAMaxTransitions[Q State, S Symbol](maxTransitions int) NFAValidationRule[Q, S] {
+	return func(nfa *NFA[Q, S]) error {
+		totalTransitions := 0
+		for _, transitions := range nfa.transitions {
+			for _, targets := range transitions {
+				totalTransitions += len(targets)
+			}
+		}
+		for _, targets := range nfa.epsilons {
+			totalTransitions += len(targets)
+		}
+		if totalTransitions > maxTransitions {
+			return NewValidationError(fmt.Sprintf(
+				"number of transitions (%d) exceeds maximum allowed (%d)",
+				totalTransitions, maxTransitions)).WithContext("field", "transitions")
+		}
+		return nil
+	}
+}
+
+func validateNFANoUnreachableStates[Q State, S Symbol](nfa *NFA[Q, S]) error {
+	reachable := map[Q]bool{nfa.initialState: true}
+
+	// BFS over every branch of the transition relation, including ε-moves
+	queue := []Q{nfa.initialState}
+	visit := func(nextState Q) {
+		if !reachable[nextState] {
+			reachable[nextState] = true
+			queue = append(queue, nextState)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, targets := range nfa.transitions[current] {
+			for nextState := range targets {
+				visit(nextState)
+			}
+		}
+		for nextState := range nfa.epsilons[current] {
+			visit(nextState)
+		}
+	}
+
+	for state := range nfa.states {
+		if !reachable[state] {
+			return NewValidationError(fmt.Sprintf("state %v is unreachable from initial state", state)).
+				WithContext("state", state)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,969 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// YAML definitions are written in a dependency-free subset of YAML meant for
+// hand editing:
+//
+//	# Turnstile guarding the lobby
+//	version: 1
+//	initial: locked
+//	states: [locked, unlocked]
+//	alphabet: [coin, push]
+//	accepting: [unlocked]
+//	transitions:
+//	  locked: {coin: unlocked, push: locked}
+//	  unlocked:
+//	    coin: unlocked  # extra coins are kept
+//	    push: locked
+//
+// The transition table maps each state to a mapping from symbol to target
+// state, written either in block style or as a one-line flow mapping. Lists
+// may use flow ([a, b]) or block (- a) style. Scalars may be plain, 'single'
+// or "double" quoted. Anchors, aliases, tags, block scalars, multi-line flow
+// collections and multiple documents are not supported.
+
+// yamlKind identifies the type of a parsed YAML node.
+type yamlKind int
+
+const (
+	yamlNull yamlKind = iota
+	yamlScalar
+	yamlSequence
+	yamlMapping
+)
+
+// yamlComment holds the comments attached to a mapping entry or sequence item.
+type yamlComment struct {
+	// head holds the full-line comments preceding the entry
+	head []string
+	// line is the comment at the end of the entry's line
+	line string
+}
+
+func (c yamlComment) isEmpty() bool {
+	return len(c.head) == 0 && c.line == ""
+}
+
+// yamlNode is a parsed YAML value.
+type yamlNode struct {
+	kind     yamlKind
+	value    string
+	items    []*yamlNode
+	keys     []*yamlNode
+	values   []*yamlNode
+	position sourcePosition
+	comment  yamlComment
+}
+
+// yamlLine is a preprocessed line of a YAML document.
+type yamlLine struct {
+	number int
+	indent int
+	// text is the content after the indentation with any comment removed
+	text    string
+	comment string
+	// commented is true when the line carries a comment
+	commented bool
+}
+
+// yamlParser parses the block structure of a YAML document line by line.
+type yamlParser struct {
+	lines   []yamlLine
+	next    int
+	pending []string
+}
+
+func yamlError(position sourcePosition, message string) *AutomatonError {
+	return NewErrorWithContext(ErrorTypeValidation, "invalid YAML definition: "+message,
+		map[string]interface{}{
+			"line":   position.line,
+			"column": position.column,
+		})
+}
+
+// parseYAML parses a document into its root mapping and any trailing comments.
+func parseYAML(data []byte) (*yamlNode, []string, error) {
+	if !utf8.Valid(data) {
+		return nil, nil, yamlError(sourcePosition{1, 1}, "document is not valid UTF-8")
+	}
+
+	parser := &yamlParser{}
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		line, err := splitYAMLLine(i+1, raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		parser.lines = append(parser.lines, line)
+	}
+
+	root := &yamlNode{kind: yamlMapping, position: sourcePosition{1, 1}}
+	if line, ok := parser.peek(); ok {
+		if line.text == "---" {
+			parser.next++
+			line, ok = parser.peek()
+		}
+		if ok {
+			if line.indent != 0 {
+				return nil, nil, yamlError(sourcePosition{line.number, line.indent + 1}, "unexpected indentation")
+			}
+			var err error
+			if root, err = parser.parseMapping(0); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if line, ok := parser.peek(); ok {
+		return nil, nil, yamlError(sourcePosition{line.number, line.indent + 1}, "unexpected content")
+	}
+	return root, parser.pending, nil
+}
+
+// splitYAMLLine measures the indentation of a line and separates its comment.
+func splitYAMLLine(number int, raw string) (yamlLine, error) {
+	line := yamlLine{number: number}
+	for line.indent < len(raw) && raw[line.indent] == ' ' {
+		line.indent++
+	}
+	if line.indent < len(raw) && raw[line.indent] == '\t' {
+		return line, yamlError(sourcePosition{number, line.indent + 1}, "tabs cannot be used for indentation")
+	}
+
+	content := raw[line.indent:]
+	var quote rune
+	for i, r := range content {
+		switch {
+		case quote == '"' && r == '\\':
+			// The escaped character is skipped below
+		case quote != 0:
+			if r == quote && !(quote == '"' && i > 0 && yamlEscaped(content, i)) {
+				quote = 0
+			}
+		case (r == '"' || r == '\'') && (i == 0 || strings.ContainsRune(" [{,:", rune(content[i-1]))):
+			quote = r
+		case r == '#' && (i == 0 || content[i-1] == ' ' || content[i-1] == '\t'):
+			line.text = strings.TrimRight(content[:i], " \t")
+			line.comment = content[i+1:]
+			line.commented = true
+			return line, nil
+		}
+	}
+	line.text = strings.TrimRight(content, " \t")
+	return line, nil
+}
+
+// yamlEscaped reports whether the byte at i is preceded by an odd number of backslashes.
+func yamlEscaped(s string, i int) bool {
+	backslashes := 0
+	for j := i - 1; j >= 0 && s[j] == '\\'; j-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+// peek returns the next line with content, collecting full-line comments on the way.
+func (p *yamlParser) peek() (yamlLine, bool) {
+	for p.next < len(p.lines) {
+		line := p.lines[p.next]
+		if line.text != "" {
+			return line, true
+		}
+		if line.commented {
+			p.pending = append(p.pending, line.comment)
+		}
+		p.next++
+	}
+	return yamlLine{}, false
+}
+
+// takeComment returns the comments collected for the entry on the given line.
+func (p *yamlParser) takeComment(line yamlLine) yamlComment {
+	comment := yamlComment{head: p.pending, line: line.comment}
+	if !line.commented {
+		comment.line = ""
+	}
+	p.pending = nil
+	return comment
+}
+
+func (p *yamlParser) parseMapping(indent int) (*yamlNode, error) {
+	node := &yamlNode{kind: yamlMapping}
+	seen := make(map[string]bool)
+
+	for {
+		line, ok := p.peek()
+		if !ok || line.indent < indent {
+			return node, nil
+		}
+		position := sourcePosition{line.number, line.indent + 1}
+		if line.indent > indent {
+			return nil, yamlError(position, "unexpected indentation")
+		}
+		if node.position.line == 0 {
+			node.position = position
+		}
+		if line.text == "-" || strings.HasPrefix(line.text, "- ") {
+			return nil, yamlError(position, "expected a mapping key, found a sequence item")
+		}
+		p.next++
+
+		scanner := &yamlScanner{text: []rune(line.text), line: line.number, offset: line.indent}
+		key, err := scanner.scanKey()
+		if err != nil {
+			return nil, err
+		}
+		if seen[key.value] {
+			return nil, yamlError(key.position, fmt.Sprintf("duplicate key %q", key.value))
+		}
+		seen[key.value] = true
+		key.comment = p.takeComment(line)
+
+		var value *yamlNode
+		if scanner.atEnd() {
+			value, err = p.parseNested(indent, sourcePosition{line.number, len(line.text) + line.indent + 1})
+		} else {
+			value, err = scanner.scanInline()
+		}
+		if err != nil {
+			return nil, err
+		}
+		node.keys = append(node.keys, key)
+		node.values = append(node.values, value)
+	}
+}
+
+// parseNested parses the block value of a key that has nothing after its colon.
+func (p *yamlParser) parseNested(parentIndent int, position sourcePosition) (*yamlNode, error) {
+	line, ok := p.peek()
+	if !ok {
+		return &yamlNode{kind: yamlNull, position: position}, nil
+	}
+	isItem := line.text == "-" || strings.HasPrefix(line.text, "- ")
+	switch {
+	case isItem && line.indent >= parentIndent:
+		return p.parseSequence(line.indent)
+	case line.indent > parentIndent:
+		return p.parseMapping(line.indent)
+	default:
+		return &yamlNode{kind: yamlNull, position: position}, nil
+	}
+}
+
+func (p *yamlParser) parseSequence(indent int) (*yamlNode, error) {
+	node := &yamlNode{kind: yamlSequence}
+	for {
+		line, ok := p.peek()
+		if !ok || line.indent != indent || !(line.text == "-" || strings.HasPrefix(line.text, "- ")) {
+			if ok && line.indent > indent {
+				return nil, yamlError(sourcePosition{line.number, line.indent + 1},
+					"nested blocks inside sequences are not supported")
+			}
+			return node, nil
+		}
+		position := sourcePosition{line.number, line.indent + 1}
+		if node.position.line == 0 {
+			node.position = position
+		}
+		p.next++
+
+		scanner := &yamlScanner{text: []rune(line.text), line: line.number, offset: line.indent, pos: 1}
+		if scanner.atEnd() {
+			return nil, yamlError(position, "empty sequence item")
+		}
+		item, err := scanner.scanInline()
+		if err != nil {
+			return nil, err
+		}
+		item.comment = p.takeComment(line)
+		node.items = append(node.items, item)
+	}
+}
+
+// yamlScanner reads flow values and keys within a single line.
+type yamlScanner struct {
+	text   []rune
+	pos    int
+	line   int
+	offset int
+}
+
+func (s *yamlScanner) position() sourcePosition {
+	return sourcePosition{s.line, s.offset + s.pos + 1}
+}
+
+func (s *yamlScanner) errorf(format string, args ...interface{}) *AutomatonError {
+	return yamlError(s.position(), fmt.Sprintf(format, args...))
+}
+
+func (s *yamlScanner) skipSpaces() {
+	for s.pos < len(s.text) && (s.text[s.pos] == ' ' || s.text[s.pos] == '\t') {
+		s.pos++
+	}
+}
+
+func (s *yamlScanner) atEnd() bool {
+	s.skipSpaces()
+	return s.pos >= len(s.text)
+}
+
+func (s *yamlScanner) peekIs(r rune) bool {
+	return s.pos < len(s.text) && s.text[s.pos] == r
+}
+
+// scanKey reads a block mapping key and its colon.
+func (s *yamlScanner) scanKey() (*yamlNode, error) {
+	key, err := s.scanScalar(true)
+	if err != nil {
+		return nil, err
+	}
+	s.skipSpaces()
+	if !s.peekIs(':') {
+		return nil, s.errorf("expected ':' after key %q", key.value)
+	}
+	s.pos++
+	if s.pos < len(s.text) && s.text[s.pos] != ' ' {
+		return nil, s.errorf("expected a space after ':'")
+	}
+	return key, nil
+}
+
+// scanInline reads the rest of the line as a single value.
+func (s *yamlScanner) scanInline() (*yamlNode, error) {
+	s.skipSpaces()
+	var value *yamlNode
+	var err error
+	if s.peekIs('[') || s.peekIs('{') {
+		value, err = s.scanFlow()
+	} else {
+		value, err = s.scanScalar(false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !s.atEnd() {
+		return nil, s.errorf("unexpected %q after value", s.text[s.pos])
+	}
+	return value, nil
+}
+
+// scanFlow reads a flow sequence or flow mapping, which must end on the same line.
+func (s *yamlScanner) scanFlow() (*yamlNode, error) {
+	start := s.position()
+	open := s.text[s.pos]
+	s.pos++
+	closing := ']'
+	node := &yamlNode{kind: yamlSequence, position: start}
+	if open == '{' {
+		closing = '}'
+		node.kind = yamlMapping
+	}
+	seen := make(map[string]bool)
+
+	for {
+		if s.atEnd() {
+			return nil, yamlError(start, "flow collections must be closed on the same line")
+		}
+		if s.peekIs(closing) {
+			s.pos++
+			return node, nil
+		}
+
+		if node.kind == yamlSequence {
+			item, err := s.scanFlowValue()
+			if err != nil {
+				return nil, err
+			}
+			node.items = append(node.items, item)
+		} else {
+			key, err := s.scanScalar(true)
+			if err != nil {
+				return nil, err
+			}
+			if seen[key.value] {
+				return nil, yamlError(key.position, fmt.Sprintf("duplicate key %q", key.value))
+			}
+			seen[key.value] = true
+			s.skipSpaces()
+			if !s.peekIs(':') {
+				return nil, s.errorf("expected ':' after key %q", key.value)
+			}
+			s.pos++
+			value, err := s.scanFlowValue()
+			if err != nil {
+				return nil, err
+			}
+			node.keys = append(node.keys, key)
+			node.values = append(node.values, value)
+		}
+
+		s.skipSpaces()
+		switch {
+		case s.peekIs(','):
+			s.pos++
+		case s.peekIs(closing), s.pos >= len(s.text):
+		default:
+			return nil, s.errorf("expected ',' or '%c'", closing)
+		}
+	}
+}
+
+func (s *yamlScanner) scanFlowValue() (*yamlNode, error) {
+	s.skipSpaces()
+	if s.peekIs('[') || s.peekIs('{') {
+		return s.scanFlow()
+	}
+	return s.scanScalarIn(true, false)
+}
+
+// scanScalar reads a scalar in block context; keys stop at ':'.
+func (s *yamlScanner) scanScalar(isKey bool) (*yamlNode, error) {
+	return s.scanScalarIn(false, isKey)
+}
+
+func (s *yamlScanner) scanScalarIn(inFlow, isKey bool) (*yamlNode, error) {
+	s.skipSpaces()
+	position := s.position()
+	if s.pos >= len(s.text) {
+		return nil, s.errorf("expected a value")
+	}
+
+	switch r := s.text[s.pos]; r {
+	case '"':
+		value, err := s.scanDoubleQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return &yamlNode{kind: yamlScalar, value: value, position: position}, nil
+	case '\'':
+		value, err := s.scanSingleQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return &yamlNode{kind: yamlScalar, value: value, position: position}, nil
+	case '&', '*', '!', '|', '>', '%', '@', '`':
+		return nil, s.errorf("unsupported YAML syntax %q (anchors, aliases, tags and block scalars are not supported)", r)
+	case '[', '{', ']', '}', ',':
+		if isKey || inFlow {
+			return nil, s.errorf("unexpected %q", r)
+		}
+	}
+
+	start := s.pos
+	for s.pos < len(s.text) {
+		r := s.text[s.pos]
+		if inFlow && (r == ',' || r == '[' || r == ']' || r == '{' || r == '}') {
+			break
+		}
+		if r == ':' && (s.pos+1 == len(s.text) || s.text[s.pos+1] == ' ' ||
+			(inFlow && strings.ContainsRune(",]}", s.text[s.pos+1]))) {
+			if isKey || inFlow {
+				break
+			}
+			return nil, s.errorf("unexpected ':' in value; quote values containing ': '")
+		}
+		s.pos++
+	}
+	value := strings.TrimRight(string(s.text[start:s.pos]), " \t")
+	if value == "" {
+		return nil, yamlError(position, "expected a value")
+	}
+	if value == "~" || value == "null" {
+		return &yamlNode{kind: yamlNull, position: position}, nil
+	}
+	return &yamlNode{kind: yamlScalar, value: value, position: position}, nil
+}
+
+func (s *yamlScanner) scanSingleQuoted() (string, error) {
+	start := s.position()
+	s.pos++
+	var builder strings.Builder
+	for s.pos < len(s.text) {
+		r := s.text[s.pos]
+		s.pos++
+		if r != '\'' {
+			builder.WriteRune(r)
+			continue
+		}
+		if s.peekIs('\'') {
+			builder.WriteRune('\'')
+			s.pos++
+			continue
+		}
+		return builder.String(), nil
+	}
+	return "", yamlError(start, "unterminated quoted string")
+}
+
+func (s *yamlScanner) scanDoubleQuoted() (string, error) {
+	start := s.position()
+	s.pos++
+	var builder strings.Builder
+	for s.pos < len(s.text) {
+		r := s.text[s.pos]
+		s.pos++
+		switch r {
+		case '"':
+			return builder.String(), nil
+		case '\\':
+			if s.pos >= len(s.text) {
+				return "", yamlError(start, "unterminated quoted string")
+			}
+			escape := s.text[s.pos]
+			s.pos++
+			switch escape {
+			case 'n':
+				builder.WriteRune('\n')
+			case 't':
+				builder.WriteRune('\t')
+			case 'r':
+				builder.WriteRune('\r')
+			case '0':
+				builder.WriteRune(0)
+			case '"', '\\', '/', ' ':
+				builder.WriteRune(escape)
+			case 'x', 'u', 'U':
+				digits := map[rune]int{'x': 2, 'u': 4, 'U': 8}[escape]
+				if s.pos+digits > len(s.text) {
+					return "", s.errorf("invalid escape sequence")
+				}
+				code, err := strconv.ParseUint(string(s.text[s.pos:s.pos+digits]), 16, 32)
+				if err != nil || !utf8.ValidRune(rune(code)) {
+					return "", s.errorf("invalid escape sequence")
+				}
+				builder.WriteRune(rune(code))
+				s.pos += digits
+			default:
+				s.pos -= 2
+				return "", s.errorf("unknown escape sequence '\\%c'", escape)
+			}
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return "", yamlError(start, "unterminated quoted string")
+}
+
+// YAMLDefinition is an automaton read from a YAML document together with the
+// comments of that document, so that tools can rewrite a hand-edited file
+// without losing its annotations.
+type YAMLDefinition[Q State, S Symbol] struct {
+	// Automaton is the automaton described by the document. It may be
+	// replaced or modified before calling SerializeDefinition.
+	Automaton *FiniteAutomaton[Q, S]
+	// comments maps entry paths (e.g. "transitions/locked/coin") to their comments
+	comments map[string]yamlComment
+	footer   []string
+}
+
+// YAMLSerializer implements the Serializer interface using the YAML subset
+// described above. Any problem with a document is reported as an
+// AutomatonError of type ErrorTypeValidation; when the problem can be traced
+// to a place in the document, the error Context holds its "line" and "column".
+type YAMLSerializer[Q State, S Symbol] struct {
+	definitionCodecs[Q, S]
+}
+
+// NewYAMLSerializer creates a YAML serializer using DefaultCodec for states and
+// symbols and the default validator configuration.
+func NewYAMLSerializer[Q State, S Symbol]() *YAMLSerializer[Q, S] {
+	return NewYAMLSerializerWithCodecs(DefaultCodec[Q](), DefaultCodec[S]())
+}
+
+// NewYAMLSerializerWithCodecs creates a YAML serializer with custom state and symbol codecs.
+func NewYAMLSerializerWithCodecs[Q State, S Symbol](states Codec[Q], symbols Codec[S]) *YAMLSerializer[Q, S] {
+	return &YAMLSerializer[Q, S]{definitionCodecs: newDefinitionCodecs(states, symbols)}
+}
+
+// WithValidator replaces the validator run on deserialized automata.
+func (s *YAMLSerializer[Q, S]) WithValidator(validator *InputValidator[Q, S]) *YAMLSerializer[Q, S] {
+	s.validator = validator
+	return s
+}
+
+// Serialize encodes the automaton as YAML (implements Serializer interface).
+func (s *YAMLSerializer[Q, S]) Serialize(automaton Automaton[Q, S]) ([]byte, error) {
+	definition, err := s.encode(automaton)
+	if err != nil {
+		return nil, err
+	}
+	return writeYAMLDefinition(definition, nil, nil), nil
+}
+
+// Deserialize decodes a YAML definition (implements Serializer interface).
+func (s *YAMLSerializer[Q, S]) Deserialize(data []byte) (Automaton[Q, S], error) {
+	return s.DeserializeAutomaton(data)
+}
+
+// DeserializeAutomaton decodes a YAML definition into a FiniteAutomaton.
+func (s *YAMLSerializer[Q, S]) DeserializeAutomaton(data []byte) (*FiniteAutomaton[Q, S], error) {
+	definition, err := s.DeserializeDefinition(data)
+	if err != nil {
+		return nil, err
+	}
+	return definition.Automaton, nil
+}
+
+// DeserializeDefinition decodes a YAML definition and keeps its comments.
+func (s *YAMLSerializer[Q, S]) DeserializeDefinition(data []byte) (*YAMLDefinition[Q, S], error) {
+	root, footer, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &yamlDocument{
+		automaton:       &AutomatonDefinition{},
+		comments:        make(map[string]yamlComment),
+		sourcePositions: newSourcePositions(),
+	}
+	if err := document.read(root); err != nil {
+		return nil, err
+	}
+
+	fa, err := s.decode(document.automaton)
+	if err != nil {
+		return nil, s.locate(document.sourcePositions, err)
+	}
+	return &YAMLDefinition[Q, S]{Automaton: fa, comments: document.comments, footer: footer}, nil
+}
+
+// SerializeDefinition encodes the definition's automaton as YAML, restoring the
+// comments of entries that still exist.
+func (s *YAMLSerializer[Q, S]) SerializeDefinition(definition *YAMLDefinition[Q, S]) ([]byte, error) {
+	encoded, err := s.encode(definition.Automaton)
+	if err != nil {
+		return nil, err
+	}
+	return writeYAMLDefinition(encoded, definition.comments, definition.footer), nil
+}
+
+// yamlDocument converts a parsed document into an AutomatonDefinition while
+// recording where each part was written.
+type yamlDocument struct {
+	automaton *AutomatonDefinition
+	comments  map[string]yamlComment
+	*sourcePositions
+}
+
+func (d *yamlDocument) read(root *yamlNode) error {
+	hasVersion, hasInitial := false, false
+	for i, key := range root.keys {
+		value := root.values[i]
+		d.keepComment(key.value, key.comment)
+
+		switch key.value {
+		case "version":
+			text, err := yamlScalarValue(value, "version")
+			if err != nil {
+				return err
+			}
+			version, err := strconv.Atoi(text)
+			if err != nil {
+				return yamlError(value.position, fmt.Sprintf("version must be an integer, got %q", text))
+			}
+			d.automaton.Version = version
+			d.fields["version"] = value.position
+			hasVersion = true
+		case "initial":
+			text, err := yamlScalarValue(value, "initial state")
+			if err != nil {
+				return err
+			}
+			d.automaton.InitialState = text
+			d.fields["initialState"] = value.position
+			hasInitial = true
+			d.declareState(text, value.position)
+		case "states":
+			names, err := d.readList(key, value, "states", d.states)
+			if err != nil {
+				return err
+			}
+			d.automaton.States = names
+		case "alphabet":
+			names, err := d.readList(key, value, "alphabet", d.symbols)
+			if err != nil {
+				return err
+			}
+			d.automaton.Alphabet = names
+		case "accepting":
+			names, err := d.readList(key, value, "acceptingStates", d.states)
+			if err != nil {
+				return err
+			}
+			d.automaton.AcceptingStates = names
+		case "transitions":
+			d.fields["transitions"] = key.position
+			if err := d.readTransitions(value); err != nil {
+				return err
+			}
+		default:
+			return yamlError(key.position, fmt.Sprintf(
+				"unknown key %q (expected version, initial, states, alphabet, accepting or transitions)", key.value))
+		}
+	}
+	if !hasVersion {
+		return yamlError(root.position, "missing version")
+	}
+	if !hasInitial {
+		return yamlError(root.position, "missing initial state")
+	}
+	return nil
+}
+
+func (d *yamlDocument) readList(
+	key, value *yamlNode,
+	field string,
+	declared map[string]sourcePosition,
+) ([]string, error) {
+	d.fields[field] = key.position
+	if value.kind == yamlNull {
+		return []string{}, nil
+	}
+	if value.kind != yamlSequence {
+		return nil, yamlError(value.position, fmt.Sprintf("%s must be a list", key.value))
+	}
+
+	names := make([]string, len(value.items))
+	for i, item := range value.items {
+		text, err := yamlScalarValue(item, "list item")
+		if err != nil {
+			return nil, err
+		}
+		names[i] = text
+		d.fields[fmt.Sprintf("%s[%d]", field, i)] = item.position
+		d.keepComment(key.value+"/"+text, item.comment)
+		if field != "acceptingStates" {
+			declared[text] = item.position
+		}
+	}
+	return names, nil
+}
+
+func (d *yamlDocument) readTransitions(table *yamlNode) error {
+	if table.kind == yamlNull {
+		return nil
+	}
+	if table.kind != yamlMapping {
+		return yamlError(table.position, "transitions must map states to {symbol: target} tables")
+	}
+
+	for i, stateKey := range table.keys {
+		row := table.values[i]
+		from := stateKey.value
+		d.declareState(from, stateKey.position)
+		d.keepComment("transitions/"+from, stateKey.comment)
+		if row.kind == yamlNull {
+			continue
+		}
+		if row.kind != yamlMapping {
+			return yamlError(row.position, fmt.Sprintf("transitions of %q must map symbols to target states", from))
+		}
+
+		for j, symbolKey := range row.keys {
+			target, err := yamlScalarValue(row.values[j], "target state")
+			if err != nil {
+				return err
+			}
+			field := fmt.Sprintf("transitions[%d]", len(d.automaton.Transitions))
+			d.fields[field] = symbolKey.position
+			d.fields[field+".from"] = stateKey.position
+			d.fields[field+".symbol"] = symbolKey.position
+			d.fields[field+".to"] = row.values[j].position
+			d.declareSymbol(symbolKey.value, symbolKey.position)
+			d.declareState(target, row.values[j].position)
+			d.keepComment("transitions/"+from+"/"+symbolKey.value, symbolKey.comment)
+
+			d.automaton.Transitions = append(d.automaton.Transitions, TransitionDefinition{
+				From: from, Symbol: symbolKey.value, To: target,
+			})
+		}
+	}
+	return nil
+}
+
+func (d *yamlDocument) keepComment(path string, comment yamlComment) {
+	if !comment.isEmpty() {
+		d.comments[path] = comment
+	}
+}
+
+func yamlScalarValue(node *yamlNode, what string) (string, error) {
+	if node.kind == yamlNull {
+		return "", yamlError(node.position, fmt.Sprintf("missing %s", what))
+	}
+	if node.kind != yamlScalar {
+		return "", yamlError(node.position, fmt.Sprintf("%s must be a single value", what))
+	}
+	return node.value, nil
+}
+
+// writeYAMLDefinition renders a definition, attaching the given comments.
+func writeYAMLDefinition(definition *AutomatonDefinition, comments map[string]yamlComment, footer []string) []byte {
+	w := &yamlWriter{comments: comments}
+
+	w.entry(0, "version", "version: "+strconv.Itoa(definition.Version))
+	w.entry(0, "initial", "initial: "+quoteYAML(definition.InitialState))
+	w.list("states", definition.States)
+	w.list("alphabet", definition.Alphabet)
+	w.list("accepting", definition.AcceptingStates)
+
+	if len(definition.Transitions) == 0 {
+		w.entry(0, "transitions", "transitions: {}")
+	} else {
+		w.entry(0, "transitions", "transitions:")
+		for start := 0; start < len(definition.Transitions); {
+			from := definition.Transitions[start].From
+			end := start
+			for end < len(definition.Transitions) && definition.Transitions[end].From == from {
+				end++
+			}
+			w.row(from, definition.Transitions[start:end])
+			start = end
+		}
+	}
+
+	for _, comment := range footer {
+		w.builder.WriteString("#" + comment + "\n")
+	}
+	return []byte(w.builder.String())
+}
+
+// maxFlowRowWidth is the longest transition row written as a one-line flow mapping.
+const maxFlowRowWidth = 100
+
+type yamlWriter struct {
+	builder  strings.Builder
+	comments map[string]yamlComment
+}
+
+// entry writes a line with the head and line comments recorded for the path.
+func (w *yamlWriter) entry(indent int, path, text string) {
+	prefix := strings.Repeat("  ", indent)
+	comment := w.comments[path]
+	for _, head := range comment.head {
+		w.builder.WriteString(prefix + "#" + head + "\n")
+	}
+	w.builder.WriteString(prefix + text)
+	if comment.line != "" {
+		w.builder.WriteString("  #" + comment.line)
+	}
+	w.builder.WriteString("\n")
+}
+
+// list writes a flow list, or a block list when any item carries a comment.
+func (w *yamlWriter) list(key string, names []string) {
+	commented := false
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteYAML(name)
+		if _, exists := w.comments[key+"/"+name]; exists {
+			commented = true
+		}
+	}
+
+	if !commented {
+		w.entry(0, key, key+": ["+strings.Join(quoted, ", ")+"]")
+		return
+	}
+	w.entry(0, key, key+":")
+	for i, name := range names {
+		w.entry(1, key+"/"+name, "- "+quoted[i])
+	}
+}
+
+// row writes the transitions of one state.
+func (w *yamlWriter) row(from string, transitions []TransitionDefinition) {
+	path := "transitions/" + from
+	cells := make([]string, len(transitions))
+	commented := false
+	for i, transition := range transitions {
+		cells[i] = quoteYAML(transition.Symbol) + ": " + quoteYAML(transition.To)
+		if _, exists := w.comments[path+"/"+transition.Symbol]; exists {
+			commented = true
+		}
+	}
+
+	flow := quoteYAML(from) + ": {" + strings.Join(cells, ", ") + "}"
+	if !commented && len(flow)+2 <= maxFlowRowWidth {
+		w.entry(1, path, flow)
+		return
+	}
+	w.entry(1, path, quoteYAML(from)+":")
+	for i, transition := range transitions {
+		w.entry(2, path+"/"+transition.Symbol, cells[i])
+	}
+}
+
+// quoteYAML writes a scalar plainly when that is unambiguous and double-quoted otherwise.
+func quoteYAML(value string) string {
+	if isPlainYAML(value) {
+		return value
+	}
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for _, r := range value {
+		switch {
+		case r == '"' || r == '\\':
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			builder.WriteString(fmt.Sprintf(`\x%02x`, r))
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// isPlainYAML reports whether a value can be written unquoted and read back
+// as the same string by this parser and by full YAML parsers.
+func isPlainYAML(value string) bool {
+	if value == "" {
+		return false
+	}
+	switch strings.ToLower(value) {
+	case "~", "null", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return false
+	}
+	if isNumericYAML(value) {
+		return false
+	}
+	for i, r := range value {
+		isWordRune := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if isWordRune || r > utf8.RuneSelf {
+			continue
+		}
+		if i > 0 && (r == '-' || r == '.' || r == '/') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// isNumericYAML reports whether a plain value would be resolved as an integer
+// or a float, such as 1, 1.5, 0x1f, 0o17, 1_000 or 1e3. Values allowed in
+// plain scalars start with a word rune, so signs and .inf are already quoted.
+func isNumericYAML(value string) bool {
+	if value[0] < '0' || value[0] > '9' {
+		return false
+	}
+	digits := strings.ReplaceAll(value, "_", "")
+	if _, err := strconv.ParseInt(digits, 0, 64); err == nil || errors.Is(err, strconv.ErrRange) {
+		return true
+	}
+	_, err := strconv.ParseFloat(digits, 64)
+	return err == nil || errors.Is(err, strconv.ErrRange)
+}
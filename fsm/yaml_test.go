@@ -0,0 +1,287 @@
+package fsm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const turnstileYAML = `# Turnstile guarding the lobby
+version: 1
+initial: locked
+states: [locked, unlocked]
+alphabet: [coin, push]
+accepting: [unlocked]  # open means accepted
+transitions:
+  locked: {coin: unlocked, push: locked}
+  # paid and waiting
+  unlocked:
+    coin: unlocked  # extra coins are kept
+    push: locked
+`
+
+// TestYAMLSerializer_Deserialize tests reading a hand-written definition
+func TestYAMLSerializer_Deserialize(t *testing.T) {
+	fa, err := NewYAMLSerializer[string, string]().DeserializeAutomaton([]byte(turnstileYAML))
+	if err != nil {
+		t.Fatalf("DeserializeAutomaton returned error: %v", err)
+	}
+
+	if fa.GetInitialState() != "locked" {
+		t.Errorf("Expected initial state locked, got %q", fa.GetInitialState())
+	}
+	tests := []struct {
+		input    []string
+		expected bool
+	}{
+		{[]string{"coin"}, true},
+		{[]string{"coin", "coin"}, true},
+		{[]string{"coin", "push"}, false},
+		{[]string{"push", "push", "coin"}, true},
+	}
+	for _, tt := range tests {
+		accepted, err := fa.ProcessInput(tt.input)
+		if err != nil || accepted != tt.expected {
+			t.Errorf("ProcessInput(%v) = %v, %v; want %v", tt.input, accepted, err, tt.expected)
+		}
+	}
+}
+
+// TestYAMLSerializer_Serialize tests the exact output format
+func TestYAMLSerializer_Serialize(t *testing.T) {
+	fa := New[string, string]("start").
+		AddStates("start", "done", "yes").
+		AddSymbols("go", "a b").
+		AddAcceptingState("done").
+		AddTransition("start", "go", "done").
+		AddTransition("start", "a b", "yes")
+
+	data, err := NewYAMLSerializer[string, string]().Serialize(fa)
+	if err != nil {
+		t.Fatalf("Serialize returned error: %v", err)
+	}
+
+	want := `version: 1
+initial: start
+states: [done, start, "yes"]
+alphabet: ["a b", go]
+accepting: [done]
+transitions:
+  start: {"a b": "yes", go: done}
+`
+	if string(data) != want {
+		t.Errorf("Serialize() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+// TestYAMLSerializer_RoundTrip tests that output can be read back, including unusual names
+func TestYAMLSerializer_RoundTrip(t *testing.T) {
+	fa := New[string, rune]("q: 0").
+		AddStates("q: 0", "null", `"quoted" #1`, "tab\there").
+		AddSymbols('a', '#', '\'', '-').
+		AddAcceptingState("null").
+		AddTransition("q: 0", '#', "null").
+		AddTransition("q: 0", '\'', `"quoted" #1`).
+		AddTransition(`"quoted" #1`, '-', "tab\there").
+		AddTransition("tab\there", 'a', "null")
+
+	serializer := NewYAMLSerializer[string, rune]()
+	data, err := serializer.Serialize(fa)
+	if err != nil {
+		t.Fatalf("Serialize returned error: %v", err)
+	}
+	restored, err := serializer.DeserializeAutomaton(data)
+	if err != nil {
+		t.Fatalf("DeserializeAutomaton returned error: %v\n%s", err, data)
+	}
+
+	if equivalent, counterexample, err := Equivalent[string, rune](fa, restored); err != nil || !equivalent {
+		t.Errorf("Round trip changed the language (counterexample %q, err %v)", string(counterexample), err)
+	}
+	if restored.GetInitialState() != "q: 0" {
+		t.Errorf("Expected initial state %q, got %q", "q: 0", restored.GetInitialState())
+	}
+}
+
+// TestYAMLSerializer_NumericNames tests that names resolving to numbers are quoted and read back as strings
+func TestYAMLSerializer_NumericNames(t *testing.T) {
+	numeric := []string{"1", "1.5", "0x1f", "0o17", "1_000", "1e3", "017", "99999999999999999999"}
+	fa := New[string, string]("1").
+		AddStates(numeric...).
+		AddStates("1.2.3", "2nd").
+		AddSymbols("1e3", "x").
+		AddAcceptingState("1.2.3")
+	for i := 1; i < len(numeric); i++ {
+		fa.AddTransition(numeric[i-1], "1e3", numeric[i])
+	}
+	fa.AddTransition(numeric[len(numeric)-1], "x", "1.2.3").AddTransition("1.2.3", "x", "2nd")
+
+	serializer := NewYAMLSerializer[string, string]()
+	data, err := serializer.Serialize(fa)
+	if err != nil {
+		t.Fatalf("Serialize returned error: %v", err)
+	}
+	for _, fragment := range []string{`initial: "1"`, `alphabet: ["1e3", x]`, `"0x1f": {"1e3": "0o17"}`, `{x: 1.2.3}`, `1.2.3: {x: 2nd}`} {
+		if !strings.Contains(string(data), fragment) {
+			t.Errorf("Serialized definition is missing %s:\n%s", fragment, data)
+		}
+	}
+
+	restored, err := serializer.DeserializeAutomaton(data)
+	if err != nil {
+		t.Fatalf("DeserializeAutomaton returned error: %v\n%s", err, data)
+	}
+	for i := 1; i < len(numeric); i++ {
+		if next, err := restored.Transition(numeric[i-1], "1e3"); err != nil || next != numeric[i] {
+			t.Errorf("Transition(%s, 1e3) = %q, %v; want %q", numeric[i-1], next, err, numeric[i])
+		}
+	}
+	if restored.GetInitialState() != "1" || !restored.IsAcceptingState("1.2.3") {
+		t.Errorf("Unexpected restored automaton:\n%s", data)
+	}
+}
+
+// TestYAMLSerializer_PreservesComments tests rewriting a definition after modifying it
+func TestYAMLSerializer_PreservesComments(t *testing.T) {
+	serializer := NewYAMLSerializer[string, string]()
+	definition, err := serializer.DeserializeDefinition([]byte(turnstileYAML))
+	if err != nil {
+		t.Fatalf("DeserializeDefinition returned error: %v", err)
+	}
+
+	definition.Automaton.AddSymbol("kick").AddTransition("locked", "kick", "locked")
+	data, err := serializer.SerializeDefinition(definition)
+	if err != nil {
+		t.Fatalf("SerializeDefinition returned error: %v", err)
+	}
+
+	want := `# Turnstile guarding the lobby
+version: 1
+initial: locked
+states: [locked, unlocked]
+alphabet: [coin, kick, push]
+accepting: [unlocked]  # open means accepted
+transitions:
+  locked: {coin: unlocked, kick: locked, push: locked}
+  # paid and waiting
+  unlocked:
+    coin: unlocked  # extra coins are kept
+    push: locked
+`
+	if string(data) != want {
+		t.Errorf("SerializeDefinition() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+// TestYAMLSerializer_BlockLists tests block-style lists and their comments
+func TestYAMLSerializer_BlockLists(t *testing.T) {
+	document := `version: 1
+initial: a
+states:
+  # the start
+  - a
+  - 'b'  # the end
+alphabet:
+- x
+accepting: [b]
+transitions:
+  a:
+    x: b
+`
+	serializer := NewYAMLSerializer[string, rune]()
+	definition, err := serializer.DeserializeDefinition([]byte(document))
+	if err != nil {
+		t.Fatalf("DeserializeDefinition returned error: %v", err)
+	}
+	data, err := serializer.SerializeDefinition(definition)
+	if err != nil {
+		t.Fatalf("SerializeDefinition returned error: %v", err)
+	}
+
+	want := `version: 1
+initial: a
+states:
+  # the start
+  - a
+  - b  # the end
+alphabet: [x]
+accepting: [b]
+transitions:
+  a: {x: b}
+`
+	if string(data) != want {
+		t.Errorf("SerializeDefinition() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+// TestYAMLSerializer_ErrorPositions tests that failures report line and column
+func TestYAMLSerializer_ErrorPositions(t *testing.T) {
+	tests := []struct {
+		name   string
+		yaml   string
+		line   int
+		column int
+	}{
+		{"tab indentation", "version: 1\n\tinitial: a\n", 2, 1},
+		{"unknown key", "version: 1\ncolour: red\n", 2, 1},
+		{"duplicate key", "version: 1\nversion: 1\n", 2, 1},
+		{"bad version", "version: one\n", 1, 10},
+		{"missing version", "initial: a\n", 1, 1},
+		{"unclosed flow", "version: 1\nstates: [a, b\n", 2, 9},
+		{"anchor", "version: 1\ninitial: &a x\n", 2, 10},
+		{"bad indentation", "version: 1\ntransitions:\n  a: {x: b}\n   b: {x: a}\n", 4, 4},
+		{"unterminated quote", "version: 1\ninitial: \"a\n", 2, 10},
+		{"undeclared target", "version: 1\ninitial: a\nstates: [a]\nalphabet: [x]\n" +
+			"transitions:\n  a:\n    x: b\n", 7, 8},
+		{"undeclared symbol", "version: 1\ninitial: a\nstates: [a]\nalphabet: [x]\n" +
+			"transitions:\n  a: {y: a}\n", 6, 7},
+		{"bad rune symbol", "version: 1\ninitial: a\nstates: [a]\nalphabet: [x, yz]\n", 4, 15},
+		{"conflicting transitions", "version: 1\ninitial: a\nstates: [a, b]\nalphabet: [x]\n" +
+			"transitions:\n  a: {x: a}\n  \"a\": {x: b}\n", 7, 3},
+	}
+
+	serializer := NewYAMLSerializer[string, rune]()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := serializer.Deserialize([]byte(tt.yaml))
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !IsValidationError(err) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+			var automatonErr *AutomatonError
+			errors.As(err, &automatonErr)
+			if automatonErr.Context["line"] != tt.line || automatonErr.Context["column"] != tt.column {
+				t.Errorf("Expected position %d:%d, got %v:%v (%v)", tt.line, tt.column,
+					automatonErr.Context["line"], automatonErr.Context["column"], err)
+			}
+		})
+	}
+}
+
+// TestYAMLSerializer_StrictValidation tests positions of validator failures
+func TestYAMLSerializer_StrictValidation(t *testing.T) {
+	document := strings.Join([]string{
+		"version: 1",
+		"initial: a",
+		"states: [a, orphan]",
+		"alphabet: [x]",
+		"transitions:",
+		"  a: {x: a}",
+		"  orphan: {x: a}",
+	}, "\n")
+
+	serializer := NewYAMLSerializer[string, rune]().
+		WithValidator(NewInputValidator[string, rune](StrictValidatorConfig()))
+	_, err := serializer.Deserialize([]byte(document))
+	if !IsValidationError(err) {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+	var automatonErr *AutomatonError
+	errors.As(err, &automatonErr)
+	if automatonErr.Context["line"] != 3 || automatonErr.Context["column"] != 13 {
+		t.Errorf("Expected position 3:13 of the unreachable state, got %v:%v",
+			automatonErr.Context["line"], automatonErr.Context["column"])
+	}
+}
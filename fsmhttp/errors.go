@@ -0,0 +1,125 @@
+package fsmhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dsonic0912/PolicyReporter-FSM/fsm"
+)
+
+// Error codes returned in the "code" field of error responses. They are part
+// of the API and do not change between releases.
+const (
+	// CodeValidationFailed reports an automaton definition that failed validation
+	CodeValidationFailed = "validation_failed"
+	// CodeTransitionUndefined reports a symbol with no transition from the current state
+	CodeTransitionUndefined = "transition_undefined"
+	// CodeInvalidInput reports input symbols that cannot be decoded or are not
+	// in the alphabet
+	CodeInvalidInput = "invalid_input"
+	// CodeInvalidConfiguration reports a hosted automaton that cannot serve the request
+	CodeInvalidConfiguration = "invalid_configuration"
+	// CodeInternal reports an unexpected failure
+	CodeInternal = "internal_error"
+	// CodeBadRequest reports a malformed request body or query
+	CodeBadRequest = "bad_request"
+	// CodeNotFound reports an unknown machine or session
+	CodeNotFound = "not_found"
+	// CodeTooManySessions reports that the session limit was reached
+	CodeTooManySessions = "too_many_sessions"
+	// CodeBodyTooLarge reports a request body larger than Config.MaxBodyBytes
+	CodeBodyTooLarge = "body_too_large"
+)
+
+// errorCodes maps every fsm.ErrorType to its code and HTTP status.
+var errorCodes = map[fsm.ErrorType]struct {
+	code   string
+	status int
+}{
+	fsm.ErrorTypeValidation:           {CodeValidationFailed, http.StatusUnprocessableEntity},
+	fsm.ErrorTypeTransition:           {CodeTransitionUndefined, http.StatusUnprocessableEntity},
+	fsm.ErrorTypeInvalidInput:         {CodeInvalidInput, http.StatusBadRequest},
+	fsm.ErrorTypeInvalidConfiguration: {CodeInvalidConfiguration, http.StatusInternalServerError},
+	fsm.ErrorTypeInternal:             {CodeInternal, http.StatusInternalServerError},
+}
+
+// requestError is an error detected by the handler itself rather than by the
+// automaton.
+type requestError struct {
+	code    string
+	status  int
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+func badRequest(message string) *requestError {
+	return &requestError{code: CodeBadRequest, status: http.StatusBadRequest, message: message}
+}
+
+func bodyTooLarge(limit int64) *requestError {
+	return &requestError{
+		code:    CodeBodyTooLarge,
+		status:  http.StatusRequestEntityTooLarge,
+		message: fmt.Sprintf("the request body exceeds the limit of %d bytes", limit),
+	}
+}
+
+func notFound(message string) *requestError {
+	return &requestError{code: CodeNotFound, status: http.StatusNotFound, message: message}
+}
+
+// ErrorCode returns the code and HTTP status used to report err.
+// AutomatonErrors are reported by type; other errors are internal errors.
+func ErrorCode(err error) (string, int) {
+	var reqErr *requestError
+	if errors.As(err, &reqErr) {
+		return reqErr.code, reqErr.status
+	}
+	var automatonErr *fsm.AutomatonError
+	if errors.As(err, &automatonErr) {
+		if mapped, exists := errorCodes[automatonErr.Type]; exists {
+			return mapped.code, mapped.status
+		}
+	}
+	return CodeInternal, http.StatusInternalServerError
+}
+
+// errorResponse is the body of every error response:
+//
+//	{"code": "transition_undefined", "error": {"type": "TransitionError", ...}}
+//
+// The error is the JSON encoding of the AutomatonError when there is one, and
+// an object with only a message otherwise.
+type errorResponse struct {
+	Code    string       `json:"code"`
+	Error   interface{}  `json:"error"`
+	Session *sessionView `json:"session,omitempty"`
+}
+
+func newErrorResponse(err error) (errorResponse, int) {
+	code, status := ErrorCode(err)
+	response := errorResponse{Code: code, Error: map[string]string{"message": err.Error()}}
+	var automatonErr *fsm.AutomatonError
+	if errors.As(err, &automatonErr) {
+		response.Error = automatonErr
+	}
+	return response, status
+}
+
+// writeJSON writes value as the JSON body of a response with the status.
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes err as an error response.
+func writeError(w http.ResponseWriter, err error) {
+	response, status := newErrorResponse(err)
+	writeJSON(w, status, response)
+}
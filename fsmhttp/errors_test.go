@@ -0,0 +1,35 @@
+package fsmhttp
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/dsonic0912/PolicyReporter-FSM/fsm"
+)
+
+// TestErrorCode tests the code and status of every error type
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err    error
+		code   string
+		status int
+	}{
+		{fsm.NewValidationError("bad"), CodeValidationFailed, http.StatusUnprocessableEntity},
+		{fsm.NewTransitionError("a", "x", "none"), CodeTransitionUndefined, http.StatusUnprocessableEntity},
+		{fsm.NewInvalidInputError("x", 0, "bad"), CodeInvalidInput, http.StatusBadRequest},
+		{fsm.NewInvalidConfigurationError("c", "bad"), CodeInvalidConfiguration, http.StatusInternalServerError},
+		{fsm.NewError(fsm.ErrorTypeInternal, "bad"), CodeInternal, http.StatusInternalServerError},
+		{fsm.NewError(fsm.ErrorType(99), "bad"), CodeInternal, http.StatusInternalServerError},
+		{errors.New("plain"), CodeInternal, http.StatusInternalServerError},
+		{notFound("gone"), CodeNotFound, http.StatusNotFound},
+		{badRequest("bad"), CodeBadRequest, http.StatusBadRequest},
+		{bodyTooLarge(10), CodeBodyTooLarge, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		code, status := ErrorCode(tt.err)
+		if code != tt.code || status != tt.status {
+			t.Errorf("ErrorCode(%v) = %s, %d; want %s, %d", tt.err, code, status, tt.code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,528 @@
+// Package fsmhttp serves automata over HTTP so that services written in other
+// languages can evaluate them.
+//
+// A Handler hosts named automata loaded through an fsm.Serializer and serves
+// the following JSON API:
+//
+//	GET    /machines                      list the hosted machines
+//	GET    /machines/{name}               the definition, as written by the serializer
+//	POST   /machines/{name}/evaluate      run {"input": [...]} from the initial state
+//	GET    /machines/{name}/diagram       ?format=dot (default), mermaid or plantuml
+//	POST   /machines/{name}/sessions      open a session in the initial state
+//	GET    /sessions/{id}                 the state of a session
+//	POST   /sessions/{id}/step            feed {"input": [...]} to a session
+//	POST   /sessions/{id}/reset           return a session to the initial state
+//	DELETE /sessions/{id}                 close a session
+//
+// Sessions left idle for longer than Config.SessionTTL expire and are
+// reported as not found.
+//
+// Symbols and states are written as strings using the handler's codecs.
+// Errors are returned as {"code": ..., "error": ...} where code is one of the
+// Code constants and error is the JSON encoding of the fsm.AutomatonError.
+package fsmhttp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dsonic0912/PolicyReporter-FSM/fsm"
+)
+
+// Config holds the limits and content types of a Handler.
+type Config struct {
+	// DefinitionContentType is the media type of the serializer's output
+	DefinitionContentType string
+	// MaxSessions bounds the number of open sessions; 0 means no limit
+	MaxSessions int
+	// SessionTTL is how long a session may stay unused before it expires;
+	// 0 means sessions never expire
+	SessionTTL time.Duration
+	// MaxBodyBytes bounds the size of request bodies; 0 means no limit
+	MaxBodyBytes int64
+}
+
+// DefaultConfig returns a configuration for JSON definitions with at most
+// 1000 open sessions, expiring after 30 minutes unused, and request bodies of
+// up to 1 MiB.
+func DefaultConfig() Config {
+	return Config{
+		DefinitionContentType: "application/json",
+		MaxSessions:           1000,
+		SessionTTL:            30 * time.Minute,
+		MaxBodyBytes:          1 << 20,
+	}
+}
+
+// Handler is an http.Handler hosting named automata. It is safe for
+// concurrent use.
+type Handler[Q fsm.State, S fsm.Symbol] struct {
+	config     Config
+	serializer fsm.Serializer[Q, S]
+	states     fsm.Codec[Q]
+	symbols    fsm.Codec[S]
+	mux        *http.ServeMux
+	// now returns the current time; tests replace it to expire sessions
+	now func() time.Time
+
+	mutex    sync.RWMutex
+	machines map[string]*machine[Q, S]
+	sessions map[string]*session[Q, S]
+}
+
+// machine is a hosted automaton. Evaluations run concurrently on the compiled
+// machine; sessions get their own copy of the automaton.
+type machine[Q fsm.State, S fsm.Symbol] struct {
+	name       string
+	definition []byte
+	automaton  fsm.Automaton[Q, S]
+	compiled   *fsm.Machine[Q, S]
+}
+
+// session is an automaton stepped by successive requests.
+type session[Q fsm.State, S fsm.Symbol] struct {
+	id        string
+	machine   string
+	automaton fsm.Automaton[Q, S]
+	trace     []Q
+	mutex     sync.Mutex
+	// lastUsed is the time of the last request to the session, in Unix
+	// nanoseconds
+	lastUsed atomic.Int64
+}
+
+// NewHandler creates a handler loading automata with the serializer.
+func NewHandler[Q fsm.State, S fsm.Symbol](serializer fsm.Serializer[Q, S], config Config) *Handler[Q, S] {
+	h := &Handler[Q, S]{
+		config:     config,
+		serializer: serializer,
+		states:     fsm.DefaultCodec[Q](),
+		symbols:    fsm.DefaultCodec[S](),
+		mux:        http.NewServeMux(),
+		now:        time.Now,
+		machines:   make(map[string]*machine[Q, S]),
+		sessions:   make(map[string]*session[Q, S]),
+	}
+	h.mux.HandleFunc("GET /machines", h.listMachines)
+	h.mux.HandleFunc("GET /machines/{name}", h.getDefinition)
+	h.mux.HandleFunc("POST /machines/{name}/evaluate", h.evaluate)
+	h.mux.HandleFunc("GET /machines/{name}/diagram", h.diagram)
+	h.mux.HandleFunc("POST /machines/{name}/sessions", h.openSession)
+	h.mux.HandleFunc("GET /sessions/{id}", h.getSession)
+	h.mux.HandleFunc("POST /sessions/{id}/step", h.stepSession)
+	h.mux.HandleFunc("POST /sessions/{id}/reset", h.resetSession)
+	h.mux.HandleFunc("DELETE /sessions/{id}", h.closeSession)
+	return h
+}
+
+// WithStateCodec sets the codec writing states in responses.
+func (h *Handler[Q, S]) WithStateCodec(states fsm.Codec[Q]) *Handler[Q, S] {
+	h.states = states
+	return h
+}
+
+// WithSymbolCodec sets the codec reading input symbols from requests.
+func (h *Handler[Q, S]) WithSymbolCodec(symbols fsm.Codec[S]) *Handler[Q, S] {
+	h.symbols = symbols
+	return h
+}
+
+// Load deserializes a definition, compiles it for evaluation and hosts it
+// under name, replacing any machine with that name. Sessions of a replaced
+// machine keep running on the old definition.
+func (h *Handler[Q, S]) Load(name string, definition []byte) error {
+	if name == "" {
+		return fsm.NewInvalidConfigurationError("fsmhttp", "machine name cannot be empty")
+	}
+	automaton, err := h.serializer.Deserialize(definition)
+	if err != nil {
+		return err
+	}
+	compiled, err := fsm.Compile(automaton)
+	if err != nil {
+		return err
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.machines[name] = &machine[Q, S]{
+		name:       name,
+		definition: append([]byte{}, definition...),
+		automaton:  automaton,
+		compiled:   compiled,
+	}
+	return nil
+}
+
+// Remove stops hosting a machine, reporting whether it existed. Its open
+// sessions are closed.
+func (h *Handler[Q, S]) Remove(name string) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if _, exists := h.machines[name]; !exists {
+		return false
+	}
+	delete(h.machines, name)
+	for id, s := range h.sessions {
+		if s.machine == name {
+			delete(h.sessions, id)
+		}
+	}
+	return true
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler[Q, S]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// inputRequest is the body of evaluate and step requests.
+type inputRequest struct {
+	Input []string `json:"input"`
+}
+
+// evaluation is the response of evaluate.
+type evaluation struct {
+	Accepted   bool     `json:"accepted"`
+	FinalState string   `json:"finalState"`
+	Trace      []string `json:"trace"`
+}
+
+// sessionView is the JSON form of a session.
+type sessionView struct {
+	ID        string   `json:"id"`
+	Machine   string   `json:"machine"`
+	State     string   `json:"state"`
+	Accepting bool     `json:"accepting"`
+	Position  int      `json:"position"`
+	Trace     []string `json:"trace"`
+}
+
+func (h *Handler[Q, S]) listMachines(w http.ResponseWriter, _ *http.Request) {
+	h.mutex.RLock()
+	names := make([]string, 0, len(h.machines))
+	for name := range h.machines {
+		names = append(names, name)
+	}
+	h.mutex.RUnlock()
+	sort.Strings(names)
+	writeJSON(w, http.StatusOK, map[string][]string{"machines": names})
+}
+
+func (h *Handler[Q, S]) getDefinition(w http.ResponseWriter, r *http.Request) {
+	m, err := h.machine(r.PathValue("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", h.config.DefinitionContentType)
+	_, _ = w.Write(m.definition)
+}
+
+func (h *Handler[Q, S]) evaluate(w http.ResponseWriter, r *http.Request) {
+	m, err := h.machine(r.PathValue("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	input, err := h.readInput(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	trace, accepted, err := m.compiled.ProcessInputWithTrace(input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	names, err := h.encodeStates(trace)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, evaluation{Accepted: accepted, FinalState: names[len(names)-1], Trace: names})
+}
+
+func (h *Handler[Q, S]) diagram(w http.ResponseWriter, r *http.Request) {
+	m, err := h.machine(r.PathValue("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	stateLabel := func(state Q) string {
+		name, err := h.states.Encode(state)
+		if err != nil {
+			return fmt.Sprintf("%v", state)
+		}
+		return name
+	}
+	var diagram, contentType string
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "dot":
+		options := fsm.DefaultDOTOptions[Q, S]()
+		options.StateLabel = stateLabel
+		diagram, err = fsm.ToDOTWithOptions(m.automaton, options)
+		contentType = "text/vnd.graphviz; charset=utf-8"
+	case "mermaid", "plantuml":
+		options := fsm.DefaultDiagramOptions[Q, S]()
+		options.StateLabel = stateLabel
+		if format == "mermaid" {
+			diagram, err = fsm.ToMermaidWithOptions(m.automaton, options)
+		} else {
+			diagram, err = fsm.ToPlantUMLWithOptions(m.automaton, options)
+		}
+		contentType = "text/plain; charset=utf-8"
+	default:
+		err = badRequest(fmt.Sprintf("unknown diagram format %q (expected dot, mermaid or plantuml)", format))
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write([]byte(diagram))
+}
+
+func (h *Handler[Q, S]) openSession(w http.ResponseWriter, r *http.Request) {
+	m, err := h.machine(r.PathValue("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// Each session steps its own copy of the automaton.
+	automaton, err := h.serializer.Deserialize(m.definition)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	id, err := newSessionID()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	automaton.Reset()
+	s := &session[Q, S]{id: id, machine: m.name, automaton: automaton, trace: []Q{automaton.GetCurrentState()}}
+	s.lastUsed.Store(h.now().UnixNano())
+
+	h.mutex.Lock()
+	h.evictIdleSessions()
+	if h.config.MaxSessions > 0 && len(h.sessions) >= h.config.MaxSessions {
+		h.mutex.Unlock()
+		writeError(w, &requestError{
+			code:    CodeTooManySessions,
+			status:  http.StatusTooManyRequests,
+			message: fmt.Sprintf("the limit of %d open sessions is reached", h.config.MaxSessions),
+		})
+		return
+	}
+	h.sessions[id] = s
+	h.mutex.Unlock()
+
+	w.Header().Set("Location", "/sessions/"+id)
+	h.writeSession(w, http.StatusCreated, s)
+}
+
+func (h *Handler[Q, S]) getSession(w http.ResponseWriter, r *http.Request) {
+	s, err := h.session(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	h.writeSession(w, http.StatusOK, s)
+}
+
+// stepSession feeds the input one symbol at a time. If a symbol fails, the
+// symbols before it stay consumed and the error response includes the
+// session.
+func (h *Handler[Q, S]) stepSession(w http.ResponseWriter, r *http.Request) {
+	s, err := h.session(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	input, err := h.readInput(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, symbol := range input {
+		state, err := s.automaton.Step(symbol)
+		if err != nil {
+			response, status := newErrorResponse(err)
+			if view, viewErr := h.viewSession(s); viewErr == nil {
+				response.Session = &view
+			}
+			writeJSON(w, status, response)
+			return
+		}
+		s.trace = append(s.trace, state)
+	}
+	h.writeSession(w, http.StatusOK, s)
+}
+
+func (h *Handler[Q, S]) resetSession(w http.ResponseWriter, r *http.Request) {
+	s, err := h.session(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.automaton.Reset()
+	s.trace = []Q{s.automaton.GetCurrentState()}
+	h.writeSession(w, http.StatusOK, s)
+}
+
+func (h *Handler[Q, S]) closeSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	h.mutex.Lock()
+	_, exists := h.sessions[id]
+	delete(h.sessions, id)
+	h.mutex.Unlock()
+	if !exists {
+		writeError(w, notFound(fmt.Sprintf("session %q not found", id)))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler[Q, S]) machine(name string) (*machine[Q, S], error) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	m, exists := h.machines[name]
+	if !exists {
+		return nil, notFound(fmt.Sprintf("machine %q not found", name))
+	}
+	return m, nil
+}
+
+// session returns an open session and marks it as used. An expired session
+// is closed and reported as not found.
+func (h *Handler[Q, S]) session(id string) (*session[Q, S], error) {
+	h.mutex.RLock()
+	s, exists := h.sessions[id]
+	h.mutex.RUnlock()
+	if !exists {
+		return nil, notFound(fmt.Sprintf("session %q not found", id))
+	}
+
+	now := h.now()
+	if h.expired(s, now) {
+		h.mutex.Lock()
+		if h.sessions[id] == s {
+			delete(h.sessions, id)
+		}
+		h.mutex.Unlock()
+		return nil, notFound(fmt.Sprintf("session %q expired", id))
+	}
+	s.lastUsed.Store(now.UnixNano())
+	return s, nil
+}
+
+// expired reports whether a session has been idle for longer than the TTL.
+func (h *Handler[Q, S]) expired(s *session[Q, S], now time.Time) bool {
+	return h.config.SessionTTL > 0 && now.UnixNano()-s.lastUsed.Load() > int64(h.config.SessionTTL)
+}
+
+// evictIdleSessions closes the expired sessions; the caller holds the mutex.
+func (h *Handler[Q, S]) evictIdleSessions() {
+	if h.config.SessionTTL <= 0 {
+		return
+	}
+	now := h.now()
+	for id, s := range h.sessions {
+		if h.expired(s, now) {
+			delete(h.sessions, id)
+		}
+	}
+}
+
+// readInput decodes the request body and its symbols. A symbol the codec
+// cannot decode is reported as invalid input at its position.
+func (h *Handler[Q, S]) readInput(w http.ResponseWriter, r *http.Request) ([]S, error) {
+	var request inputRequest
+	body := r.Body
+	if h.config.MaxBodyBytes > 0 {
+		body = http.MaxBytesReader(w, body, h.config.MaxBodyBytes)
+	}
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, bodyTooLarge(tooLarge.Limit)
+		}
+		return nil, badRequest(fmt.Sprintf("invalid request body: %v", err))
+	}
+	input := make([]S, len(request.Input))
+	for i, text := range request.Input {
+		symbol, err := h.symbols.Decode(text)
+		if err != nil {
+			return nil, fsm.NewInvalidInputError(text, i, fmt.Sprintf("cannot decode symbol %q", text)).WithCause(err)
+		}
+		input[i] = symbol
+	}
+	return input, nil
+}
+
+// encodeStates writes states with the state codec.
+func (h *Handler[Q, S]) encodeStates(states []Q) ([]string, error) {
+	names := make([]string, len(states))
+	for i, state := range states {
+		name, err := h.states.Encode(state)
+		if err != nil {
+			return nil, fsm.NewErrorWithCause(fsm.ErrorTypeInternal, fmt.Sprintf("cannot encode state %v", state), err)
+		}
+		names[i] = name
+	}
+	return names, nil
+}
+
+// viewSession returns the JSON form of a session; the caller holds its mutex.
+func (h *Handler[Q, S]) viewSession(s *session[Q, S]) (sessionView, error) {
+	trace, err := h.encodeStates(s.trace)
+	if err != nil {
+		return sessionView{}, err
+	}
+	return sessionView{
+		ID:        s.id,
+		Machine:   s.machine,
+		State:     trace[len(trace)-1],
+		Accepting: s.automaton.IsCurrentStateAccepting(),
+		Position:  len(trace) - 1,
+		Trace:     trace,
+	}, nil
+}
+
+func (h *Handler[Q, S]) writeSession(w http.ResponseWriter, status int, s *session[Q, S]) {
+	view, err := h.viewSession(s)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, status, view)
+}
+
+// newSessionID returns a random, unguessable session identifier.
+func newSessionID() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", fsm.NewErrorWithCause(fsm.ErrorTypeInternal, "cannot generate a session ID", err)
+	}
+	return hex.EncodeToString(id[:]), nil
+}
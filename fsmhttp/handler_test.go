@@ -0,0 +1,352 @@
+package fsmhttp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dsonic0912/PolicyReporter-FSM/fsm"
+)
+
+const turnstileDefinition = `{
+  "version": 1,
+  "states": ["locked", "unlocked"],
+  "alphabet": ["coin", "push"],
+  "initialState": "locked",
+  "acceptingStates": ["unlocked"],
+  "transitions": [
+    {"from": "locked", "symbol": "coin", "to": "unlocked"},
+    {"from": "locked", "symbol": "push", "to": "locked"},
+    {"from": "unlocked", "symbol": "coin", "to": "unlocked"}
+  ]
+}`
+
+// newTestServer starts a server hosting the turnstile machine
+func newTestServer(t *testing.T, config Config) (*httptest.Server, *Handler[string, string]) {
+	t.Helper()
+	handler := NewHandler[string, string](fsm.NewJSONSerializer[string, string](), config)
+	if err := handler.Load("turnstile", []byte(turnstileDefinition)); err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server, handler
+}
+
+// call sends a request and decodes the JSON response into result, if not nil
+func call(t *testing.T, method, url, body string, result interface{}) *http.Response {
+	t.Helper()
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest returned error: %v", err)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("%s %s returned error: %v", method, url, err)
+	}
+	defer response.Body.Close()
+	if result != nil {
+		if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+			t.Fatalf("%s %s returned invalid JSON: %v", method, url, err)
+		}
+	}
+	return response
+}
+
+// errorBody is the decoded form of an error response
+type errorBody struct {
+	Code  string `json:"code"`
+	Error struct {
+		Type    string                 `json:"type"`
+		Message string                 `json:"message"`
+		Context map[string]interface{} `json:"context"`
+	} `json:"error"`
+	Session *sessionView `json:"session"`
+}
+
+// TestHandler_Machines tests listing machines and fetching a definition
+func TestHandler_Machines(t *testing.T) {
+	server, _ := newTestServer(t, DefaultConfig())
+
+	var list struct {
+		Machines []string `json:"machines"`
+	}
+	call(t, "GET", server.URL+"/machines", "", &list)
+	if len(list.Machines) != 1 || list.Machines[0] != "turnstile" {
+		t.Errorf("Unexpected machine list %v", list.Machines)
+	}
+
+	response, err := http.Get(server.URL + "/machines/turnstile")
+	if err != nil {
+		t.Fatalf("GET returned error: %v", err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	if response.Header.Get("Content-Type") != "application/json" || !strings.Contains(string(body), `"initialState": "locked"`) {
+		t.Errorf("Unexpected definition %q (%s)", body, response.Header.Get("Content-Type"))
+	}
+
+	var missing errorBody
+	if response := call(t, "GET", server.URL+"/machines/nope", "", &missing); response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", response.StatusCode)
+	}
+	if missing.Code != CodeNotFound || missing.Error.Message != `machine "nope" not found` {
+		t.Errorf("Unexpected error %+v", missing)
+	}
+}
+
+// TestHandler_Evaluate tests evaluating inputs and the error responses
+func TestHandler_Evaluate(t *testing.T) {
+	server, _ := newTestServer(t, DefaultConfig())
+	url := server.URL + "/machines/turnstile/evaluate"
+
+	var result evaluation
+	if response := call(t, "POST", url, `{"input": ["push", "coin"]}`, &result); response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", response.StatusCode)
+	}
+	if !result.Accepted || result.FinalState != "unlocked" || strings.Join(result.Trace, ",") != "locked,locked,unlocked" {
+		t.Errorf("Unexpected evaluation %+v", result)
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		code    string
+		errType string
+	}{
+		{"undefined transition", `{"input": ["coin", "push"]}`, http.StatusUnprocessableEntity, CodeTransitionUndefined, "TransitionError"},
+		{"symbol outside the alphabet", `{"input": ["kick"]}`, http.StatusBadRequest, CodeInvalidInput, "InvalidInputError"},
+		{"malformed body", `{"input": "coin"}`, http.StatusBadRequest, CodeBadRequest, ""},
+		{"unknown field", `{"symbols": []}`, http.StatusBadRequest, CodeBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body errorBody
+			response := call(t, "POST", url, tt.body, &body)
+			if response.StatusCode != tt.status || body.Code != tt.code || body.Error.Type != tt.errType {
+				t.Errorf("Got %d %+v, want %d %s %s", response.StatusCode, body, tt.status, tt.code, tt.errType)
+			}
+		})
+	}
+
+	var body errorBody
+	call(t, "POST", url, `{"input": ["coin", "push"]}`, &body)
+	if body.Error.Context["state"] != "unlocked" || body.Error.Context["symbol"] != "push" {
+		t.Errorf("Expected the transition in the error context, got %v", body.Error.Context)
+	}
+}
+
+// TestHandler_Sessions tests stepping a session across requests
+func TestHandler_Sessions(t *testing.T) {
+	server, _ := newTestServer(t, DefaultConfig())
+
+	var created sessionView
+	response := call(t, "POST", server.URL+"/machines/turnstile/sessions", "", &created)
+	if response.StatusCode != http.StatusCreated || response.Header.Get("Location") != "/sessions/"+created.ID {
+		t.Fatalf("Unexpected response %d, Location %q", response.StatusCode, response.Header.Get("Location"))
+	}
+	if created.State != "locked" || created.Accepting || created.Position != 0 || created.Machine != "turnstile" {
+		t.Errorf("Unexpected new session %+v", created)
+	}
+	url := server.URL + "/sessions/" + created.ID
+
+	var stepped sessionView
+	call(t, "POST", url+"/step", `{"input": ["push"]}`, &stepped)
+	call(t, "POST", url+"/step", `{"input": ["coin"]}`, &stepped)
+	if stepped.State != "unlocked" || !stepped.Accepting || stepped.Position != 2 {
+		t.Errorf("Unexpected session after stepping %+v", stepped)
+	}
+
+	// The first symbol is consumed before the second fails.
+	var failed errorBody
+	response = call(t, "POST", url+"/step", `{"input": ["coin", "push"]}`, &failed)
+	if response.StatusCode != http.StatusUnprocessableEntity || failed.Code != CodeTransitionUndefined {
+		t.Errorf("Unexpected error response %d %+v", response.StatusCode, failed)
+	}
+	if failed.Session == nil || failed.Session.Position != 3 {
+		t.Errorf("Expected the session at position 3 in the error, got %+v", failed.Session)
+	}
+
+	// Evaluations do not disturb sessions.
+	call(t, "POST", server.URL+"/machines/turnstile/evaluate", `{"input": ["push"]}`, nil)
+	var current sessionView
+	call(t, "GET", url, "", &current)
+	if current.State != "unlocked" || strings.Join(current.Trace, ",") != "locked,locked,unlocked,unlocked" {
+		t.Errorf("Unexpected session %+v", current)
+	}
+
+	var reset sessionView
+	call(t, "POST", url+"/reset", "", &reset)
+	if reset.State != "locked" || reset.Position != 0 {
+		t.Errorf("Unexpected session after reset %+v", reset)
+	}
+
+	if response := call(t, "DELETE", url, "", nil); response.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", response.StatusCode)
+	}
+	var missing errorBody
+	if response := call(t, "GET", url, "", &missing); response.StatusCode != http.StatusNotFound || missing.Code != CodeNotFound {
+		t.Errorf("Expected a closed session to be gone, got %d %+v", response.StatusCode, missing)
+	}
+}
+
+// TestHandler_SessionLimit tests the MaxSessions limit and Remove
+func TestHandler_SessionLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxSessions = 1
+	server, handler := newTestServer(t, config)
+
+	var first sessionView
+	call(t, "POST", server.URL+"/machines/turnstile/sessions", "", &first)
+	var limited errorBody
+	response := call(t, "POST", server.URL+"/machines/turnstile/sessions", "", &limited)
+	if response.StatusCode != http.StatusTooManyRequests || limited.Code != CodeTooManySessions {
+		t.Errorf("Expected the session limit, got %d %+v", response.StatusCode, limited)
+	}
+
+	if !handler.Remove("turnstile") || handler.Remove("turnstile") {
+		t.Error("Remove should remove an existing machine exactly once")
+	}
+	if response := call(t, "GET", server.URL+"/sessions/"+first.ID, "", nil); response.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected sessions of a removed machine to be closed, got %d", response.StatusCode)
+	}
+}
+
+// TestHandler_MaxBodyBytes tests the body limit and that a zero limit means no limit
+func TestHandler_MaxBodyBytes(t *testing.T) {
+	server, _ := newTestServer(t, Config{MaxSessions: 10})
+	var result evaluation
+	if response := call(t, "POST", server.URL+"/machines/turnstile/evaluate", `{"input": ["coin"]}`, &result); response.StatusCode != http.StatusOK || !result.Accepted {
+		t.Errorf("Expected a partial config to accept bodies, got %d %+v", response.StatusCode, result)
+	}
+
+	config := DefaultConfig()
+	config.MaxBodyBytes = 16
+	server, _ = newTestServer(t, config)
+	var body errorBody
+	response := call(t, "POST", server.URL+"/machines/turnstile/evaluate", `{"input": ["coin", "coin", "coin"]}`, &body)
+	if response.StatusCode != http.StatusRequestEntityTooLarge || body.Code != CodeBodyTooLarge {
+		t.Errorf("Expected 413 %s, got %d %+v", CodeBodyTooLarge, response.StatusCode, body)
+	}
+}
+
+// TestHandler_SessionTTL tests that idle sessions expire and stop counting against MaxSessions
+func TestHandler_SessionTTL(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxSessions = 2
+	config.SessionTTL = time.Minute
+	server, handler := newTestServer(t, config)
+	now := time.Now()
+	handler.now = func() time.Time { return now }
+
+	var idle, used sessionView
+	call(t, "POST", server.URL+"/machines/turnstile/sessions", "", &idle)
+	call(t, "POST", server.URL+"/machines/turnstile/sessions", "", &used)
+
+	// Using a session keeps it open past the TTL counted from its creation.
+	now = now.Add(40 * time.Second)
+	call(t, "POST", server.URL+"/sessions/"+used.ID+"/step", `{"input": ["coin"]}`, nil)
+	now = now.Add(40 * time.Second)
+
+	var expired errorBody
+	if response := call(t, "GET", server.URL+"/sessions/"+idle.ID, "", &expired); response.StatusCode != http.StatusNotFound ||
+		expired.Error.Message != `session "`+idle.ID+`" expired` {
+		t.Errorf("Expected the idle session to expire, got %d %+v", response.StatusCode, expired)
+	}
+	var current sessionView
+	if response := call(t, "GET", server.URL+"/sessions/"+used.ID, "", &current); response.StatusCode != http.StatusOK || current.State != "unlocked" {
+		t.Errorf("Expected the used session to stay open, got %d %+v", response.StatusCode, current)
+	}
+
+	// Opening a session evicts the expired ones before checking the limit.
+	now = now.Add(2 * time.Minute)
+	if response := call(t, "POST", server.URL+"/machines/turnstile/sessions", "", nil); response.StatusCode != http.StatusCreated {
+		t.Errorf("Expected expired sessions to be evicted, got %d", response.StatusCode)
+	}
+	handler.mutex.RLock()
+	open := len(handler.sessions)
+	handler.mutex.RUnlock()
+	if open != 1 {
+		t.Errorf("Expected 1 open session, got %d", open)
+	}
+}
+
+// TestHandler_ConcurrentEvaluate tests evaluations running concurrently
+func TestHandler_ConcurrentEvaluate(t *testing.T) {
+	server, _ := newTestServer(t, DefaultConfig())
+	url := server.URL + "/machines/turnstile/evaluate"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body, want := `{"input": ["push", "coin"]}`, true
+			if i%2 == 1 {
+				body, want = `{"input": ["push"]}`, false
+			}
+			for j := 0; j < 20; j++ {
+				// call must not be used here, since it stops the test on errors.
+				response, err := http.Post(url, "application/json", strings.NewReader(body))
+				if err != nil {
+					t.Errorf("POST returned error: %v", err)
+					return
+				}
+				var result evaluation
+				err = json.NewDecoder(response.Body).Decode(&result)
+				response.Body.Close()
+				if err != nil || result.Accepted != want {
+					t.Errorf("Evaluation %s = %+v, %v; want accepted %v", body, result, err, want)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// TestHandler_Diagram tests rendering diagrams in every format
+func TestHandler_Diagram(t *testing.T) {
+	server, _ := newTestServer(t, DefaultConfig())
+
+	tests := []struct {
+		query       string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{"", http.StatusOK, "text/vnd.graphviz; charset=utf-8", "digraph fsm {"},
+		{"?format=mermaid", http.StatusOK, "text/plain; charset=utf-8", "stateDiagram-v2"},
+		{"?format=plantuml", http.StatusOK, "text/plain; charset=utf-8", "@startuml"},
+		{"?format=svg", http.StatusBadRequest, "application/json", `"code":"bad_request"`},
+	}
+	for _, tt := range tests {
+		response, err := http.Get(server.URL + "/machines/turnstile/diagram" + tt.query)
+		if err != nil {
+			t.Fatalf("GET returned error: %v", err)
+		}
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		if response.StatusCode != tt.status || response.Header.Get("Content-Type") != tt.contentType ||
+			!strings.Contains(string(body), tt.contains) {
+			t.Errorf("diagram%s = %d %s %q", tt.query, response.StatusCode, response.Header.Get("Content-Type"), body)
+		}
+	}
+}
+
+// TestHandler_Load tests rejected definitions
+func TestHandler_Load(t *testing.T) {
+	handler := NewHandler[string, string](fsm.NewJSONSerializer[string, string](), DefaultConfig())
+	if err := handler.Load("", []byte(turnstileDefinition)); err == nil {
+		t.Error("Expected error for an empty name")
+	}
+	if err := handler.Load("broken", []byte(`{"version": 1}`)); err == nil {
+		t.Error("Expected error for an invalid definition")
+	}
+}
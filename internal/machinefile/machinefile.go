@@ -0,0 +1,117 @@
+// Package machinefile reads and writes automaton definition files for the
+// command-line tools. Machines have string states; symbols are strings, runes
+// or integers depending on the chosen SymbolKind.
+package machinefile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dsonic0912/PolicyReporter-FSM/fsm"
+)
+
+// Format is a serialization format of definition files.
+type Format string
+
+// Supported formats.
+const (
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	SCXML Format = "scxml"
+)
+
+// SymbolKind selects the Go type of the symbols of a machine.
+type SymbolKind string
+
+// Supported symbol kinds.
+const (
+	StringSymbols SymbolKind = "string"
+	RuneSymbols   SymbolKind = "rune"
+	IntSymbols    SymbolKind = "int"
+)
+
+// ParseFormat validates a format name; "yml" is accepted for YAML.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return JSON, nil
+	case "yaml", "yml":
+		return YAML, nil
+	case "scxml", "xml":
+		return SCXML, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (expected json, yaml or scxml)", name)
+	}
+}
+
+// FormatOf infers the format of a file from its extension.
+func FormatOf(path string) (Format, error) {
+	extension := strings.TrimPrefix(filepath.Ext(path), ".")
+	if extension == "" {
+		return "", fmt.Errorf("cannot infer the format of %s; specify it explicitly", path)
+	}
+	return ParseFormat(extension)
+}
+
+// ParseSymbolKind validates a symbol kind name.
+func ParseSymbolKind(name string) (SymbolKind, error) {
+	switch kind := SymbolKind(strings.ToLower(name)); kind {
+	case StringSymbols, RuneSymbols, IntSymbols:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unknown symbol kind %q (expected string, rune or int)", name)
+	}
+}
+
+// Serializer returns the serializer for a format. SCXML requires string symbols.
+func Serializer[S fsm.Symbol](format Format) (fsm.Serializer[string, S], error) {
+	switch format {
+	case JSON:
+		return fsm.NewJSONSerializer[string, S](), nil
+	case YAML:
+		return fsm.NewYAMLSerializer[string, S](), nil
+	case SCXML:
+		if serializer, ok := any(fsm.NewSCXMLSerializer()).(fsm.Serializer[string, S]); ok {
+			return serializer, nil
+		}
+		return nil, fmt.Errorf("the scxml format requires string symbols")
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// Decode reads a machine from data in the given format.
+func Decode[S fsm.Symbol](data []byte, format Format) (*fsm.FiniteAutomaton[string, S], error) {
+	serializer, err := Serializer[S](format)
+	if err != nil {
+		return nil, err
+	}
+	automaton, err := serializer.Deserialize(data)
+	if err != nil {
+		return nil, err
+	}
+	fa, ok := automaton.(*fsm.FiniteAutomaton[string, S])
+	if !ok {
+		return nil, fmt.Errorf("unexpected automaton type %T", automaton)
+	}
+	return fa, nil
+}
+
+// Load reads a machine from a file; an empty format is inferred from the
+// file extension.
+func Load[S fsm.Symbol](path string, format Format) (*fsm.FiniteAutomaton[string, S], error) {
+	if format == "" {
+		inferred, err := FormatOf(path)
+		if err != nil {
+			return nil, err
+		}
+		format = inferred
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decode[S](data, format)
+}